@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// activitySampleInterval keeps recorded activity coarse: at most one sample per minute
+const activitySampleInterval = time.Minute
+
+// editorProcesses are the frontmost apps whose window title names a workspace
+var editorProcesses = map[string]bool{"Cursor": true, "Code": true}
+
+func getActivityLogPath() string {
+	return expandHome(loadSettings().Logs.Activity)
+}
+
+// windowTitlePath extracts the workspace path from an editor window title like
+// "file.go — ~/src/project" (requires window.title to include ${rootPath})
+func windowTitlePath(title string) string {
+	for _, part := range strings.Split(title, " — ") {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "~/") || strings.HasPrefix(part, "/") {
+			return expandHome(part)
+		}
+	}
+	return ""
+}
+
+// frontmostWindow returns the frontmost application and the title of its front window
+func frontmostWindow() (app string, title string, err error) {
+	script := `tell application "System Events"
+	set frontProc to first application process whose frontmost is true
+	set winTitle to ""
+	try
+		set winTitle to name of front window of frontProc
+	end try
+	return (name of frontProc) & tab & winTitle
+end tell`
+	output, err := commandOutput("osascript", "-e", script)
+	if err != nil {
+		return "", "", err
+	}
+	app, title, _ = strings.Cut(strings.TrimSpace(string(output)), "\t")
+	return app, title, nil
+}
+
+// recordActivitySample appends the frontmost app, and the workspace if it is an
+// editor, to the activity log. Only the workspace path is kept, never the title.
+func recordActivitySample() {
+	app, title, err := frontmostWindow()
+	if err != nil || app == "" {
+		return
+	}
+
+	path := ""
+	if editorProcesses[app] {
+		path = windowTitlePath(title)
+	}
+
+	// Silently fail like the port log
+	appendActivitySamples(app, []activitySample{{Time: time.Now(), Project: path}})
+}
+
+// appendActivitySamples writes samples to the activity log, tagged with their source
+func appendActivitySamples(source string, samples []activitySample) error {
+	if readOnly {
+		return errReadOnly
+	}
+	if err := rotateActivityLog(getActivityLogPath(), time.Now()); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(getActivityLogPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, sample := range samples {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", sample.Time.Format("2006-01-02 15:04:05"), source, sample.Project)
+	}
+	return w.Flush()
+}
+
+// loadRecordedActivity reads editor samples from the activity log since the given time
+func loadRecordedActivity(since time.Time) []activitySample {
+	data, err := os.ReadFile(readPath(getActivityLogPath()))
+	if err != nil {
+		return nil
+	}
+
+	var samples []activitySample
+	for _, line := range strings.Split(string(data), "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) < 3 || parts[2] == "" {
+			continue
+		}
+		ts, err := time.ParseInLocation("2006-01-02 15:04:05", parts[0], time.Local)
+		if err != nil || ts.Before(since) {
+			continue
+		}
+		samples = append(samples, activitySample{Time: ts, Project: parts[2]})
+	}
+	return samples
+}
+
+// applyRecordedActivity moves each workspace's last-active time forward when the
+// activity log saw it in the foreground more recently than state.vscdb was written
+func applyRecordedActivity(workspaces []CursorWorkspace) {
+	lastSeen := make(map[string]time.Time)
+	for _, sample := range loadRecordedActivity(time.Time{}) {
+		if sample.Time.After(lastSeen[sample.Project]) {
+			lastSeen[sample.Project] = sample.Time
+		}
+	}
+
+	for i := range workspaces {
+		if seen, ok := lastSeen[workspaces[i].Path]; ok && seen.After(workspaces[i].LastModified) {
+			workspaces[i].LastModified = seen
+		}
+	}
+}
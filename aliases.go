@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// portAlias returns the configured name for a port. The project's .portage.yml comes
+// first (an alias for the port, then its name); in portage.json an alias for the port
+// number wins, otherwise the alias of the deepest configured directory containing the
+// port's path.
+func portAlias(port PortInfo, config *Config) string {
+	if project := findProjectFile(port.Path); project != nil && project.err == nil {
+		if name := project.Aliases[port.Port]; name != "" {
+			return name
+		}
+		if project.Name != "" {
+			return project.Name
+		}
+	}
+	if name, ok := config.Aliases[strconv.Itoa(port.Port)]; ok {
+		return name
+	}
+
+	best := ""
+	bestLen := 0
+	for key, name := range config.Aliases {
+		if _, err := strconv.Atoi(key); err == nil {
+			continue // Port alias, handled above
+		}
+		dir := strings.TrimSuffix(expandHome(key), "/")
+		if (port.Path == dir || strings.HasPrefix(port.Path, dir+"/")) && len(dir) > bestLen {
+			best = name
+			bestLen = len(dir)
+		}
+	}
+	return best
+}
+
+// resolvePortAliases fills in the Name field for every port in place
+func resolvePortAliases(portsByRange map[int][]PortInfo, config *Config) {
+	for _, ports := range portsByRange {
+		for i := range ports {
+			ports[i].Name = portAlias(ports[i], config)
+		}
+	}
+}
+
+// resolveAlias maps an alias name back to what it stands for: a port number or a
+// directory. Matching is case-insensitive; unknown names return ok=false.
+func resolveAlias(name string, config *Config) (port int, path string, ok bool) {
+	for key, alias := range config.Aliases {
+		if !strings.EqualFold(alias, name) {
+			continue
+		}
+		if p, err := strconv.Atoi(key); err == nil {
+			return p, "", true
+		}
+		return 0, expandHome(key), true
+	}
+	return 0, "", false
+}
+
+// killTarget implements --kill: target is a port number, a port alias or a path alias.
+// Every listener matching it is sent SIGTERM.
+func killTarget(target string) error {
+	if readOnly {
+		return errReadOnly
+	}
+	config := loadConfig()
+
+	targetPort, targetPath := 0, ""
+	if p, err := strconv.Atoi(target); err == nil {
+		targetPort = p
+	} else if p, path, ok := resolveAlias(target, config); ok {
+		targetPort, targetPath = p, path
+	} else {
+		return fmt.Errorf("%q is neither a port number nor a configured alias", target)
+	}
+
+	ports, err := scanPorts()
+	if err != nil {
+		return fmt.Errorf("failed to scan ports: %w", err)
+	}
+
+	killed := make(map[string]bool)
+	for _, port := range ports {
+		if targetPort != 0 && port.Port != targetPort {
+			continue
+		}
+		if targetPath != "" && port.Path != targetPath && !strings.HasPrefix(port.Path, targetPath+"/") {
+			continue
+		}
+		if killed[port.PID] {
+			continue
+		}
+
+		if err := killProcess(port.PID); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to kill %s (PID %s): %v\n", port.Command, port.PID, err)
+			continue
+		}
+		killed[port.PID] = true
+		fmt.Printf("Killed %s (PID %s) on port %d\n", port.Command, port.PID, port.Port)
+	}
+
+	if len(killed) == 0 {
+		return fmt.Errorf("nothing is listening for %q", target)
+	}
+	return nil
+}
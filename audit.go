@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// Exposure classes, from least to most exposed
+const (
+	exposureLoopback = "loopback" // 127.0.0.1 / ::1 only
+	exposureLAN      = "lan"      // a private-network address
+	exposureAll      = "all"      // 0.0.0.0 / * : every interface, including Wi-Fi
+	exposurePublic   = "public"   // a specific public address
+)
+
+var exposureRank = map[string]int{exposureLoopback: 0, exposureLAN: 1, exposureAll: 2, exposurePublic: 3}
+
+type auditEntry struct {
+	Port     int    `json:"port"`
+	Command  string `json:"command"`
+	PID      string `json:"pid"`
+	Address  string `json:"address"`
+	Path     string `json:"path"`
+	Exposure string `json:"exposure"`
+	Allowed  bool   `json:"allowed"` // listed in audit_allow, so not a failure
+}
+
+// classifyExposure works out who can reach a listener from its lsof bind address
+func classifyExposure(address string) string {
+	if isWildcardBind(address) {
+		return exposureAll
+	}
+
+	idx := strings.LastIndex(address, ":")
+	host := address
+	if idx >= 0 {
+		host = address[:idx]
+	}
+	host = strings.Trim(host, "[]")
+	if host == "localhost" {
+		return exposureLoopback
+	}
+
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		return exposureLAN // Unresolved names: assume reachable, but not necessarily public
+	case ip.IsLoopback():
+		return exposureLoopback
+	case ip.IsPrivate() || ip.IsLinkLocalUnicast():
+		return exposureLAN
+	default:
+		return exposurePublic
+	}
+}
+
+// runAudit prints every listener by exposure and returns the exit code: 1 if anything
+// not in audit_allow is listening on all interfaces or a public address
+func runAudit(portsByRange map[int][]PortInfo, config *Config) int {
+	allowed := make(map[int]bool)
+	for _, port := range config.AuditAllow {
+		allowed[port] = true
+	}
+
+	var entries []auditEntry
+	seen := make(map[string]bool)
+	for _, ports := range portsByRange {
+		for _, port := range ports {
+			key := fmt.Sprintf("%d-%s", port.Port, port.PID)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			entries = append(entries, auditEntry{
+				Port:     port.Port,
+				Command:  port.Command,
+				PID:      port.PID,
+				Address:  port.Address,
+				Path:     port.Path,
+				Exposure: classifyExposure(port.Address),
+				Allowed:  allowed[port.Port],
+			})
+		}
+	}
+
+	// Most exposed first
+	sort.Slice(entries, func(i, j int) bool {
+		if exposureRank[entries[i].Exposure] != exposureRank[entries[j].Exposure] {
+			return exposureRank[entries[i].Exposure] > exposureRank[entries[j].Exposure]
+		}
+		return entries[i].Port < entries[j].Port
+	})
+
+	failures := 0
+	for _, entry := range entries {
+		if exposureRank[entry.Exposure] >= exposureRank[exposureAll] && !entry.Allowed {
+			failures++
+		}
+	}
+
+	if jsonOutput {
+		writeJSON("audit", entries)
+	} else {
+		t := newTable(table.StyleDefault)
+		t.SetOutputMirror(os.Stdout)
+		t.AppendHeader(table.Row{"PORT", "COMMAND", "PID", "ADDRESS", "EXPOSURE", "PATH"})
+		for _, entry := range entries {
+			exposure := entry.Exposure
+			switch {
+			case entry.Allowed && exposure != exposureLoopback:
+				exposure += " (allowed)"
+			case exposure == exposureAll || exposure == exposurePublic:
+				exposure = ColorRed + exposure + ColorReset
+			case exposure == exposureLAN:
+				exposure = ColorYellow + exposure + ColorReset
+			}
+			t.AppendRow(table.Row{entry.Port, entry.Command, entry.PID, entry.Address, exposure, shortenPath(entry.Path)})
+		}
+		fmt.Println()
+		t.Render()
+
+		if failures > 0 {
+			fmt.Printf("\n%s%s%d listener(s) reachable from other machines%s (add ports to \"audit_allow\" in portage.json if expected)\n\n", ColorBold, ColorRed, failures, ColorReset)
+		} else {
+			fmt.Printf("\n%s%sNothing unexpected is listening on all interfaces%s\n\n", ColorBold, ColorGreen, ColorReset)
+		}
+	}
+
+	if failures > 0 {
+		return 1
+	}
+	return 0
+}
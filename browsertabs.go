@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// tabBrowsers are the AppleScript-scriptable browsers checked for localhost tabs
+var tabBrowsers = []string{"Google Chrome", "Arc", "Brave Browser", "Microsoft Edge", "Safari"}
+
+// listBrowserTabURLs returns the URL of every open tab in every running browser.
+// Browsers that aren't running are never launched.
+func listBrowserTabURLs() []string {
+	var urls []string
+	for _, browser := range tabBrowsers {
+		script := fmt.Sprintf(`if application %[1]s is running then
+	tell application %[1]s
+		set out to ""
+		repeat with w in windows
+			repeat with t in tabs of w
+				set out to out & (URL of t) & linefeed
+			end repeat
+		end repeat
+		return out
+	end tell
+end if`, appleScriptString(browser))
+		output, err := commandOutput("osascript", "-e", script)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				urls = append(urls, line)
+			}
+		}
+	}
+	return urls
+}
+
+// localTabPort returns the port a tab URL points at if it targets this machine
+func localTabPort(raw string) (int, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return 0, false
+	}
+
+	host := u.Hostname()
+	if host != "localhost" && host != "127.0.0.1" && host != "0.0.0.0" && host != "::1" && !strings.HasSuffix(host, ".localhost") {
+		return 0, false
+	}
+
+	if u.Port() == "" {
+		if u.Scheme == "https" {
+			return 443, true
+		}
+		return 80, true
+	}
+	port, err := strconv.Atoi(u.Port())
+	return port, err == nil
+}
+
+// resolveBrowserTabs counts the open browser tabs pointing at each listening port
+func resolveBrowserTabs(ports []PortInfo) {
+	tabs := make(map[int]int)
+	for _, raw := range listBrowserTabURLs() {
+		if port, ok := localTabPort(raw); ok {
+			tabs[port]++
+		}
+	}
+	for i := range ports {
+		ports[i].BrowserTabs = tabs[ports[i].Port]
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// capability says whether a provider or feature works on this host, and why (or why not)
+type capability struct {
+	Available bool   `json:"available"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+type capabilities struct {
+	Platform  map[string]string     `json:"platform"`
+	Providers map[string]capability `json:"providers"`
+	Features  map[string]capability `json:"features"`
+}
+
+// binaryCapability reports whether an external tool is on PATH
+func binaryCapability(name string) capability {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return capability{Detail: name + " not found in PATH"}
+	}
+	return capability{Available: true, Detail: path}
+}
+
+// fileCapability reports whether the first existing path from candidates is present
+func fileCapability(what string, candidates ...string) capability {
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return capability{Available: true, Detail: what + " found at " + shortenPath(path)}
+		}
+	}
+	return capability{Detail: "no " + what}
+}
+
+// both requires two capabilities, reporting the first one that's missing
+func both(a, b capability) capability {
+	if !a.Available {
+		return a
+	}
+	if !b.Available {
+		return b
+	}
+	return capability{Available: true, Detail: b.Detail}
+}
+
+// detectCapabilities probes the host without running any provider for real
+func detectCapabilities() capabilities {
+	home, _ := os.UserHomeDir()
+	darwin := capability{Detail: "requires macOS"}
+	if runtime.GOOS == "darwin" {
+		darwin = capability{Available: true, Detail: "macOS"}
+	}
+	osascript := both(darwin, binaryCapability("osascript"))
+	cursorUser := filepath.Join(home, "Library", "Application Support", "Cursor", "User")
+
+	return capabilities{
+		Platform: map[string]string{
+			"os":   runtime.GOOS,
+			"arch": runtime.GOARCH,
+			"go":   runtime.Version(),
+		},
+		Providers: map[string]capability{
+			"ports":          binaryCapability("lsof"),
+			"processes":      binaryCapability("ps"),
+			"cursor":         fileCapability("Cursor workspace storage", filepath.Join(cursorUser, "workspaceStorage")),
+			"cursor_history": fileCapability("Cursor global state", filepath.Join(cursorUser, "globalStorage", "state.vscdb")),
+			"cursor_windows": osascript,
+			"vscode":         fileCapability("VS Code workspace storage", filepath.Join(home, "Library", "Application Support", "Code", "User", "workspaceStorage")),
+			"claude":         fileCapability("Claude history", filepath.Join(home, ".claude", "history.jsonl")),
+			"docker":         fileCapability("Docker socket", "/var/run/docker.sock", filepath.Join(home, ".docker", "run", "docker.sock")),
+			"tmux":           binaryCapability("tmux"),
+			"shell_history": fileCapability("shell history", os.Getenv("HISTFILE"), filepath.Join(home, ".zsh_history"),
+				filepath.Join(home, ".local", "share", "fish", "fish_history")),
+		},
+		Features: map[string]capability{
+			"json":            {Available: true},
+			"jq":              {Available: true, Detail: "built in (gojq)"},
+			"interactive":     {Available: true},
+			"watch":           {Available: true},
+			"grpc_health":     {Available: true},
+			"sudo":            binaryCapability("sudo"),
+			"browser_tabs":    osascript,
+			"terminal_origin": binaryCapability("ps"),
+			"activity_record": osascript,
+			"switch_focus":    osascript,
+			"fixtures":        {Available: true, Detail: "record-fixtures and PORTAGE_FIXTURES replay"},
+			"demo":            {Available: true},
+		},
+	}
+}
+
+// runCapabilities implements `portage capabilities`
+func runCapabilities(args []string) {
+	fs := flag.NewFlagSet("capabilities", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Output as JSON")
+	fs.Parse(args)
+
+	caps := detectCapabilities()
+	if *asJSON {
+		writeJSON("capabilities", caps)
+		return
+	}
+
+	fmt.Printf("\n%s%sPORTAGE - Capabilities (%s/%s)%s\n\n", ColorBold, ColorCyan, caps.Platform["os"], caps.Platform["arch"], ColorReset)
+
+	t := newTable(table.StyleDefault)
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"KIND", "NAME", "AVAILABLE", "DETAIL"})
+	for _, section := range []struct {
+		kind string
+		caps map[string]capability
+	}{{"provider", caps.Providers}, {"feature", caps.Features}} {
+		var names []string
+		for name := range section.caps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			c := section.caps[name]
+			available := ColorRed + "no" + ColorReset
+			if c.Available {
+				available = ColorGreen + "yes" + ColorReset
+			}
+			t.AppendRow(table.Row{section.kind, name, available, c.Detail})
+		}
+	}
+	t.Render()
+	fmt.Println()
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// claudeRefreshInterval is how often the Claude view reloads its CPU, memory and last
+// messages while it's shown
+const claudeRefreshInterval = 3 * time.Second
+
+// claudeTickMsg reloads the Claude view if it's still the current one
+type claudeTickMsg struct{}
+
+func claudeTick() tea.Cmd {
+	return tea.Tick(claudeRefreshInterval, func(time.Time) tea.Msg {
+		return claudeTickMsg{}
+	})
+}
+
+// transcriptTail is how much of the end of a transcript is read for its last message
+const transcriptTail = 256 * 1024
+
+var transcriptDirRegex = regexp.MustCompile(`[^A-Za-z0-9]`)
+
+// claudeTranscriptDir is where Claude Code keeps the transcripts of sessions started in
+// dir: ~/.claude/projects/ plus transcriptDirName
+func claudeTranscriptDir(dir string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".claude", "projects", transcriptDirName(dir))
+}
+
+// transcriptDirName is the path with everything but letters and digits as "-"
+func transcriptDirName(dir string) string {
+	return transcriptDirRegex.ReplaceAllString(dir, "-")
+}
+
+// latestTranscript returns the most recently written transcript of sessions in dir,
+// which is the running session's
+func latestTranscript(dir string) string {
+	files, _ := filepath.Glob(filepath.Join(claudeTranscriptDir(dir), "*.jsonl"))
+	latest := ""
+	var latestTime time.Time
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil && info.ModTime().After(latestTime) {
+			latest, latestTime = file, info.ModTime()
+		}
+	}
+	return latest
+}
+
+// transcriptEntry is the part of a transcript line the last message is taken from
+type transcriptEntry struct {
+	Type        string `json:"type"`
+	IsSidechain bool   `json:"isSidechain"` // subagent traffic
+	Message     struct {
+		Content json.RawMessage `json:"content"` // a string, or text/tool_use/... blocks
+	} `json:"message"`
+}
+
+// lastTranscriptMessage summarizes the newest message of a transcript: "you: <prompt>",
+// "claude: <reply>", or "claude: using Bash" while a tool runs
+func lastTranscriptMessage(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err == nil && info.Size() > transcriptTail {
+		file.Seek(-transcriptTail, io.SeekEnd)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return ""
+	}
+
+	last := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		var entry transcriptEntry
+		if json.Unmarshal([]byte(line), &entry) != nil || entry.IsSidechain {
+			continue // also the partial first line of the tail
+		}
+		who := map[string]string{"user": "you", "assistant": "claude"}[entry.Type]
+		if who == "" {
+			continue
+		}
+
+		var text string
+		if json.Unmarshal(entry.Message.Content, &text) == nil {
+			last = who + ": " + text
+			continue
+		}
+		var blocks []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(entry.Message.Content, &blocks) != nil {
+			continue
+		}
+		for _, block := range blocks {
+			switch block.Type {
+			case "text":
+				last = who + ": " + block.Text
+			case "tool_use":
+				last = who + ": using " + block.Name
+			}
+		}
+	}
+	return strings.Join(strings.Fields(last), " ")
+}
+
+// killClaudeSession asks to TERM the selected Claude session
+func (m model) killClaudeSession() (tea.Model, tea.Cmd) {
+	rows := m.tabRows()
+	if m.cursor >= len(rows) {
+		return m, nil
+	}
+	row := rows[m.cursor]
+	question := fmt.Sprintf("Kill the Claude session (PID %s) in %s with TERM?", row.pid, shortenPath(row.Path))
+	return m.confirmThen(question, func(m model) (tea.Model, tea.Cmd) {
+		if err := signalProcess(row.pid, "TERM"); err != nil {
+			m.message = fmt.Sprintf("Failed to kill PID %s: %v", row.pid, err)
+			return m, nil
+		}
+		m.message = fmt.Sprintf("Killed the Claude session in %s", shortenPath(row.Path))
+		return m, loadTab(tabClaude)
+	})
+}
+
+// jumpToProject switches to the Ports view with the first listener of the selected
+// Claude session's project selected
+func (m model) jumpToProject() (tea.Model, tea.Cmd) {
+	rows := m.tabRows()
+	if m.cursor >= len(rows) || rows[m.cursor].Path == "" {
+		return m, nil
+	}
+	dir := rows[m.cursor].Path
+	for i, port := range m.getVisiblePorts() {
+		if port.Path == dir || strings.HasPrefix(port.Path, dir+"/") {
+			next, cmd := m.switchTab(tabPorts)
+			m = next.(model)
+			m.cursor = i
+			return m, cmd
+		}
+	}
+	m.message = fmt.Sprintf("Nothing of %s is listening", shortenPath(dir))
+	return m, nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// codeownersRule is a single "pattern @owner ..." line from a CODEOWNERS file
+type codeownersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// codeownersFile is a parsed CODEOWNERS file for one repository
+type codeownersFile struct {
+	root  string
+	rules []codeownersRule
+}
+
+// Repos are resolved once per invocation; nil means "no CODEOWNERS for this repo"
+var codeownersCache = make(map[string]*codeownersFile)
+
+// loadCodeowners parses the CODEOWNERS file of a repository, checking the same
+// locations as GitHub: .github/, the repository root, then docs/
+func loadCodeowners(root string) *codeownersFile {
+	if cached, ok := codeownersCache[root]; ok {
+		return cached
+	}
+	codeownersCache[root] = nil
+
+	for _, candidate := range []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"} {
+		f, err := os.Open(filepath.Join(root, candidate))
+		if err != nil {
+			continue
+		}
+
+		parsed := &codeownersFile{root: root}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue // Pattern without owners: nobody owns it
+			}
+
+			pattern, err := compileCodeownersPattern(fields[0])
+			if err != nil {
+				continue // Skip patterns we can't translate
+			}
+
+			var owners []string
+			for _, owner := range fields[1:] {
+				if strings.HasPrefix(owner, "#") {
+					break // Trailing comment
+				}
+				owners = append(owners, owner)
+			}
+			parsed.rules = append(parsed.rules, codeownersRule{pattern: pattern, owners: owners})
+		}
+		f.Close()
+
+		codeownersCache[root] = parsed
+		return parsed
+	}
+
+	return nil
+}
+
+// compileCodeownersPattern translates a gitignore-style CODEOWNERS pattern into a regexp
+// matching a repo-relative directory path and everything below it
+func compileCodeownersPattern(pattern string) (*regexp.Regexp, error) {
+	trimmed := strings.TrimSuffix(pattern, "/")
+	anchored := strings.HasPrefix(trimmed, "/") || strings.Contains(strings.TrimPrefix(trimmed, "/"), "/")
+	trimmed = strings.TrimPrefix(trimmed, "/")
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("^(.*/)?")
+	}
+
+	for i := 0; i < len(trimmed); i++ {
+		switch {
+		case strings.HasPrefix(trimmed[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(trimmed[i:], "/**"):
+			expr.WriteString("(/.*)?")
+			i += 2
+		case strings.HasPrefix(trimmed[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case trimmed[i] == '*':
+			expr.WriteString("[^/]*")
+		case trimmed[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(trimmed[i])))
+		}
+	}
+
+	expr.WriteString("(/.*)?$")
+	return regexp.Compile(expr.String())
+}
+
+// ownersFor returns the owners of a path inside the repository (last matching rule wins)
+func (c *codeownersFile) ownersFor(path string) []string {
+	rel, err := filepath.Rel(c.root, path)
+	if err != nil {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		rel = ""
+	}
+
+	var owners []string
+	for _, rule := range c.rules {
+		if rule.pattern.MatchString(rel) {
+			owners = rule.owners
+		}
+	}
+	return owners
+}
+
+// resolveOwner returns the CODEOWNERS owners of a working directory, or "" if unknown
+func resolveOwner(path string) string {
+	if path == "N/A" || path == "/" || path == "" {
+		return ""
+	}
+
+	root := findRepoRoot(path)
+	if root == "" {
+		return ""
+	}
+
+	codeowners := loadCodeowners(root)
+	if codeowners == nil {
+		return ""
+	}
+
+	return strings.Join(codeowners.ownersFor(path), " ")
+}
+
+// resolvePortOwners fills in the Owner field for every port in place
+func resolvePortOwners(portsByRange map[int][]PortInfo) {
+	for _, ports := range portsByRange {
+		for i := range ports {
+			ports[i].Owner = resolveOwner(ports[i].Path)
+		}
+	}
+}
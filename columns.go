@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// portColumn is a column of the port list that "columns" in portage.json (or v in
+// interactive mode) shows or hides. Columns always appear in this order.
+type portColumn struct {
+	name     string // as written in the config
+	title    string
+	width    int // in interactive mode; the path takes the rest of the line
+	minWidth int // how far a narrow terminal may shrink it (layout.go); 0 keeps the width
+}
+
+var portColumns = []portColumn{
+	{"port", "PORT", 6, 0},
+	{"command", "COMMAND", 16, 9},
+	{"pid", "PID", 8, 0},
+	{"uptime", "UPTIME", 8, 0},
+	{"cpu", "CPU%", 6, 0},
+	{"address", "ADDRESS", 18, 9},
+	{"branch", "BRANCH", 16, 8},
+	{"path", "PATH", 0, 0},
+}
+
+// defaultColumns are shown when the config doesn't list any
+var defaultColumns = []string{"port", "command", "pid", "uptime", "address", "path"}
+
+// showsColumn reports whether a port list column is enabled; the port always is, since
+// it identifies the row
+func (c *Config) showsColumn(name string) bool {
+	if name == "port" {
+		return true
+	}
+	columns := c.Columns
+	if len(columns) == 0 {
+		columns = defaultColumns
+	}
+	for _, column := range columns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleColumn shows or hides a column, writing out the full list so later defaults
+// don't change a customized layout
+func (c *Config) toggleColumn(name string) {
+	if name == "port" {
+		return
+	}
+	var columns []string
+	for _, column := range portColumns {
+		if c.showsColumn(column.name) != (column.name == name) {
+			columns = append(columns, column.name)
+		}
+	}
+	c.Columns = columns
+}
+
+func columnTitle(name string) string {
+	for _, column := range portColumns {
+		if column.name == name {
+			return column.title
+		}
+	}
+	return strings.ToUpper(name)
+}
+
+// portCell is the text of a column for a port; the path is formatted by each view
+func portCell(port PortInfo, name string) string {
+	switch name {
+	case "port":
+		return strconv.Itoa(port.Port)
+	case "command":
+		return port.Command
+	case "pid":
+		return port.PID
+	case "uptime":
+		return port.Uptime
+	case "cpu":
+		return fmt.Sprintf("%.1f", port.CPU)
+	case "address":
+		return port.Address
+	case "branch":
+		if port.Branch == "" {
+			return "-"
+		}
+		return port.Branch
+	}
+	return ""
+}
+
+// resolveGitBranches fills in Branch for every port inside a git checkout
+func resolveGitBranches(ports []PortInfo) {
+	branches := make(map[string]string)
+	for i := range ports {
+		root := findRepoRoot(ports[i].Path)
+		if root == "" {
+			continue
+		}
+		branch, ok := branches[root]
+		if !ok {
+			branch = gitBranch(root)
+			branches[root] = branch
+		}
+		ports[i].Branch = branch
+	}
+}
+
+// columnPicker is the open column menu of v
+type columnPicker struct {
+	cursor int
+}
+
+// updateColumnPicker handles keys while the column menu is open; changes show at once
+// and are saved when it closes
+func (m model) updateColumnPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q", "v", "enter":
+		m.columnPick = nil
+		m.config.save()
+		m.message = "Saved the columns"
+		if readOnly {
+			m.message += " for this session (read-only)"
+		}
+	case "up", "k":
+		if m.columnPick.cursor > 0 {
+			m.columnPick.cursor--
+		}
+	case "down", "j":
+		if m.columnPick.cursor < len(portColumns)-1 {
+			m.columnPick.cursor++
+		}
+	case " ", "x":
+		m.config.toggleColumn(portColumns[m.columnPick.cursor].name)
+	}
+	return m, nil
+}
+
+// viewColumnPicker renders the column menu in place of the help line
+func (m model) viewColumnPicker() string {
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Accent)
+	selectedStyle := ui.selectedStyle()
+	helpStyle := lipgloss.NewStyle().Foreground(ui.Muted)
+
+	var s strings.Builder
+	s.WriteString(headerStyle.Render("Columns"))
+	s.WriteString("\n")
+	for i, column := range portColumns {
+		check := "[ ]"
+		if m.config.showsColumn(column.name) {
+			check = "[x]"
+		}
+		line := fmt.Sprintf("  %s %s", check, column.title)
+		if column.name == "port" {
+			line += " (always shown)"
+		}
+		if i == m.columnPick.cursor {
+			line = selectedStyle.Render(line)
+		}
+		s.WriteString(line)
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render("↑/↓: choose • space: show/hide • enter/esc: save"))
+	return s.String()
+}
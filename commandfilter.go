@@ -0,0 +1,62 @@
+package main
+
+import "strings"
+
+// commandFamily is a runtime the list can be narrowed to with a single key. lsof cuts
+// command names at 9 characters ("python3.1", "com.docke"), so they're matched by prefix.
+type commandFamily struct {
+	name     string
+	key      string // hotkey in interactive mode, "" for families only F reaches
+	prefixes []string
+}
+
+var commandFamilies = []commandFamily{
+	{"node", "N", []string{"node", "bun", "deno", "npm", "pnpm", "yarn", "next-serv"}},
+	{"python", "Y", []string{"python", "uvicorn", "gunicorn", "hypercorn", "daphne", "flask"}},
+	{"docker", "D", []string{"docker", "com.docke", "vpnkit"}},
+	{"ruby", "", []string{"ruby", "puma", "rails", "bundle"}},
+	{"java", "", []string{"java"}},
+}
+
+// matchesCommandFamily reports whether a port's command belongs to the named family;
+// every port matches when no family is selected
+func matchesCommandFamily(port PortInfo, family string) bool {
+	if family == "" {
+		return true
+	}
+	command := strings.ToLower(port.Command)
+	for _, f := range commandFamilies {
+		if f.name != family {
+			continue
+		}
+		for _, prefix := range f.prefixes {
+			if strings.HasPrefix(command, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// commandFamilyForKey returns the family a hotkey selects
+func commandFamilyForKey(key string) (string, bool) {
+	for _, f := range commandFamilies {
+		if f.key != "" && f.key == key {
+			return f.name, true
+		}
+	}
+	return "", false
+}
+
+// nextCommandFamily cycles F through every family and back to showing all commands
+func nextCommandFamily(family string) string {
+	for i, f := range commandFamilies {
+		if f.name == family {
+			if i+1 < len(commandFamilies) {
+				return commandFamilies[i+1].name
+			}
+			return ""
+		}
+	}
+	return commandFamilies[0].name
+}
@@ -0,0 +1,533 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// runConfig implements `portage config`. main runs it before config.toml is loaded,
+// since it's how a broken one gets fixed.
+func runConfig(args []string) {
+	fs := flag.NewFlagSet("config", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: portage config [command]\n\n")
+		fmt.Fprintf(os.Stderr, "  path               print where config.toml is read from (default)\n")
+		fmt.Fprintf(os.Stderr, "  files              list every file portage reads and writes\n")
+		fmt.Fprintf(os.Stderr, "  check              validate it and list every problem\n")
+		fmt.Fprintf(os.Stderr, "  init               write a config.toml with every key and its default\n")
+		fmt.Fprintf(os.Stderr, "  list               print every setting, marking the defaults\n")
+		fmt.Fprintf(os.Stderr, "  get <key>          print one setting, or every setting of a table\n")
+		fmt.Fprintf(os.Stderr, "  set <key> <value>  change a setting; lists take \"a,b\" or TOML arrays\n")
+		fmt.Fprintf(os.Stderr, "  unset <key>        go back to a setting's default\n")
+		fmt.Fprintf(os.Stderr, "  edit               open config.toml in $VISUAL or $EDITOR, then check it\n")
+		fmt.Fprintf(os.Stderr, "  profiles           list the [profiles.<name>] tables and what each sets\n")
+	}
+	fs.Parse(args)
+
+	path := getSettingsPath()
+	switch fs.Arg(0) {
+	case "", "path":
+		fmt.Println(path)
+	case "files":
+		for _, file := range []struct{ name, path string }{
+			{"config.toml", path},
+			{"portage.json", getConfigPath()},
+			{"history", getHistoryPath()},
+			{"activity log", getActivityLogPath()},
+			{"crash reports", crashDir()},
+		} {
+			fmt.Printf("%-15s %s\n", file.name, shortenPath(file.path))
+		}
+	case "check":
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("%s doesn't exist; using the defaults (portage config init writes one)\n", shortenPath(path))
+			return
+		}
+		if _, err := readSettings(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error in %s:\n%v\n", shortenPath(path), err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s is valid\n", shortenPath(path))
+	case "init":
+		if _, err := os.Stat(path); err == nil {
+			fmt.Fprintf(os.Stderr, "Error: %s already exists\n", shortenPath(path))
+			os.Exit(1)
+		}
+		writeSettingsFile(path, settingsTemplate())
+		fmt.Printf("Wrote %s\n", shortenPath(path))
+	case "list":
+		settings, meta := readSettingsFile(path)
+		for _, entry := range settingsEntries(reflect.ValueOf(settings), nil) {
+			line := entry.key() + " = " + tomlValue(entry.value.Interface())
+			if !meta.IsDefined(entry.parts...) {
+				line += "  # default"
+			}
+			fmt.Println(line)
+		}
+	case "get":
+		if fs.NArg() != 2 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		getSetting(path, fs.Arg(1))
+	case "set":
+		if fs.NArg() < 3 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		// Values with spaces work unquoted: portage config set editor.command code -n {path}
+		setSetting(path, fs.Arg(1), strings.Join(fs.Args()[2:], " "))
+	case "unset":
+		if fs.NArg() != 2 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		unsetSetting(path, fs.Arg(1))
+	case "edit":
+		editSettings(path)
+	case "profiles":
+		settings, _ := readSettingsFile(path)
+		listProfiles(settings)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// readSettingsFile loads config.toml for list and get, exiting on a file that doesn't
+// parse or validate. A missing file is all defaults.
+func readSettingsFile(path string) (*Settings, toml.MetaData) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return defaultSettings(), toml.MetaData{}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	settings, meta, err := decodeSettings(string(data))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error in %s:\n%v\n", shortenPath(path), err)
+		os.Exit(1)
+	}
+	return settings, meta
+}
+
+// writeSettingsFile saves config.toml, creating its directory
+func writeSettingsFile(path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// settingsEntry is one key of config.toml with its value
+type settingsEntry struct {
+	parts []string // the key's path, e.g. ["editor", "projects", "~/dev/app"]
+	value reflect.Value
+}
+
+var bareTOMLKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// key writes the entry's dotted key as TOML does: editor.projects."~/dev/app"
+func (e settingsEntry) key() string {
+	parts := make([]string, len(e.parts))
+	for i, part := range e.parts {
+		parts[i] = tomlKey(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// settingsEntries lists every key of a settings struct with its value, tables flattened
+// into dotted keys and map tables into one key per entry
+func settingsEntries(v reflect.Value, prefix []string) []settingsEntry {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v = reflect.New(v.Type().Elem()) // an unset [theme] lists its keys empty
+		}
+		v = v.Elem()
+	}
+	var entries []settingsEntry
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Tag.Get("toml")
+		if name == "" {
+			continue
+		}
+		parts := append(append([]string{}, prefix...), name)
+		field := v.Field(i)
+		switch {
+		case field.Kind() == reflect.Struct || (field.Kind() == reflect.Pointer && field.Type().Elem().Kind() == reflect.Struct):
+			entries = append(entries, settingsEntries(field, parts)...)
+		case field.Kind() == reflect.Map:
+			keys := field.MapKeys()
+			sort.Slice(keys, func(a, b int) bool { return keys[a].String() < keys[b].String() })
+			for _, key := range keys {
+				keyParts := append(append([]string{}, parts...), key.String())
+				if field.Type().Elem().Kind() != reflect.Struct {
+					entries = append(entries, settingsEntry{parts: keyParts, value: field.MapIndex(key)})
+					continue
+				}
+				// Tables of tables, like [profiles.work.filters], list only the keys they set
+				for _, entry := range settingsEntries(field.MapIndex(key), keyParts) {
+					if entry.value.Kind() != reflect.Slice || !entry.value.IsNil() {
+						entries = append(entries, entry)
+					}
+				}
+			}
+		default:
+			entries = append(entries, settingsEntry{parts: parts, value: field})
+		}
+	}
+	return entries
+}
+
+// getSetting prints a value for scripts: strings as they are, anything else as TOML.
+// A table prints all of its keys like list does.
+func getSetting(path, key string) {
+	if _, _, _, err := settingsKeyPath(key); err != nil && !strings.Contains(err.Error(), "is a table") {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	settings, _ := readSettingsFile(path)
+	found := false
+	for _, entry := range settingsEntries(reflect.ValueOf(settings), nil) {
+		switch {
+		case entry.key() == key || strings.Join(entry.parts, ".") == key:
+			if entry.value.Kind() == reflect.String {
+				fmt.Println(entry.value.String())
+			} else {
+				fmt.Println(tomlValue(entry.value.Interface()))
+			}
+			return
+		case strings.HasPrefix(entry.key(), key+"."):
+			fmt.Printf("%s = %s\n", entry.key(), tomlValue(entry.value.Interface()))
+			found = true
+		}
+	}
+	if !found {
+		os.Exit(1) // a map table entry that isn't set, like git config
+	}
+}
+
+// setSetting changes one key in config.toml, keeping the rest of the file (comments
+// included) as it is. Nothing is saved if the result doesn't validate.
+func setSetting(path, key, raw string) {
+	table, name, valueType, err := settingsKeyPath(key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	value, err := parseSettingsValue(valueType, raw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: %v\n", key, err)
+		os.Exit(1)
+	}
+
+	content := readSettingsText(path)
+	content = setSettingsLine(content, table, name, value)
+	saveSettings(path, content)
+	fmt.Printf("Set %s.%s = %s in %s\n", table, tomlKey(name), value, shortenPath(path))
+}
+
+// unsetSetting removes one key from config.toml, so its default applies again
+func unsetSetting(path, key string) {
+	table, name, _, err := settingsKeyPath(key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	content, ok := unsetSettingsLine(readSettingsText(path), table, name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: %s isn't set in %s\n", key, shortenPath(path))
+		os.Exit(1)
+	}
+	saveSettings(path, content)
+	fmt.Printf("Unset %s in %s\n", key, shortenPath(path))
+}
+
+func readSettingsText(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return string(data)
+}
+
+// saveSettings validates new content for config.toml and writes it, or reports why not
+func saveSettings(path, content string) {
+	if _, _, err := decodeSettings(content); err != nil {
+		fmt.Fprintf(os.Stderr, "Not saved, %s would be invalid:\n%v\n", shortenPath(path), err)
+		os.Exit(1)
+	}
+	writeSettingsFile(path, content)
+}
+
+// settingsKeyPath resolves a dotted key to where it goes in config.toml: its table, its
+// name there and the type of its value. A key of a map table is the rest of the key,
+// dots and all: editor.projects.~/dev/my.app
+func settingsKeyPath(key string) (table, name string, valueType reflect.Type, err error) {
+	return settingsKeyPathIn(reflect.TypeOf(Settings{}), strings.Split(key, "."), key)
+}
+
+// settingsKeyPathIn resolves the parts of a key within the settings struct t
+func settingsKeyPathIn(t reflect.Type, parts []string, key string) (table, name string, valueType reflect.Type, err error) {
+	for i, part := range parts {
+		var field reflect.StructField
+		found := false
+		for j := 0; j < t.NumField(); j++ {
+			if t.Field(j).Tag.Get("toml") == part {
+				field, found = t.Field(j), true
+			}
+		}
+		if !found {
+			return "", "", nil, errors.New(unknownSettingsKey(key))
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		prefix := strings.Join(parts[:i+1], ".")
+		switch {
+		case fieldType.Kind() == reflect.Struct:
+			if i == len(parts)-1 {
+				return "", "", nil, fmt.Errorf("%s is a table, set one of its keys (portage config get %s lists them)", key, key)
+			}
+			t = fieldType
+		case fieldType.Kind() == reflect.Map && fieldType.Elem().Kind() == reflect.Struct:
+			// A table of tables: the next part names one, like "work" in profiles.work.ranges.starts
+			if i+2 >= len(parts) {
+				return "", "", nil, fmt.Errorf("%s is a table, set one of its keys (portage config get %s lists them)", key, key)
+			}
+			table, name, valueType, err = settingsKeyPathIn(fieldType.Elem(), parts[i+2:], key)
+			if err != nil {
+				return "", "", nil, err
+			}
+			return strings.Join([]string{prefix, tomlKey(parts[i+1]), table}, "."), name, valueType, nil
+		case fieldType.Kind() == reflect.Map:
+			name = strings.Trim(strings.Join(parts[i+1:], "."), `"'`)
+			if name == "" {
+				return "", "", nil, fmt.Errorf("%s is a table, set %s.<key>", key, prefix)
+			}
+			return prefix, name, fieldType.Elem(), nil
+		case i != len(parts)-1:
+			return "", "", nil, errors.New(unknownSettingsKey(key))
+		default:
+			return strings.Join(parts[:i], "."), part, fieldType, nil
+		}
+	}
+	return "", "", nil, errors.New(unknownSettingsKey(key))
+}
+
+// parseSettingsValue turns a value from the command line into TOML for a key of type t.
+// Strings are taken as they are; lists as a TOML array or comma-separated: 3000,4000.
+func parseSettingsValue(t reflect.Type, raw string) (string, error) {
+	value := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return "", fmt.Errorf("%q is not true or false", raw)
+		}
+		value.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return "", fmt.Errorf("%q is not a number", raw)
+		}
+		value.SetInt(int64(n))
+	case reflect.Slice:
+		if trimmed := strings.TrimSpace(raw); strings.HasPrefix(trimmed, "[") {
+			return trimmed, nil // checked with the whole file
+		}
+		value.Set(reflect.MakeSlice(t, 0, 0))
+		for _, item := range strings.Split(raw, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			elem := reflect.New(t.Elem()).Elem()
+			if t.Elem().Kind() == reflect.Int {
+				n, err := strconv.Atoi(item)
+				if err != nil {
+					return "", fmt.Errorf("%q is not a number", item)
+				}
+				elem.SetInt(int64(n))
+			} else {
+				elem.SetString(item)
+			}
+			value.Set(reflect.Append(value, elem))
+		}
+	default:
+		return "", fmt.Errorf("can't be set from the command line, use portage config edit")
+	}
+	return tomlValue(value.Interface()), nil
+}
+
+// tomlValue writes a value the way it appears in config.toml
+func tomlValue(v interface{}) string {
+	if value := reflect.ValueOf(v); value.Kind() == reflect.Slice && value.Len() == 0 {
+		return "[]" // the encoder leaves out nil slices altogether
+	}
+	var b bytes.Buffer
+	if err := toml.NewEncoder(&b).Encode(map[string]interface{}{"v": v}); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSpace(strings.TrimPrefix(b.String(), "v = "))
+}
+
+// tomlKey quotes a key that isn't a bare TOML key, like a path
+func tomlKey(key string) string {
+	if bareTOMLKey.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+var (
+	tomlTableLine = regexp.MustCompile(`^\s*\[\s*([^\[\]]+?)\s*\]\s*(#.*)?$`)
+	tomlKeyLine   = regexp.MustCompile(`^\s*("(?:[^"\\]|\\.)*"|'[^']*'|[A-Za-z0-9_-]+)\s*=(.*)$`)
+	tomlStrings   = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'[^']*'`)
+)
+
+// settingsKeyLines finds a key of a table in config.toml: the first and last line of
+// its value (arrays can span lines), or -1, plus the table's header line (-1 without
+// one) and where a new key goes: below a commented-out example of it like the ones
+// `portage config init` writes, else below the table's last key
+func settingsKeyLines(lines []string, table, key string) (start, end, header, insertAt int) {
+	start, end, header = -1, -1, -1
+	lastKey, example := -1, -1
+	commentedKey := regexp.MustCompile(`^\s*#\s*` + regexp.QuoteMeta(tomlKey(key)) + `\s*=`)
+	current := ""
+	for i := 0; i < len(lines); i++ {
+		if match := tomlTableLine.FindStringSubmatch(lines[i]); match != nil {
+			current = strings.ReplaceAll(match[1], " ", "")
+			if current == table {
+				header, lastKey = i, i
+			}
+			continue
+		}
+		if current == table && commentedKey.MatchString(lines[i]) {
+			example = i
+		}
+		match := tomlKeyLine.FindStringSubmatch(lines[i])
+		if match == nil || current != table {
+			continue
+		}
+		first := i
+		// An array value runs until its brackets balance, ignoring strings and comments
+		depth := 0
+		for rest := match[2]; ; rest = lines[i] {
+			code, _, _ := strings.Cut(tomlStrings.ReplaceAllString(rest, `""`), "#")
+			depth += strings.Count(code, "[") - strings.Count(code, "]")
+			if depth <= 0 || i == len(lines)-1 {
+				break
+			}
+			i++
+		}
+		lastKey = i
+		name := match[1]
+		if unquoted, err := strconv.Unquote(name); err == nil && strings.HasPrefix(name, `"`) {
+			name = unquoted
+		}
+		if strings.Trim(name, "'") == key {
+			start, end = first, i
+		}
+	}
+	if example >= 0 {
+		return start, end, header, example
+	}
+	return start, end, header, lastKey
+}
+
+// setSettingsLine sets key in table to a TOML value: the key's line is replaced, or a
+// new line is added to the table, with the table added if it's missing
+func setSettingsLine(content, table, key, value string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+	line := tomlKey(key) + " = " + value
+	start, end, header, insertAt := settingsKeyLines(lines, table, key)
+	switch {
+	case start >= 0:
+		indent := lines[start][:len(lines[start])-len(strings.TrimLeft(lines[start], " \t"))]
+		lines = append(lines[:start], append([]string{indent + line}, lines[end+1:]...)...)
+	case header >= 0:
+		lines = append(lines[:insertAt+1], append([]string{line}, lines[insertAt+1:]...)...)
+	default:
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+table+"]", line)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// unsetSettingsLine removes key from table, reporting whether it was there
+func unsetSettingsLine(content, table, key string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	start, end, _, _ := settingsKeyLines(lines, table, key)
+	if start < 0 {
+		return content, false
+	}
+	return strings.Join(append(lines[:start], lines[end+1:]...), "\n"), true
+}
+
+// editSettings opens config.toml in the terminal editor ($VISUAL, $EDITOR, else vi),
+// creating it from the template first, and checks it afterwards, offering to go back
+// in until it's valid
+func editSettings(path string) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		writeSettingsFile(path, settingsTemplate())
+	}
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := append(strings.Fields(editor), path)
+
+	input := bufio.NewReader(os.Stdin)
+	for {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running %s: %v\n", args[0], err)
+			os.Exit(1)
+		}
+		_, err := readSettings(path)
+		if err == nil {
+			fmt.Printf("%s is valid\n", shortenPath(path))
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Error in %s:\n%v\n", shortenPath(path), err)
+		fmt.Fprint(os.Stderr, "Edit again? [Y/n] ")
+		answer, _ := input.ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer == "n" || answer == "no" {
+			fmt.Fprintln(os.Stderr, "Kept with errors; portage won't start until they're fixed")
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// confirmPrompt is a y/n question shown before a destructive action (kill, restart,
+// bulk operations); the action runs only on y
+type confirmPrompt struct {
+	question string
+	details  []string // shown above the question, e.g. what will be killed
+	action   func(model) (tea.Model, tea.Cmd)
+}
+
+// confirmThen asks before running action, unless skip_confirm is set in the config
+func (m model) confirmThen(question string, action func(model) (tea.Model, tea.Cmd)) (tea.Model, tea.Cmd) {
+	return m.confirmListThen(question, nil, action)
+}
+
+// confirmListThen is confirmThen with a list of details shown above the question
+func (m model) confirmListThen(question string, details []string, action func(model) (tea.Model, tea.Cmd)) (tea.Model, tea.Cmd) {
+	if m.config.SkipConfirm {
+		return action(m)
+	}
+	m.confirm = &confirmPrompt{question: question, details: details, action: action}
+	return m, nil
+}
+
+// updateConfirm handles keys while a confirmation is open
+func (m model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "y", "Y":
+		action := m.confirm.action
+		m.confirm = nil
+		return action(m)
+	case "n", "N", "esc", "q":
+		m.confirm = nil
+		m.message = "Cancelled"
+	}
+	return m, nil
+}
+
+// viewConfirm renders the question in place of the help line
+func (m model) viewConfirm() string {
+	questionStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Warning)
+	helpStyle := lipgloss.NewStyle().Foreground(ui.Muted)
+	var details string
+	for _, line := range m.confirm.details {
+		details += "  " + line + "\n"
+	}
+	return details + questionStyle.Render(m.confirm.question) + " " + helpStyle.Render("y: yes • n/esc: no")
+}
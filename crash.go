@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// lastCrashReport is the report written for the most recent recovered panic
+var lastCrashReport string
+
+// crashDir is where crash reports are kept, in the state directory (xdg.go)
+func crashDir() string {
+	return filepath.Join(stateDir(), "crash")
+}
+
+// buildVersion describes the binary from its embedded build info (module version and VCS revision)
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version := info.Main.Version
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			version += " " + setting.Value
+		case "vcs.modified":
+			if setting.Value == "true" {
+				version += " (modified)"
+			}
+		}
+	}
+	return version
+}
+
+// writeCrashReport saves a report for a recovered panic, with the home directory and user
+// name sanitized like fixture bundles, and returns its path
+func writeCrashReport(recovered interface{}, stack []byte) (string, error) {
+	if readOnly {
+		return "", errReadOnly
+	}
+
+	var report strings.Builder
+	fmt.Fprintf(&report, "portage crash report\n\n")
+	fmt.Fprintf(&report, "time:    %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&report, "version: %s\n", buildVersion())
+	fmt.Fprintf(&report, "go:      %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&report, "args:    %s\n", strings.Join(os.Args, " "))
+	fmt.Fprintf(&report, "panic:   %v\n\n", recovered)
+	fmt.Fprintf(&report, "%s\n", stack)
+
+	// The last ports added to the history show what was being scanned around the crash
+	if events, err := recentPortEvents(20); err == nil && len(events) > 0 {
+		fmt.Fprintf(&report, "recent ports in %s:\n", shortenPath(getHistoryPath()))
+		for _, event := range events {
+			fmt.Fprintln(&report, event.logLine())
+		}
+	}
+
+	dir := crashDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("crash-%s.txt", time.Now().Format("20060102-150405")))
+	content := newFixtureBundle("").sanitize(report.String())
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// crashGuard records a panic in progress and panics again, so outer recovery (bubbletea
+// restoring the terminal) still happens. Use as `defer crashGuard()`.
+func crashGuard() {
+	if r := recover(); r != nil {
+		if path, err := writeCrashReport(r, debug.Stack()); err == nil {
+			lastCrashReport = path
+		}
+		panic(r)
+	}
+}
+
+// exitOnCrash records a panic, says where the report is and exits. Use as
+// `defer exitOnCrash()` in long-running modes that don't own the terminal.
+func exitOnCrash() {
+	if r := recover(); r != nil {
+		fmt.Fprintf(os.Stderr, "\nportage crashed: %v\n", r)
+		if path, err := writeCrashReport(r, debug.Stack()); err == nil {
+			fmt.Fprintf(os.Stderr, "Crash report saved to %s - please attach it to a bug report\n", path)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s\n", debug.Stack())
+		}
+		os.Exit(2)
+	}
+}
+
+// crashSafeModel wraps a bubbletea model so panics in Update, View and commands are
+// recorded before bubbletea's own recovery restores the terminal
+type crashSafeModel struct {
+	inner tea.Model
+}
+
+func (m crashSafeModel) Init() tea.Cmd {
+	defer crashGuard()
+	return guardCmd(m.inner.Init())
+}
+
+func (m crashSafeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	defer crashGuard()
+	inner, cmd := m.inner.Update(msg)
+	return crashSafeModel{inner: inner}, guardCmd(cmd)
+}
+
+func (m crashSafeModel) View() string {
+	defer crashGuard()
+	return m.inner.View()
+}
+
+// guardCmd wraps a command (and the commands of a batch it returns) in crashGuard
+func guardCmd(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		defer crashGuard()
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			guarded := make(tea.BatchMsg, len(batch))
+			for i, c := range batch {
+				guarded[i] = guardCmd(c)
+			}
+			return guarded
+		}
+		return msg
+	}
+}
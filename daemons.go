@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// daemonPatterns map command line fragments to the tooling daemon they belong to: build
+// and package-manager helpers that keep a socket open between commands. They aren't dev
+// servers, so they're folded out of the list, counts and alerts unless --daemons is given.
+var daemonPatterns = []struct {
+	pattern string
+	tool    string
+}{
+	{"nx/src/daemon", "nx"},
+	{"nx daemon", "nx"},
+	{"turbo daemon", "turbo"},
+	{"turbod", "turbo"},
+	{"pnpm server", "pnpm"},
+	{"pnpm store server", "pnpm"},
+	{"bun --daemon", "bun"},
+	{"bun daemon", "bun"},
+	{"eslint_d", "eslint_d"},
+	{"prettierd", "prettierd"},
+	{"gradledaemon", "Gradle"},
+	{"kotlincompiledaemon", "Kotlin"},
+}
+
+// detectToolingDaemon names the tool a command line belongs to if it's one of its daemons
+func detectToolingDaemon(commandLine string) string {
+	lower := strings.ToLower(commandLine)
+	for _, d := range daemonPatterns {
+		if strings.Contains(lower, d.pattern) {
+			return d.tool
+		}
+	}
+	return ""
+}
+
+func filterToolingDaemons(portsByRange map[int][]PortInfo) map[int][]PortInfo {
+	filtered := make(map[int][]PortInfo)
+
+	for rangeStart, ports := range portsByRange {
+		filtered[rangeStart] = []PortInfo{}
+		for _, port := range ports {
+			if port.Daemon == "" {
+				filtered[rangeStart] = append(filtered[rangeStart], port)
+			}
+		}
+	}
+
+	return filtered
+}
+
+// foldedDaemonsSummary describes the tooling daemons among ports, e.g. "3 tooling daemons (nx, turbo)"
+func foldedDaemonsSummary(ports []PortInfo) string {
+	count := 0
+	tools := make(map[string]bool)
+	for _, port := range ports {
+		if port.Daemon != "" {
+			count++
+			tools[port.Daemon] = true
+		}
+	}
+	if count == 0 {
+		return ""
+	}
+	var names []string
+	for tool := range tools {
+		names = append(names, tool)
+	}
+	sort.Strings(names)
+	noun := "tooling daemons"
+	if count == 1 {
+		noun = "tooling daemon"
+	}
+	return fmt.Sprintf("%d %s (%s)", count, noun, strings.Join(names, ", "))
+}
+
+// displayFoldedDaemons mentions the daemons left out of the table, scoped like the table
+func displayFoldedDaemons(ports []PortInfo, config *Config) {
+	if showDaemons || showAllPorts || showSystemPorts || showOrphans {
+		return
+	}
+	var folded []PortInfo
+	for _, port := range ports {
+		if port.Daemon == "" || !isUserPort(port) || !isUnderPathFilter(port.Path) || config.isHidden(port) {
+			continue
+		}
+		if !matchesUserFilter(port) || !matchesRegexFilter(port) {
+			continue
+		}
+		folded = append(folded, port)
+	}
+	if summary := foldedDaemonsSummary(folded); summary != "" {
+		fmt.Printf("Folded %s - show them with --daemons\n\n", summary)
+	}
+}
@@ -0,0 +1,451 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// demoMode routes every external command to synthetic output (see demoCommandOutput)
+var demoMode bool
+
+// demoProcess is a fake listening process. Project is relative to the demo home.
+type demoProcess struct {
+	PID     string
+	Command string // lsof's truncated name
+	Args    string
+	Project string
+	Ports   []string // lsof addresses, e.g. "*:3000"
+	Etime   string
+	TTY     string
+}
+
+var demoProcesses = []demoProcess{
+	{"41001", "node", "node node_modules/.bin/next dev", "dev/storefront", []string{"*:3000"}, "02:13:45", "ttys003"},
+	{"41050", "node", "node node_modules/.bin/storybook dev -p 6006", "dev/storefront", []string{"*:6006"}, "47:12", ""},
+	{"41022", "node", "node node_modules/.bin/vite --port 5173", "dev/admin", []string{"127.0.0.1:5173"}, "25:10", "ttys004"},
+	{"41075", "turbo", "node_modules/turbo-darwin-arm64/bin/turbo daemon", "dev/admin", []string{"127.0.0.1:3931"}, "1:12:30", ""}, // folded as a tooling daemon
+	{"41100", "python3.1", "python3 -m uvicorn app.main:app --reload --port 8000", "dev/api", []string{"127.0.0.1:8000"}, "1-03:22:10", "ttys005"},
+	{"41210", "ruby", "ruby bundle exec jekyll serve --port 4000", "dev/docs", []string{"127.0.0.1:4000"}, "05:02", ""},
+	{"40988", "node", "node server.js", "dev/old-prototype", []string{"*:3001"}, "3-01:00:07", ""}, // directory deleted
+	{"41300", "ssh", "ssh -fN -L 3020:db.internal:5432 deploy@bastion", "", []string{"127.0.0.1:3020"}, "4:51:00", ""},
+	{"812", "postgres", "/opt/homebrew/opt/postgresql@16/bin/postgres -D /opt/homebrew/var/postgresql@16", "/opt/homebrew/var/postgresql@16", []string{"127.0.0.1:5432"}, "10-02:11:40", ""},
+}
+
+// demoProjects are created inside the demo home so paths resolve like real ones
+var demoProjects = map[string]string{
+	"dev/storefront": "main",
+	"dev/admin":      "feature/billing",
+	"dev/api":        "main",
+	"dev/docs":       "",
+	"dev/blog":       "drafts",
+}
+
+// demoProjectFiles are the .portage.yml files of demo projects; admin declares a port
+// nothing listens on, to show the mismatch warnings
+var demoProjectFiles = map[string]string{
+	"dev/storefront": "launch: npm run dev\nports: [3000, 6006]\naliases:\n  6006: storybook\n",
+	"dev/admin":      "name: admin\nlaunch: pnpm dev\nports: [5173, 3001]\naliases:\n  3001: billing-api\n",
+	"dev/docs":       "launch: npm run docs\nports: [4000]\n",
+}
+
+// startDemo builds a throwaway home directory with synthetic history and state and
+// switches commandOutput to fake data. Everything else runs the normal code paths.
+func startDemo() (cleanup func(), err error) {
+	home, err := os.MkdirTemp("", "portage-demo-")
+	if err != nil {
+		return nil, err
+	}
+	cleanup = func() { os.RemoveAll(home) }
+
+	if err := writeDemoHome(home); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	os.Setenv("HOME", home)
+	os.Unsetenv("XDG_CONFIG_HOME") // config.toml and state come from the demo home too
+	os.Unsetenv("XDG_STATE_HOME")
+	demoMode = true
+	return cleanup, nil
+}
+
+// demoPath resolves a demo project to an absolute path inside the demo home
+func demoPath(home, project string) string {
+	if project == "" {
+		return home
+	}
+	if filepath.IsAbs(project) {
+		return project
+	}
+	return filepath.Join(home, project)
+}
+
+func writeDemoHome(home string) error {
+	for project, branch := range demoProjects {
+		dir := filepath.Join(home, project)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		if branch == "" {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Join(dir, ".git"), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref: refs/heads/"+branch+"\n"), 0644); err != nil {
+			return err
+		}
+	}
+	for project, content := range demoProjectFiles {
+		if err := os.WriteFile(filepath.Join(home, project, projectFileName), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+
+	config := map[string]interface{}{
+		"aliases":         map[string]string{"3000": "storefront", "~/dev/api": "API"},
+		"sensitive_ports": []int{5432},
+	}
+	data, _ := json.MarshalIndent(config, "", "  ")
+	// The XDG defaults (xdg.go), which macOS also uses once they exist
+	configHome, stateHome := filepath.Join(home, ".config", "portage"), filepath.Join(home, ".local", "state", "portage")
+	for _, dir := range []string{configHome, stateHome} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(configHome, "portage.json"), data, 0644); err != nil {
+		return err
+	}
+
+	// A fixed seed keeps screenshots identical between runs
+	rng := rand.New(rand.NewSource(42))
+	now := time.Now()
+	projects := []string{"dev/storefront", "dev/admin", "dev/api", "dev/docs", "dev/blog"}
+	ports := map[string]int{"dev/storefront": 3000, "dev/admin": 5173, "dev/api": 8000, "dev/docs": 4000, "dev/blog": 4001}
+	commandLines := map[string]string{
+		"dev/storefront": "node node_modules/.bin/next dev",
+		"dev/admin":      "node node_modules/.bin/vite --port 5173",
+		"dev/api":        "node --watch src/server.js --port 8000",
+		"dev/docs":       "node node_modules/.bin/docusaurus start --port 4000",
+		"dev/blog":       "node node_modules/.bin/astro dev --port 4001",
+	}
+
+	// Lifetimes have their own source so the rest of the data stays as it was
+	lifetimes := rand.New(rand.NewSource(7))
+	var servers []demoServer
+	var claudeLog strings.Builder
+	for day := 90; day >= 1; day-- {
+		date := now.AddDate(0, 0, -day)
+		if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			if rng.Intn(4) != 0 {
+				continue
+			}
+		}
+		for _, project := range projects {
+			if rng.Intn(3) == 0 {
+				continue
+			}
+			start := time.Date(date.Year(), date.Month(), date.Day(), 9+rng.Intn(9), rng.Intn(60), 0, 0, time.Local)
+			path := filepath.Join(home, project)
+			pid := strconv.Itoa(30000 + rng.Intn(9999))
+			lifetime := time.Duration(20+lifetimes.Intn(6*60)) * time.Minute
+			servers = append(servers, demoServer{ports[project], pid, path, commandLines[project], start, start.Add(lifetime)})
+
+			session := fmt.Sprintf("demo-%d-%s", day, filepath.Base(project))
+			for prompt := 0; prompt < 1+rng.Intn(6); prompt++ {
+				entry := ClaudeHistoryEntry{
+					Display:   demoPrompts[rng.Intn(len(demoPrompts))],
+					Timestamp: start.Add(time.Duration(prompt*7) * time.Minute).UnixMilli(),
+					Project:   path,
+					SessionID: session,
+				}
+				line, _ := json.Marshal(entry)
+				claudeLog.Write(line)
+				claudeLog.WriteString("\n")
+			}
+		}
+	}
+	if err := writeDemoHistory(filepath.Join(stateHome, "history.db"), servers); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(home, ".claude"), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(home, ".claude", "history.jsonl"), []byte(claudeLog.String()), 0644); err != nil {
+		return err
+	}
+
+	// The running session in dev/api (demoClaudePID) has a transcript for the Claude view
+	transcriptDir := filepath.Join(home, ".claude", "projects", transcriptDirName(filepath.Join(home, "dev/api")))
+	if err := os.MkdirAll(transcriptDir, 0755); err != nil {
+		return err
+	}
+	transcript := `{"type":"user","message":{"role":"user","content":"add pagination to the /orders endpoint"}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"I'll add limit and cursor parameters to the orders query."},{"type":"tool_use","name":"Edit"}]}}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Pagination is in place: /orders takes ?limit= and ?cursor= and returns next_cursor."}]}}
+`
+	if err := os.WriteFile(filepath.Join(transcriptDir, "demo-session.jsonl"), []byte(transcript), 0644); err != nil {
+		return err
+	}
+
+	// dev/blog was closed yesterday, so it shows up in --cursor-history
+	closed := now.Add(-26 * time.Hour).Unix()
+	workspaceLog := fmt.Sprintf("%d,open,%s\n%d,close,%s\n", closed-3*3600, filepath.Join(home, "dev/blog"), closed, filepath.Join(home, "dev/blog"))
+	if err := os.WriteFile(filepath.Join(stateHome, "workspaces.log"), []byte(workspaceLog), 0644); err != nil {
+		return err
+	}
+
+	return writeDemoCursorState(home, now)
+}
+
+// demoServer is a dev server of the synthetic history
+type demoServer struct {
+	Port        int
+	PID         string
+	Path        string
+	CommandLine string
+	Start, End  time.Time
+}
+
+// writeDemoHistory writes history.db the way recordScan would have: every start (the
+// old logs kept each one), a snapshot each time a server starts or stops, and the
+// stops, for `portage history`, `portage stats` and `portage history at`
+func writeDemoHistory(path string, servers []demoServer) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if _, err := db.Exec(historySchema); err != nil {
+		return err
+	}
+	var changes []time.Time
+	for _, server := range servers {
+		changes = append(changes, server.Start, server.End)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Before(changes[j]) })
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, server := range servers {
+		if _, err := tx.Exec(`INSERT INTO port_events (started_at, port, pid, command, path, command_line, user) VALUES (?, ?, ?, 'node', ?, ?, 'demo')`,
+			server.Start.Unix(), server.Port, server.PID, server.Path, server.CommandLine); err != nil {
+			return err
+		}
+	}
+	for _, at := range changes {
+		result, err := tx.Exec(`INSERT INTO scans (at) VALUES (?)`, at.Unix())
+		if err != nil {
+			return err
+		}
+		scanID, _ := result.LastInsertId()
+		for _, server := range servers {
+			if server.End.Equal(at) {
+				if _, err := tx.Exec(`INSERT INTO port_closes (closed_at, started_at, port, pid, command, path) VALUES (?, ?, ?, ?, 'node', ?)`,
+					at.Unix(), server.Start.Unix(), server.Port, server.PID, server.Path); err != nil {
+					return err
+				}
+			}
+			if server.Start.After(at) || !server.End.After(at) {
+				continue
+			}
+			if _, err := tx.Exec(`INSERT INTO scan_ports (scan_id, port, pid, command, path, started_at) VALUES (?, ?, ?, 'node', ?, ?)`,
+				scanID, server.Port, server.PID, server.Path, server.Start.Unix()); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// writeDemoCursorState creates Cursor's per-workspace storage and the global recent-paths database
+func writeDemoCursorState(home string, now time.Time) error {
+	cursorUser := filepath.Join(home, "Library", "Application Support", "Cursor", "User")
+	lastActive := map[string]time.Duration{
+		"dev/storefront": 2 * time.Minute,
+		"dev/api":        40 * time.Minute,
+		"dev/admin":      3 * time.Hour,
+	}
+	for project, ago := range lastActive {
+		dir := filepath.Join(cursorUser, "workspaceStorage", filepath.Base(project))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		folder := (&url.URL{Scheme: "file", Path: filepath.Join(home, project)}).String()
+		data, _ := json.Marshal(map[string]string{"folder": folder})
+		if err := os.WriteFile(filepath.Join(dir, "workspace.json"), data, 0644); err != nil {
+			return err
+		}
+		state := filepath.Join(dir, "state.vscdb")
+		if err := os.WriteFile(state, nil, 0644); err != nil {
+			return err
+		}
+		os.Chtimes(state, now.Add(-ago), now.Add(-ago))
+	}
+
+	globalStorage := filepath.Join(cursorUser, "globalStorage")
+	if err := os.MkdirAll(globalStorage, 0755); err != nil {
+		return err
+	}
+	db, err := sql.Open("sqlite", filepath.Join(globalStorage, "state.vscdb"))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var history CursorHistory
+	for _, project := range []string{"dev/storefront", "dev/api", "dev/admin", "dev/blog", "dev/docs"} {
+		history.Entries = append(history.Entries, CursorHistoryEntry{FolderURI: "file://" + filepath.Join(home, project)})
+	}
+	historyJSON, _ := json.Marshal(history)
+	if _, err := db.Exec("CREATE TABLE ItemTable (key TEXT UNIQUE ON CONFLICT REPLACE, value BLOB)"); err != nil {
+		return err
+	}
+	_, err = db.Exec("INSERT INTO ItemTable (key, value) VALUES ('history.recentlyOpenedPathsList', ?)", string(historyJSON))
+	return err
+}
+
+var demoPrompts = []string{
+	"add pagination to the orders endpoint",
+	"why is the checkout test flaky?",
+	"refactor the cart reducer",
+	"write a migration for the invoices table",
+	"fix the dark mode colors in the sidebar",
+}
+
+// demoCommandOutput answers the external commands portage runs with synthetic data.
+// Anything not modelled fails, just like a missing tool would.
+func demoCommandOutput(name string, args []string) ([]byte, error) {
+	home, _ := os.UserHomeDir()
+	full := strings.Join(append([]string{name}, args...), " ")
+
+	switch {
+	case full == "lsof -i -P -n":
+		var out strings.Builder
+		out.WriteString("COMMAND     PID USER   FD   TYPE             DEVICE SIZE/OFF NODE NAME\n")
+		for _, proc := range demoProcesses {
+			for _, addr := range proc.Ports {
+				fmt.Fprintf(&out, "%-9s %5s demo   23u  IPv4 0x1f2e3d4c5b6a7988      0t0  TCP %s (LISTEN)\n", proc.Command, proc.PID, addr)
+			}
+		}
+		return []byte(out.String()), nil
+
+	case name == "lsof" && len(args) == 6 && args[0] == "-a" && args[4] == "cwd":
+		if proc, ok := findDemoProcess(args[2]); ok {
+			return []byte(fmt.Sprintf("p%s\nfcwd\nn%s\n", proc.PID, demoPath(home, proc.Project))), nil
+		}
+
+	case name == "ps" && len(args) == 5 && args[0] == "-ww":
+		// The inspect view (i)
+		if proc, ok := findDemoProcess(args[4]); ok {
+			return []byte(fmt.Sprintf("  PID  PPID USER  STARTED                          ELAPSED %%CPU %%MEM    RSS COMMAND\n%5s     1 demo  Thu Oct  9 09:12:44 2026 %14s  0.4  1.1 184320 %s\n", proc.PID, proc.Etime, proc.Args)), nil
+		}
+
+	case name == "lsof" && len(args) == 4 && args[0] == "-n" && args[2] == "-p":
+		if proc, ok := findDemoProcess(args[3]); ok {
+			var out strings.Builder
+			out.WriteString("COMMAND     PID USER   FD   TYPE             DEVICE SIZE/OFF     NODE NAME\n")
+			fmt.Fprintf(&out, "%-9s %5s demo  cwd    DIR                1,4      640  1234567 %s\n", proc.Command, proc.PID, demoPath(home, proc.Project))
+			if proc.TTY != "" {
+				fmt.Fprintf(&out, "%-9s %5s demo    1u   CHR               16,5   0t1024     1117 /dev/%s\n", proc.Command, proc.PID, proc.TTY)
+			}
+			for _, addr := range proc.Ports {
+				fmt.Fprintf(&out, "%-9s %5s demo   23u  IPv4 0x1f2e3d4c5b6a7988      0t0      TCP %s (LISTEN)\n", proc.Command, proc.PID, addr)
+			}
+			return []byte(out.String()), nil
+		}
+
+	case name == "lsof" && len(args) == 2 && args[0] == "-p":
+		if args[1] == demoClaudePID {
+			return []byte(fmt.Sprintf("claude  %s demo  cwd    DIR  1,4  640  123 %s\n", demoClaudePID, filepath.Join(home, "dev/api"))), nil
+		}
+
+	case name == "ps" && len(args) == 4 && args[0] == "-p" && args[3] == "etime=":
+		if proc, ok := findDemoProcess(args[1]); ok {
+			return []byte(proc.Etime + "\n"), nil
+		}
+
+	case name == "ps" && len(args) == 4 && args[0] == "-o" && args[2] == "-p":
+		var out strings.Builder
+		for _, pid := range strings.Split(args[3], ",") {
+			proc, ok := findDemoProcess(pid)
+			if !ok {
+				continue
+			}
+			switch args[1] {
+			case "pid=,args=":
+				fmt.Fprintf(&out, "%s %s\n", proc.PID, proc.Args)
+			case "pid=,%cpu=":
+				// Stable made-up load so the cpu sort has something to order
+				n, _ := strconv.Atoi(proc.PID)
+				fmt.Fprintf(&out, "%s %.1f\n", proc.PID, float64(n%97)/3)
+			case "pid=,tty=":
+				tty := proc.TTY
+				if tty == "" {
+					tty = "??"
+				}
+				fmt.Fprintf(&out, "%s %s\n", proc.PID, tty)
+			}
+		}
+		return []byte(out.String()), nil
+
+	case full == "ps -axo pid=,args=":
+		var out strings.Builder
+		for _, proc := range demoProcesses {
+			fmt.Fprintf(&out, "%s %s\n", proc.PID, proc.Args)
+		}
+		return []byte(out.String()), nil
+
+	case name == "tmux" && len(args) > 0 && args[0] == "list-panes":
+		return []byte("/dev/ttys003\tshop:1.0\tstorefront\n/dev/ttys004\tshop:2.0\tadmin\n"), nil
+
+	case name == "osascript" && len(args) == 2 && strings.Contains(args[1], `every window of application process "Cursor"`):
+		return []byte("page.tsx — ~/dev/storefront, main.py — ~/dev/api, Billing.tsx — ~/dev/admin\n"), nil
+
+	case name == "osascript" && len(args) == 2 && strings.Contains(args[1], `application "Google Chrome" is running`):
+		return []byte("http://localhost:3000/cart\nhttp://localhost:3000/\nhttp://127.0.0.1:5173/invoices\nhttps://github.com/\n"), nil
+
+	case name == "sh" && strings.Contains(full, "grep -i claude"):
+		return []byte(fmt.Sprintf("demo  %s  3.1  1.2 4123456 204800 ttys005 S+ 9:41AM 0:12.34 claude\n", demoClaudePID)), nil
+	}
+
+	return nil, fmt.Errorf("%s is not available in demo mode", name)
+}
+
+// demoClaudePID is the fake Claude session shown by --claude
+const demoClaudePID = "42000"
+
+// demoHTTPCheck answers --check with fixed results, including a server that hangs
+func demoHTTPCheck(port PortInfo) string {
+	switch port.Port {
+	case 3000, 5173, 6006, 8000:
+		return "200"
+	case 4000:
+		return "404"
+	case 3001:
+		return "timeout"
+	}
+	return ""
+}
+
+func findDemoProcess(pid string) (demoProcess, bool) {
+	for _, proc := range demoProcesses {
+		if proc.PID == pid {
+			return proc, true
+		}
+	}
+	return demoProcess{}, false
+}
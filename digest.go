@@ -0,0 +1,238 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// digestProject is what happened in one project over the digest's period
+type digestProject struct {
+	Project        string `json:"project"`
+	EditorSeconds  int    `json:"editor_seconds"` // open in the editor, from workspace events
+	ClaudeSessions int    `json:"claude_sessions"`
+	Prompts        int    `json:"prompts"`
+	Servers        int    `json:"servers"`
+	ServerSeconds  int    `json:"server_seconds"` // of the servers whose lifetime is known
+}
+
+// digestServer is a dev server run once or more in the period: the same command on
+// the same port in the same project
+type digestServer struct {
+	Project   string `json:"project"`
+	Command   string `json:"command"`
+	Port      int    `json:"port"`
+	Runs      int    `json:"runs"`
+	Seconds   int    `json:"seconds"`
+	FirstRun  string `json:"first_run"` // RFC 3339
+	StillRuns bool   `json:"still_runs"`
+}
+
+type digestTotals struct {
+	Projects       int `json:"projects"`
+	EditorSeconds  int `json:"editor_seconds"`
+	ClaudeSessions int `json:"claude_sessions"`
+	Prompts        int `json:"prompts"`
+	Servers        int `json:"servers"`
+	ServerSeconds  int `json:"server_seconds"`
+}
+
+type activityDigest struct {
+	Since    string          `json:"since"` // RFC 3339
+	Until    string          `json:"until"`
+	Projects []digestProject `json:"projects"` // most time first
+	Servers  []digestServer  `json:"servers"`
+	Totals   digestTotals    `json:"totals"`
+}
+
+// overlap returns how much of [from, to] falls in [since, until]
+func overlap(from, to, since, until time.Time) time.Duration {
+	if from.Before(since) {
+		from = since
+	}
+	if to.After(until) {
+		to = until
+	}
+	if !to.After(from) {
+		return 0
+	}
+	return to.Sub(from)
+}
+
+// buildDigest summarizes the period from since to now: the projects worked on in the
+// editor and with Claude, and the dev servers that ran in them
+func buildDigest(since, now time.Time) activityDigest {
+	projects := make(map[string]*digestProject)
+	project := func(path string) *digestProject {
+		path = activityProject(path)
+		if projects[path] == nil {
+			projects[path] = &digestProject{Project: path}
+		}
+		return projects[path]
+	}
+
+	for path, spans := range workspaceSpans(since, now) {
+		project(path).EditorSeconds += spansSeconds(spans)
+	}
+
+	sessions, _ := queryEvents(eventQuery{Types: []string{EventClaudeSession}, Since: since, Until: now})
+	for _, event := range sessions {
+		p := project(event.Path)
+		p.ClaudeSessions++
+		p.Prompts += event.Session.MessageCount
+	}
+
+	servers := make(map[string]*digestServer)
+	var serverOrder []*digestServer
+	serverSessions, _ := loadServerSessions(since, now)
+	for _, session := range serverSessions {
+		p := project(session.Project)
+		p.Servers++
+		key := fmt.Sprintf("%s\t%s\t%d", p.Project, session.Command, session.Port)
+		server := servers[key]
+		if server == nil {
+			server = &digestServer{Project: p.Project, Command: session.Command, Port: session.Port, FirstRun: session.Started.Format(time.RFC3339)}
+			servers[key] = server
+			serverOrder = append(serverOrder, server)
+		}
+		server.Runs++
+		if lifetime, ok := session.lifetime(); ok {
+			p.ServerSeconds += int(lifetime.Seconds())
+			server.Seconds += int(lifetime.Seconds())
+		}
+		server.StillRuns = server.StillRuns || session.Running
+	}
+
+	digest := activityDigest{
+		Since:    since.Format(time.RFC3339),
+		Until:    now.Format(time.RFC3339),
+		Projects: []digestProject{},
+		Servers:  []digestServer{},
+	}
+	for _, p := range projects {
+		if p.EditorSeconds == 0 && p.ClaudeSessions == 0 && p.Servers == 0 {
+			continue
+		}
+		digest.Projects = append(digest.Projects, *p)
+		digest.Totals.Projects++
+		digest.Totals.EditorSeconds += p.EditorSeconds
+		digest.Totals.ClaudeSessions += p.ClaudeSessions
+		digest.Totals.Prompts += p.Prompts
+		digest.Totals.Servers += p.Servers
+		digest.Totals.ServerSeconds += p.ServerSeconds
+	}
+	sort.Slice(digest.Projects, func(i, j int) bool {
+		a, b := digest.Projects[i], digest.Projects[j]
+		if a.EditorSeconds+a.ServerSeconds != b.EditorSeconds+b.ServerSeconds {
+			return a.EditorSeconds+a.ServerSeconds > b.EditorSeconds+b.ServerSeconds
+		}
+		if a.Prompts != b.Prompts {
+			return a.Prompts > b.Prompts
+		}
+		return a.Project < b.Project
+	})
+	for _, server := range serverOrder {
+		digest.Servers = append(digest.Servers, *server)
+	}
+	sort.SliceStable(digest.Servers, func(i, j int) bool { return digest.Servers[i].Seconds > digest.Servers[j].Seconds })
+	return digest
+}
+
+// totalDuration formats seconds of tracked time in hours and minutes, which add up
+// better on a timesheet than days; "-" for none
+func totalDuration(seconds int) string {
+	if seconds == 0 {
+		return "-"
+	}
+	minutes := (seconds + 30) / 60
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %02dm", minutes/60, minutes%60)
+}
+
+// runDigest implements `portage digest`
+func runDigest(args []string) {
+	fs := flag.NewFlagSet("digest", flag.ExitOnError)
+	week := fs.Bool("week", false, "Summarize the past 7 days instead of the past day")
+	sinceFlag := fs.String("since", "", "Summarize since: a duration ago (2h, 7d), a day (yesterday, monday) or a date")
+	markdown := fs.Bool("markdown", false, "Output as Markdown, for notes and timesheets")
+	asJSON := fs.Bool("json", false, "Output as JSON")
+	fs.Parse(args)
+
+	now := time.Now()
+	since, period := now.AddDate(0, 0, -1), "the past day"
+	if *week {
+		since, period = now.AddDate(0, 0, -7), "the past week"
+	}
+	if *sinceFlag != "" {
+		var err error
+		if since, err = parseHistoryTime(*sinceFlag, now); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --since: %v\n", err)
+			os.Exit(1)
+		}
+		period = "since " + since.Format("Mon 2006-01-02 15:04")
+	}
+
+	digest := buildDigest(since, now)
+	if *asJSON {
+		writeJSON("digest", digest)
+		return
+	}
+
+	projects := newTable(table.StyleDefault) // the style is ignored for Markdown
+	projects.AppendHeader(table.Row{"PROJECT", "EDITOR", "CLAUDE SESSIONS", "PROMPTS", "DEV SERVERS", "SERVER TIME"})
+	for _, p := range digest.Projects {
+		projects.AppendRow(table.Row{shortenPath(p.Project), totalDuration(p.EditorSeconds), p.ClaudeSessions, p.Prompts, p.Servers, totalDuration(p.ServerSeconds)})
+	}
+	servers := newTable(table.StyleDefault)
+	servers.AppendHeader(table.Row{"PROJECT", "COMMAND", "PORT", "RUNS", "RAN FOR"})
+	for _, s := range digest.Servers {
+		ranFor := totalDuration(s.Seconds)
+		if s.StillRuns {
+			ranFor += " (running)"
+		}
+		servers.AppendRow(table.Row{shortenPath(s.Project), s.Command, s.Port, s.Runs, ranFor})
+	}
+	editor := "no time in the editor"
+	if digest.Totals.EditorSeconds > 0 {
+		editor = totalDuration(digest.Totals.EditorSeconds) + " in the editor"
+	}
+	totals := fmt.Sprintf("%d projects, %s, %d Claude sessions (%d prompts), %d dev servers",
+		digest.Totals.Projects, editor, digest.Totals.ClaudeSessions, digest.Totals.Prompts, digest.Totals.Servers)
+	if digest.Totals.ServerSeconds > 0 {
+		totals += " for " + totalDuration(digest.Totals.ServerSeconds)
+	}
+	span := fmt.Sprintf("%s - %s", since.Format("2006-01-02 15:04"), now.Format("2006-01-02 15:04"))
+
+	if *markdown {
+		fmt.Printf("# Digest for %s\n\n%s\n\n", period, span)
+		if len(digest.Projects) == 0 {
+			fmt.Printf("Nothing recorded.\n")
+			return
+		}
+		fmt.Printf("## Projects\n\n%s\n\n", projects.RenderMarkdown())
+		if len(digest.Servers) > 0 {
+			fmt.Printf("## Dev servers\n\n%s\n\n", servers.RenderMarkdown())
+		}
+		fmt.Printf("## Totals\n\n%s\n", totals)
+		return
+	}
+
+	fmt.Printf("\n%s%sPORTAGE - Digest for %s%s (%s)\n\n", ColorBold, ColorCyan, period, ColorReset, span)
+	if len(digest.Projects) == 0 {
+		fmt.Printf("%sNothing recorded - run portage for a while, or try --week%s\n\n", ColorYellow, ColorReset)
+		return
+	}
+	fmt.Printf("%sProjects%s\n", ColorBold, ColorReset)
+	fmt.Println(projects.Render())
+	if len(digest.Servers) > 0 {
+		fmt.Printf("\n%sDev servers%s\n", ColorBold, ColorReset)
+		fmt.Println(servers.Render())
+	}
+	fmt.Printf("\n%s%sTotal: %s%s\n\n", ColorBold, ColorCyan, totals, ColorReset)
+}
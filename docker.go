@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// publishImage forwards a published host port to the container (see publishContainerPort)
+const publishImage = "alpine/socat:latest"
+
+// containerPort is a port a container exposes without publishing it on the host, so
+// the service listens inside the container while localhost refuses connections
+type containerPort struct {
+	ContainerID string
+	Container   string // container name without the leading slash
+	Project     string // compose working directory, the project the container belongs to
+	Port        int
+	Network     string
+	IP          string // container address on Network
+}
+
+type dockerContainer struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Labels map[string]string `json:"Labels"`
+	Ports  []struct {
+		PrivatePort int    `json:"PrivatePort"`
+		PublicPort  int    `json:"PublicPort"`
+		Type        string `json:"Type"`
+	} `json:"Ports"`
+	HostConfig struct {
+		NetworkMode string `json:"NetworkMode"`
+	} `json:"HostConfig"`
+	NetworkSettings struct {
+		Networks map[string]struct {
+			IPAddress string `json:"IPAddress"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// dockerSocketPath finds the Docker Engine socket: DOCKER_HOST, the system socket or
+// Docker Desktop's per-user one
+func dockerSocketPath() string {
+	if host := os.Getenv("DOCKER_HOST"); strings.HasPrefix(host, "unix://") {
+		return strings.TrimPrefix(host, "unix://")
+	}
+	home, _ := os.UserHomeDir()
+	for _, path := range []string{"/var/run/docker.sock", filepath.Join(home, ".docker", "run", "docker.sock")} {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// dockerRequest calls the Engine API over its unix socket and decodes the JSON reply into out (if non-nil)
+func dockerRequest(method, path string, body interface{}, out interface{}, timeout time.Duration) error {
+	socket := dockerSocketPath()
+	if socket == "" {
+		return fmt.Errorf("docker socket not found")
+	}
+
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = strings.NewReader(string(data))
+	}
+	req, err := http.NewRequest(method, "http://docker"+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("docker %s %s: %s", method, path, apiErr.Message)
+	}
+	if out == nil {
+		_, err = io.Copy(io.Discard, resp.Body) // e.g. image pull progress
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// listUnpublishedContainerPorts returns exposed TCP ports of running compose containers
+// that aren't published on the host, scoped to --path if given
+func listUnpublishedContainerPorts() []containerPort {
+	if demoMode || dockerSocketPath() == "" {
+		return nil
+	}
+
+	var containers []dockerContainer
+	if err := dockerRequest("GET", "/containers/json", nil, &containers, 2*time.Second); err != nil {
+		return nil
+	}
+
+	var unpublished []containerPort
+	for _, c := range containers {
+		project := c.Labels["com.docker.compose.project.working_dir"]
+		if project == "" || !isUnderPathFilter(project) || c.HostConfig.NetworkMode == "host" {
+			continue
+		}
+
+		// The same port shows up once per host binding, and once without one if unpublished
+		published := make(map[int]bool)
+		for _, p := range c.Ports {
+			if p.PublicPort != 0 {
+				published[p.PrivatePort] = true
+			}
+		}
+
+		// Sidecars attach to the container's first network (by name, for stable output)
+		var networks []string
+		for name := range c.NetworkSettings.Networks {
+			networks = append(networks, name)
+		}
+		sort.Strings(networks)
+		if len(networks) == 0 {
+			continue
+		}
+		network := networks[0]
+
+		name := c.ID[:12]
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+
+		seen := make(map[int]bool)
+		for _, p := range c.Ports {
+			if p.Type != "tcp" || published[p.PrivatePort] || seen[p.PrivatePort] {
+				continue
+			}
+			seen[p.PrivatePort] = true
+			unpublished = append(unpublished, containerPort{
+				ContainerID: c.ID,
+				Container:   name,
+				Project:     project,
+				Port:        p.PrivatePort,
+				Network:     network,
+				IP:          c.NetworkSettings.Networks[network].IPAddress,
+			})
+		}
+	}
+
+	sort.Slice(unpublished, func(i, j int) bool {
+		if unpublished[i].Container != unpublished[j].Container {
+			return unpublished[i].Container < unpublished[j].Container
+		}
+		return unpublished[i].Port < unpublished[j].Port
+	})
+	return unpublished
+}
+
+// publishContainerPort makes a container port reachable on localhost:<port>. Docker
+// can't add a -p mapping to a running container, so this starts a small socat
+// container on the same network that publishes the port and forwards to it. The
+// sidecar removes itself when stopped.
+func publishContainerPort(cp containerPort) error {
+	if readOnly {
+		return errReadOnly
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", cp.Port))
+	if err != nil {
+		return fmt.Errorf("localhost:%d is already in use", cp.Port)
+	}
+	listener.Close()
+
+	image, tag, _ := strings.Cut(publishImage, ":")
+	if err := dockerRequest("POST", "/images/create?fromImage="+image+"&tag="+tag, nil, nil, 2*time.Minute); err != nil {
+		return err
+	}
+
+	portKey := strconv.Itoa(cp.Port) + "/tcp"
+	spec := map[string]interface{}{
+		"Image": publishImage,
+		"Cmd": []string{
+			fmt.Sprintf("TCP-LISTEN:%d,fork,reuseaddr", cp.Port),
+			fmt.Sprintf("TCP:%s:%d", cp.IP, cp.Port),
+		},
+		"ExposedPorts": map[string]interface{}{portKey: struct{}{}},
+		"Labels":       map[string]string{"portage.publish": cp.Container},
+		"HostConfig": map[string]interface{}{
+			"AutoRemove":   true,
+			"NetworkMode":  cp.Network,
+			"PortBindings": map[string]interface{}{portKey: []map[string]string{{"HostIp": "127.0.0.1", "HostPort": strconv.Itoa(cp.Port)}}},
+		},
+	}
+
+	var created struct {
+		ID string `json:"Id"`
+	}
+	name := fmt.Sprintf("portage-publish-%s-%d", cp.Container, cp.Port)
+	if err := dockerRequest("POST", "/containers/create?name="+name, spec, &created, 10*time.Second); err != nil {
+		return err
+	}
+	return dockerRequest("POST", "/containers/"+created.ID+"/start", nil, nil, 10*time.Second)
+}
+
+// displayUnpublishedContainerPorts explains container ports that localhost can't reach
+func displayUnpublishedContainerPorts(ports []containerPort) {
+	if len(ports) == 0 {
+		return
+	}
+	fmt.Printf("%s%sListening inside containers but not published (localhost refuses these):%s\n", ColorBold, ColorYellow, ColorReset)
+	for _, cp := range ports {
+		fmt.Printf("  %d  %s  %s\n", cp.Port, cp.Container, shortenPath(cp.Project))
+	}
+	fmt.Printf("Publish one with p in interactive mode, or add it under ports: in docker-compose.yml\n\n")
+}
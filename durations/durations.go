@@ -0,0 +1,115 @@
+// Package durations parses process elapsed times and formats durations for display.
+package durations
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseEtime parses the elapsed time printed by `ps -o etime`: [[DD-]HH:]MM:SS.
+// Days are unbounded ("123-04:05:06"); minutes and seconds must be below 60.
+func ParseEtime(etime string) (time.Duration, error) {
+	s := strings.TrimSpace(etime)
+
+	days := 0
+	if before, after, ok := strings.Cut(s, "-"); ok {
+		n, err := parseField(before, -1)
+		if err != nil {
+			return 0, fmt.Errorf("invalid etime %q: days: %w", etime, err)
+		}
+		days = n
+		s = after
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid etime %q: want [[DD-]HH:]MM:SS", etime)
+	}
+	if days > 0 && len(parts) != 3 {
+		return 0, fmt.Errorf("invalid etime %q: days without hours", etime)
+	}
+
+	hours := 0
+	if len(parts) == 3 {
+		n, err := parseField(parts[0], -1)
+		if err != nil {
+			return 0, fmt.Errorf("invalid etime %q: hours: %w", etime, err)
+		}
+		hours = n
+		parts = parts[1:]
+	}
+	minutes, err := parseField(parts[0], 60)
+	if err != nil {
+		return 0, fmt.Errorf("invalid etime %q: minutes: %w", etime, err)
+	}
+	seconds, err := parseField(parts[1], 60)
+	if err != nil {
+		return 0, fmt.Errorf("invalid etime %q: seconds: %w", etime, err)
+	}
+
+	return time.Duration(days)*24*time.Hour +
+		time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds)*time.Second, nil
+}
+
+// parseField parses a non-negative decimal field, optionally bounded (limit < 0 means none)
+func parseField(s string, limit int) (int, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty field")
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("%q is not a number", s)
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if limit >= 0 && n >= limit {
+		return 0, fmt.Errorf("%d out of range", n)
+	}
+	return n, nil
+}
+
+// Thresholds decide which unit a duration is shown in: minutes below Minutes,
+// days from Days on, hours in between.
+type Thresholds struct {
+	Minutes time.Duration
+	Days    time.Duration
+	JustNow time.Duration // Ago prints "just now" below this; zero disables it
+}
+
+// Uptime is the policy for how long a process has been running: hours are more
+// useful than days for anything up to about a week ("27h", "150h", "9d").
+var Uptime = Thresholds{Minutes: 3 * time.Hour, Days: 200 * time.Hour}
+
+// Recency is the policy for how long ago something happened ("5m ago", "3h ago", "2d ago").
+var Recency = Thresholds{Minutes: time.Hour, Days: 24 * time.Hour, JustNow: time.Minute}
+
+// Format renders d as a whole number of minutes, hours or days, e.g. "45m", "27h", "9d".
+// Negative durations (clock skew) are treated as zero.
+func (t Thresholds) Format(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	switch {
+	case d < t.Minutes:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d >= t.Days:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	default:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	}
+}
+
+// Ago renders d as a relative time, e.g. "just now" or "3h ago"
+func (t Thresholds) Ago(d time.Duration) string {
+	if d < t.JustNow {
+		return "just now"
+	}
+	return t.Format(d) + " ago"
+}
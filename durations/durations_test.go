@@ -0,0 +1,119 @@
+package durations
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseEtime(t *testing.T) {
+	tests := []struct {
+		etime string
+		want  time.Duration
+	}{
+		{"00:00", 0},
+		{"00:01", time.Second},
+		{"5:23", 5*time.Minute + 23*time.Second},
+		{"59:59", 59*time.Minute + 59*time.Second},
+		{"1:00:00", time.Hour},
+		{"01:23:45", time.Hour + 23*time.Minute + 45*time.Second},
+		{"23:59:59", 23*time.Hour + 59*time.Minute + 59*time.Second},
+		{"1-00:00:00", 24 * time.Hour},
+		{"3-12:34:56", 3*24*time.Hour + 12*time.Hour + 34*time.Minute + 56*time.Second},
+		{"99-23:59:59", 99*24*time.Hour + 23*time.Hour + 59*time.Minute + 59*time.Second},
+		{"100-00:00:00", 100 * 24 * time.Hour},
+		{"123-04:05:06", 123*24*time.Hour + 4*time.Hour + 5*time.Minute + 6*time.Second},
+		{"1234-00:00:01", 1234*24*time.Hour + time.Second},
+		{"  12:34\n", 12*time.Minute + 34*time.Second},
+	}
+	for _, tt := range tests {
+		got, err := ParseEtime(tt.etime)
+		if err != nil {
+			t.Errorf("ParseEtime(%q) error: %v", tt.etime, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseEtime(%q) = %v, want %v", tt.etime, got, tt.want)
+		}
+	}
+}
+
+func TestParseEtimeInvalid(t *testing.T) {
+	for _, etime := range []string{
+		"",
+		"N/A",
+		"12",
+		"1:2:3:4",
+		"1-",
+		"-01:00:00",
+		"1-2-03:04:05",
+		"1-05:06",
+		"xx-01:00:00",
+		"01:60",
+		"01:00:60",
+		"1:-5",
+		"+1:00",
+		"1::00",
+		"1:00:",
+	} {
+		if got, err := ParseEtime(etime); err == nil {
+			t.Errorf("ParseEtime(%q) = %v, want error", etime, got)
+		}
+	}
+}
+
+func TestUptimeFormat(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0m"},
+		{59 * time.Second, "0m"},
+		{45 * time.Minute, "45m"},
+		{3*time.Hour - time.Second, "179m"},
+		{3 * time.Hour, "3h"},
+		{27 * time.Hour, "27h"},
+		{200*time.Hour - time.Second, "199h"},
+		{200 * time.Hour, "8d"},
+		{123 * 24 * time.Hour, "123d"},
+		{-time.Minute, "0m"},
+	}
+	for _, tt := range tests {
+		if got := Uptime.Format(tt.d); got != tt.want {
+			t.Errorf("Uptime.Format(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestRecencyAgo(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "just now"},
+		{59 * time.Second, "just now"},
+		{time.Minute, "1m ago"},
+		{59 * time.Minute, "59m ago"},
+		{time.Hour, "1h ago"},
+		{23 * time.Hour, "23h ago"},
+		{24 * time.Hour, "1d ago"},
+		{150 * 24 * time.Hour, "150d ago"},
+	}
+	for _, tt := range tests {
+		if got := Recency.Ago(tt.d); got != tt.want {
+			t.Errorf("Recency.Ago(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestCustomThresholds(t *testing.T) {
+	compact := Thresholds{Minutes: time.Hour, Days: 48 * time.Hour}
+	if got := compact.Format(47 * time.Hour); got != "47h" {
+		t.Errorf("Format(47h) = %q, want 47h", got)
+	}
+	if got := compact.Format(48 * time.Hour); got != "2d" {
+		t.Errorf("Format(48h) = %q, want 2d", got)
+	}
+	if got := compact.Ago(0); got != "0m ago" {
+		t.Errorf("Ago(0) without JustNow = %q, want \"0m ago\"", got)
+	}
+}
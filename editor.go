@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// editorTemplate returns the editor command for a project directory, in order: the
+// closest directory under [editor.projects] in config.toml, PORTAGE_EDITOR, [editor]
+// command, EDITOR, then cursor
+func editorTemplate(path string) string {
+	best, bestLen := "", 0
+	for dir, command := range loadSettings().Editor.Projects {
+		dir = strings.TrimSuffix(expandHome(dir), "/")
+		if (path == dir || strings.HasPrefix(path, dir+"/")) && len(dir) > bestLen {
+			best, bestLen = command, len(dir)
+		}
+	}
+	if best != "" {
+		return best
+	}
+	if editor := os.Getenv("PORTAGE_EDITOR"); editor != "" {
+		return editor
+	}
+	if editor := loadSettings().Editor.Command; editor != "" {
+		return editor
+	}
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+	return "cursor" // Default to Cursor
+}
+
+// editorArgs expands an editor command template into arguments. {path} and {port} are
+// replaced; without {path} the path goes last, so a bare "code" or "emacsclient -n"
+// works. An argument that ends up empty, like {port} when there is no port, is dropped.
+func editorArgs(template, path string, port int) []string {
+	portText := ""
+	if port > 0 {
+		portText = strconv.Itoa(port)
+	}
+	var args []string
+	for _, arg := range strings.Fields(template) {
+		arg = strings.NewReplacer("{path}", path, "{port}", portText).Replace(arg)
+		if arg != "" {
+			args = append(args, arg)
+		}
+	}
+	if !strings.Contains(template, "{path}") {
+		args = append(args, path)
+	}
+	return args
+}
+
+// startEditor opens path in its editor without waiting for it, and returns the editor's
+// name for messages
+func startEditor(path string, port int) (string, error) {
+	args := editorArgs(editorTemplate(path), path, port)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = path // relative arguments like "." are the project
+	if err := cmd.Start(); err != nil {
+		return args[0], err
+	}
+	return args[0], nil
+}
+
+// validateEditorTemplate reports a template startEditor can't run
+func validateEditorTemplate(template string) error {
+	if strings.TrimSpace(template) == "" {
+		return fmt.Errorf("is empty")
+	}
+	for _, placeholder := range strings.SplitAfter(template, "{")[1:] {
+		name, _, _ := strings.Cut(placeholder, "}")
+		if name != "path" && name != "port" {
+			return fmt.Errorf("%q has unknown placeholder {%s} (use {path} or {port})", template, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// The kinds of HistoryEvent
+const (
+	EventPortStart      = "port_start"      // a port first seen listening in a directory
+	EventPortStop       = "port_stop"       // a listener that stopped
+	EventWorkspaceOpen  = "workspace_open"  // the editor opening a workspace
+	EventWorkspaceClose = "workspace_close" // and closing it
+	EventClaudeSession  = "claude_session"  // a Claude session, at its first prompt
+)
+
+// HistoryEvent is one thing that happened in a directory, whichever record it comes
+// from: port_events, port_closes and workspace_events in history.db, or Claude's
+// history.jsonl. The record of its type is kept alongside for what only it has.
+type HistoryEvent struct {
+	Type string
+	Path string
+	At   time.Time
+
+	// The history.db table and row the event was read from, for deleting it; none for
+	// Claude sessions
+	Table string
+	ID    int64
+
+	Port      *PortEvent            // EventPortStart
+	Close     *PortClose            // EventPortStop
+	Workspace *WorkspaceEvent       // EventWorkspaceOpen and EventWorkspaceClose
+	Session   *ClaudeHistorySession // EventClaudeSession
+}
+
+// eventQuery narrows queryEvents; zero values don't filter
+type eventQuery struct {
+	Types []string  // the Event* kinds wanted
+	Since time.Time // At on or after
+	Until time.Time // At before
+	Under string    // Path is this directory or inside it
+}
+
+// wants reports whether the query asks for events of a type
+func (q eventQuery) wants(eventType string) bool {
+	if len(q.Types) == 0 {
+		return true
+	}
+	for _, t := range q.Types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether an event is in the query's time span and directory
+func (q eventQuery) matches(event HistoryEvent) bool {
+	if !q.Since.IsZero() && event.At.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !event.At.Before(q.Until) {
+		return false
+	}
+	return q.Under == "" || underProject(event.Path, q.Under)
+}
+
+// underProject reports whether path is the project directory or inside it
+func underProject(path, project string) bool {
+	return path == project || strings.HasPrefix(path, strings.TrimSuffix(project, "/")+"/")
+}
+
+// queryEvents returns the history events a query asks for, oldest first. Only the
+// sources of the wanted types are read; when one can't be, the events of the others
+// come with its error. A missing Claude history has no sessions.
+func queryEvents(q eventQuery) ([]HistoryEvent, error) {
+	var events []HistoryEvent
+	var firstErr error
+	failed := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	add := func(event HistoryEvent) {
+		if q.matches(event) {
+			events = append(events, event)
+		}
+	}
+
+	if q.wants(EventPortStart) {
+		portEvents, err := loadPortEvents(q.Since)
+		failed(err)
+		for i := range portEvents {
+			event := &portEvents[i]
+			add(HistoryEvent{Type: EventPortStart, Path: event.Path, At: event.Started, Table: "port_events", ID: event.ID, Port: event})
+		}
+	}
+	if q.wants(EventPortStop) {
+		closes, err := loadPortCloses()
+		failed(err)
+		for i := range closes {
+			c := &closes[i]
+			add(HistoryEvent{Type: EventPortStop, Path: c.Path, At: c.Closed, Table: "port_closes", ID: c.ID, Close: c})
+		}
+	}
+	if q.wants(EventWorkspaceOpen) || q.wants(EventWorkspaceClose) {
+		workspaceEvents, err := loadWorkspaceEvents()
+		failed(err)
+		for i := range workspaceEvents {
+			event := &workspaceEvents[i]
+			eventType := EventWorkspaceOpen
+			if event.Event == "close" {
+				eventType = EventWorkspaceClose
+			}
+			if q.wants(eventType) {
+				add(HistoryEvent{Type: eventType, Path: event.Path, At: time.Unix(event.Timestamp, 0), Table: "workspace_events", ID: event.ID, Workspace: event})
+			}
+		}
+	}
+	if q.wants(EventClaudeSession) {
+		if entries, err := loadClaudeHistory(); err == nil {
+			for _, session := range groupHistoryBySessions(entries) {
+				add(HistoryEvent{Type: EventClaudeSession, Path: session.Project, At: time.UnixMilli(session.FirstTimestamp), Session: session})
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+	return events, firstErr
+}
+
+// lastWorkspaceEvents returns the latest open or close event of each workspace
+func lastWorkspaceEvents(events []HistoryEvent) map[string]*WorkspaceEvent {
+	last := make(map[string]*WorkspaceEvent)
+	for _, event := range events {
+		if event.Workspace == nil {
+			continue
+		}
+		if existing, ok := last[event.Path]; !ok || event.Workspace.Timestamp > existing.Timestamp {
+			last[event.Path] = event.Workspace
+		}
+	}
+	return last
+}
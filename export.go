@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultExportPath is a timestamped file in the current directory
+func defaultExportPath() string {
+	return fmt.Sprintf("portage-%s.json", time.Now().Format("20060102-150405"))
+}
+
+// exportPorts writes ports as JSON (the --json envelope) to a timestamped file in the
+// current directory and returns its path
+func exportPorts(ports []PortInfo) (string, error) {
+	path := defaultExportPath()
+	return path, writeExport(path, ports, nil)
+}
+
+// writeExport writes ports to path: as CSV with the list's visible columns when it ends
+// in .csv, otherwise as JSON
+func writeExport(path string, ports []PortInfo, config *Config) error {
+	if !strings.EqualFold(filepath.Ext(path), ".csv") {
+		data, err := json.MarshalIndent(newJSONEnvelope("ports", ports), "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, append(data, '\n'), 0644)
+	}
+
+	var columns []string
+	for _, column := range portColumns {
+		if config.showsColumn(column.name) {
+			columns = append(columns, column.name)
+		}
+	}
+	records := [][]string{{}}
+	for _, name := range columns {
+		records[0] = append(records[0], columnTitle(name))
+	}
+	for _, port := range ports {
+		var record []string
+		for _, name := range columns {
+			if name == "path" {
+				record = append(record, port.Path) // in full, unlike the list
+			} else {
+				record = append(record, portCell(port, name))
+			}
+		}
+		records = append(records, record)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(file)
+	writer.WriteAll(records)
+	if err := writer.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// exportPrompt is the open path input of E
+type exportPrompt struct {
+	text []rune
+}
+
+// updateExportPrompt handles keys while the export path input is open
+func (m model) updateExportPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		m.export = nil
+
+	case tea.KeyEnter:
+		path := expandHome(strings.TrimSpace(string(m.export.text)))
+		if path == "" {
+			return m, nil
+		}
+		m.export = nil
+		ports := m.getVisiblePorts()
+		if err := writeExport(path, ports, m.config); err != nil {
+			m.message = fmt.Sprintf("Failed to export: %v", err)
+		} else {
+			m.message = fmt.Sprintf("Exported %d port(s) to %s", len(ports), shortenPath(path))
+		}
+
+	case tea.KeyBackspace:
+		if len(m.export.text) > 0 {
+			m.export.text = m.export.text[:len(m.export.text)-1]
+		}
+
+	case tea.KeyCtrlU:
+		m.export.text = nil
+
+	case tea.KeyRunes, tea.KeySpace:
+		m.export.text = append(m.export.text, msg.Runes...)
+	}
+	return m, nil
+}
+
+// viewExportPrompt renders the export path input in place of the help line
+func (m model) viewExportPrompt() string {
+	promptStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Accent)
+	helpStyle := lipgloss.NewStyle().Foreground(ui.Muted)
+	return promptStyle.Render(fmt.Sprintf("Export %d listed port(s) to: ", len(m.getVisiblePorts()))) + string(m.export.text) + "█\n" +
+		helpStyle.Render("enter: save (.csv for CSV with the visible columns, JSON otherwise) • ctrl+u: clear • esc: cancel")
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// fixtureRecorder is set by record-fixtures so every external command output gets captured
+var fixtureRecorder *fixtureBundle
+
+// fixtureBundle is a directory of sanitized command outputs and redacted state files.
+// Layout: <dir>/commands/<key>.out (or .err), <dir>/home/..., <dir>/manifest.json
+type fixtureBundle struct {
+	dir       string
+	home      string
+	userRegex *regexp.Regexp
+	commands  []string
+	files     []string
+}
+
+type fixtureManifest struct {
+	RecordedAt string   `json:"recorded_at"`
+	GOOS       string   `json:"goos"`
+	GOARCH     string   `json:"goarch"`
+	Commands   []string `json:"commands"`
+	Files      []string `json:"files"`
+}
+
+// commandOutput runs an external command and returns its stdout.
+// With PORTAGE_FIXTURES set, output is replayed from a recorded fixture bundle instead;
+// `portage demo` answers with synthetic data.
+func commandOutput(name string, args ...string) ([]byte, error) {
+	if demoMode {
+		return demoCommandOutput(name, args)
+	}
+	if dir := os.Getenv("PORTAGE_FIXTURES"); dir != "" {
+		return replayCommand(dir, name, args)
+	}
+
+	output, err := exec.Command(name, args...).Output()
+	if fixtureRecorder != nil {
+		fixtureRecorder.recordCommand(name, args, output, err)
+	}
+	return output, err
+}
+
+var fixtureKeyRegex = regexp.MustCompile(`[^A-Za-z0-9.=-]+`)
+
+// fixtureKey turns a command line into a stable file name
+func fixtureKey(name string, args []string) string {
+	full := strings.Join(append([]string{name}, args...), " ")
+	key := strings.Trim(fixtureKeyRegex.ReplaceAllString(full, "_"), "_")
+	if len(key) > 80 {
+		// Long command lines (AppleScript, shell pipelines) get a hash suffix to stay unique
+		key = fmt.Sprintf("%s_%x", key[:64], sha1.Sum([]byte(full)))
+	}
+	return key
+}
+
+// replayCommand returns the recorded output for a command from a fixture bundle
+func replayCommand(dir, name string, args []string) ([]byte, error) {
+	base := filepath.Join(dir, "commands", fixtureKey(name, args))
+
+	if data, err := os.ReadFile(base + ".out"); err == nil {
+		return data, nil
+	}
+	if data, err := os.ReadFile(base + ".err"); err == nil {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(string(data)))
+	}
+	return nil, fmt.Errorf("no fixture recorded for %q", strings.Join(append([]string{name}, args...), " "))
+}
+
+func (b *fixtureBundle) recordCommand(name string, args []string, output []byte, runErr error) {
+	full := strings.Join(append([]string{name}, args...), " ")
+	base := filepath.Join(b.dir, "commands", fixtureKey(name, args))
+
+	var err error
+	if runErr != nil {
+		err = os.WriteFile(base+".err", []byte(b.sanitize(runErr.Error())+"\n"), 0644)
+	} else {
+		err = os.WriteFile(base+".out", []byte(b.sanitize(string(output))), 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error recording %q: %v\n", full, err)
+		return
+	}
+	b.commands = append(b.commands, b.sanitize(full))
+}
+
+// newFixtureBundle prepares a bundle in dir that sanitizes the current home directory and user name
+func newFixtureBundle(dir string) *fixtureBundle {
+	home, _ := os.UserHomeDir()
+	bundle := &fixtureBundle{dir: dir, home: home}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		bundle.userRegex = regexp.MustCompile(`\b` + regexp.QuoteMeta(u.Username) + `\b`)
+	}
+	return bundle
+}
+
+// sanitize replaces the home directory and user name with neutral placeholders
+func (b *fixtureBundle) sanitize(s string) string {
+	if b.home != "" {
+		s = strings.ReplaceAll(s, b.home, "/Users/portage")
+	}
+	if b.userRegex != nil {
+		s = b.userRegex.ReplaceAllString(s, "portage")
+	}
+	return s
+}
+
+// copyFile copies a state file from the home directory into the bundle, passing it through redact
+func (b *fixtureBundle) copyFile(path, relPath string, redact func(string) string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return // Missing state files are simply not part of the bundle
+	}
+	b.addFile(relPath, string(data), redact)
+}
+
+// addFile writes content to the bundle's home directory, sanitized and redacted like a
+// copied file
+func (b *fixtureBundle) addFile(relPath, content string, redact func(string) string) {
+	dest := filepath.Join(b.dir, "home", relPath)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", filepath.Dir(dest), err)
+		return
+	}
+
+	content = b.sanitize(content)
+	if redact != nil {
+		content = redact(content)
+	}
+	if err := os.WriteFile(dest, []byte(content), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", dest, err)
+		return
+	}
+	b.files = append(b.files, filepath.Join("home", relPath))
+}
+
+// redactClaudeHistory drops prompt text and pasted contents, keeping only structure and timing
+func redactClaudeHistory(content string) string {
+	var out strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		var entry ClaudeHistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entry.Display = fmt.Sprintf("[redacted %d chars]", len(entry.Display))
+		entry.PastedContents = map[string]interface{}{}
+
+		line, err := json.Marshal(entry)
+		if err != nil {
+			continue
+		}
+		out.Write(line)
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
+
+// runRecordFixtures implements `portage record-fixtures`
+func runRecordFixtures(args []string) {
+	fs := flag.NewFlagSet("record-fixtures", flag.ExitOnError)
+	outDir := fs.String("out", "testdata", "Directory to write the fixture bundle to")
+	fs.Parse(args)
+
+	bundle := newFixtureBundle(*outDir)
+	if err := os.MkdirAll(filepath.Join(bundle.dir, "commands"), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating fixture directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Run the same providers a normal invocation would, capturing their command output
+	fixtureRecorder = bundle
+	fmt.Printf("Recording command outputs")
+	if output, err := commandOutput("lsof", "-i", "-P", "-n"); err == nil {
+		enrichPorts(parseOutput(string(output)), func(PortInfo, time.Duration) { fmt.Printf(".") })
+	}
+	getOpenCursorWindows()
+	getClaudeSessions()
+	fixtureRecorder = nil
+	fmt.Printf(" done\n")
+
+	// Copy state files, redacting anything that could contain private text
+	// Portage's own files go where a replay home without XDG variables looks for them
+	// history.db goes in as the text logs it imports, since sanitizing would corrupt it
+	bundle.copyFile(getConfigPath(), filepath.Join(".config", "portage", "portage.json"), nil)
+	if ports, workspaces, err := exportLegacyLogs(); err == nil {
+		if ports != "" {
+			bundle.addFile(filepath.Join(".local", "state", "portage", "ports.log"), ports, nil)
+		}
+		if workspaces != "" {
+			bundle.addFile(filepath.Join(".local", "state", "portage", "workspaces.log"), workspaces, nil)
+		}
+	}
+	bundle.copyFile(filepath.Join(bundle.home, ".claude", "history.jsonl"), filepath.Join(".claude", "history.jsonl"), redactClaudeHistory)
+
+	manifest := fixtureManifest{
+		RecordedAt: time.Now().Format(time.RFC3339),
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		Commands:   bundle.commands,
+		Files:      bundle.files,
+	}
+	data, _ := json.MarshalIndent(manifest, "", "  ")
+	if err := os.WriteFile(filepath.Join(bundle.dir, "manifest.json"), data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing manifest: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\n%s%sRecorded %d commands and %d files to %s%s\n", ColorBold, ColorCyan, len(bundle.commands), len(bundle.files), bundle.dir, ColorReset)
+	fmt.Printf("Replay with: HOME=%s PORTAGE_FIXTURES=%s portage\n\n", filepath.Join(bundle.dir, "home"), bundle.dir)
+	fmt.Println("Please review the bundle before sharing it; command lines and paths are only partially anonymized.")
+}
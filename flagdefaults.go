@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+)
+
+// outputFlags pick what portage prints, or do something else entirely; with one of them
+// on the command line, [defaults] json and interactive don't apply
+var outputFlags = []string{
+	"i", "json", "yaml", "jq", "format", "q", "metrics", "html", "watch", "audit", "no-enrich",
+	"unified", "cursor", "claude", "claude-history", "cursor-history", "history",
+	"history-search", "since", "until",
+	"kill", "log-close", "log-open",
+}
+
+// applyDefaultOutput turns on [defaults] json or interactive after the flags are parsed,
+// when none of them chose the output
+func applyDefaultOutput() {
+	explicit := false
+	flag.Visit(func(f *flag.Flag) {
+		explicit = explicit || slices.Contains(outputFlags, f.Name)
+	})
+	if explicit {
+		return
+	}
+	defaults := loadSettings().Defaults
+	interactive = defaults.Interactive
+	jsonOutput = defaults.JSON
+}
+
+// validate reports [defaults] portage can't start with
+func (d DefaultSettings) validate() []string {
+	var problems []string
+	if !slices.Contains(interactiveSortOrders, d.Sort) {
+		problems = append(problems, fmt.Sprintf("defaults.sort: unknown order %q (uptime, port, command, path or cpu)", d.Sort))
+	}
+	if d.Limit < 1 {
+		problems = append(problems, fmt.Sprintf("defaults.limit: %d must be at least 1", d.Limit))
+	}
+	if d.JSON && d.Interactive {
+		problems = append(problems, "defaults: json and interactive can't both be true")
+	}
+	return problems
+}
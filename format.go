@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// formatTemplate is the parsed --format template, nil when it isn't given
+var formatTemplate *template.Template
+
+// formatFuncs are available in --format templates besides the text/template builtins
+var formatFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+	"join":      strings.Join,
+	"shortpath": shortenPath,
+}
+
+// parseFormat parses a --format template; like docker's, "\t" and "\n" written out in
+// the shell become a tab and a newline
+func parseFormat(format string) (*template.Template, error) {
+	format = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+	return template.New("format").Funcs(formatFuncs).Parse(format)
+}
+
+// writeFormatted executes the --format template against v: once per element when v is
+// a list (a line each, as with `docker ps --format`), otherwise once. Templates see the
+// Go structs, so fields go by their Go names: {{.WorkingDir}}, not working_dir.
+func writeFormatted(v interface{}) {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Slice {
+		executeFormat(v)
+		return
+	}
+	for i := 0; i < value.Len(); i++ {
+		executeFormat(value.Index(i).Interface())
+	}
+}
+
+func executeFormat(v interface{}) {
+	var b strings.Builder
+	if err := formatTemplate.Execute(&b, v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing --format: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(strings.TrimSuffix(b.String(), "\n"))
+}
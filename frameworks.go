@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// frameworkPatterns map command line fragments to the dev server they start, most
+// specific first (storybook runs through webpack, next through node, ...)
+var frameworkPatterns = []struct {
+	pattern string
+	name    string
+}{
+	{"storybook", "Storybook"},
+	{"next dev", "Next.js"},
+	{"next-server", "Next.js"},
+	{"next start", "Next.js"},
+	{"nuxt", "Nuxt"},
+	{"astro", "Astro"},
+	{"remix", "Remix"},
+	{"gatsby", "Gatsby"},
+	{"vite", "Vite"},
+	{"webpack", "webpack"},
+	{"react-scripts", "Create React App"},
+	{"expo", "Expo"},
+	{"metro", "Metro"},
+	{"ng serve", "Angular"},
+	{"rails server", "Rails"},
+	{"rails s", "Rails"},
+	{"puma", "Rails"},
+	{"manage.py runserver", "Django"},
+	{"uvicorn", "Uvicorn"},
+	{"gunicorn", "Gunicorn"},
+	{"flask", "Flask"},
+	{"jekyll", "Jekyll"},
+	{"hugo", "Hugo"},
+	{"http.server", "static files"},
+	{"docker-proxy", "Docker"},
+}
+
+// detectFramework names the dev server a command line starts, or "" if unknown
+func detectFramework(commandLine string) string {
+	lower := strings.ToLower(commandLine)
+	for _, fw := range frameworkPatterns {
+		if strings.Contains(lower, fw.pattern) {
+			return fw.name
+		}
+	}
+	return ""
+}
+
+// groupOverlappingProjects moves listeners of projects served by more than one process
+// next to each other (at the position of the project's first row). It returns the
+// reordered ports and, for each project with overlapping servers, a warning line.
+// groupStart marks the indexes where such a group begins and groupEnd where one ends.
+func groupOverlappingProjects(ports []PortInfo) (ordered []PortInfo, groupStart, groupEnd map[int]bool, warnings []string) {
+	pids := make(map[string]map[string]bool)
+	for _, port := range ports {
+		root := projectRoot(port.Path)
+		if root == "" || port.Daemon != "" {
+			continue // A tooling daemon next to a dev server is expected
+		}
+		if pids[root] == nil {
+			pids[root] = make(map[string]bool)
+		}
+		pids[root][port.PID] = true
+	}
+
+	groupStart = make(map[int]bool)
+	groupEnd = make(map[int]bool)
+	emitted := make(map[string]bool)
+	for _, port := range ports {
+		root := projectRoot(port.Path)
+		if root == "" || len(pids[root]) < 2 || port.Daemon != "" {
+			ordered = append(ordered, port)
+			continue
+		}
+		if emitted[root] {
+			continue
+		}
+		emitted[root] = true
+
+		groupStart[len(ordered)] = true
+		var servers []string
+		for _, member := range ports {
+			if projectRoot(member.Path) != root || member.Daemon != "" {
+				continue
+			}
+			ordered = append(ordered, member)
+			role := member.Role
+			if role == "" {
+				role = member.Command
+			}
+			servers = append(servers, fmt.Sprintf("%s (:%d)", role, member.Port))
+		}
+		groupEnd[len(ordered)-1] = true
+		sort.Strings(servers)
+		warnings = append(warnings, fmt.Sprintf("%s is served by %d processes: %s", shortenPath(root), len(pids[root]), strings.Join(servers, ", ")))
+	}
+	return ordered, groupStart, groupEnd, warnings
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// findRepoRoot walks up from dir until it finds a directory containing .git
+func findRepoRoot(dir string) string {
+	for dir != "" && dir != "/" && dir != "." {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		dir = filepath.Dir(dir)
+	}
+	return ""
+}
+
+// gitBranch returns the checked out branch of a repository by reading .git/HEAD
+// directly (no git subprocess). Detached heads return the short commit hash.
+func gitBranch(root string) string {
+	gitDir := filepath.Join(root, ".git")
+
+	// Worktrees and submodules have a .git file pointing at the real git dir
+	if data, err := os.ReadFile(gitDir); err == nil {
+		target := strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(root, target)
+		}
+		gitDir = target
+	}
+
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+
+	head := strings.TrimSpace(string(data))
+	if strings.HasPrefix(head, "ref: ") {
+		return strings.TrimPrefix(strings.TrimPrefix(head, "ref: "), "refs/heads/")
+	}
+	if len(head) > 7 {
+		return head[:7]
+	}
+	return head
+}
+
+// projectRoot returns the directory a port belongs to: its repository root if it
+// lives inside a git checkout, otherwise the working directory itself
+func projectRoot(path string) string {
+	if path == "N/A" || path == "/" || path == "" {
+		return ""
+	}
+	if root := findRepoRoot(path); root != "" {
+		return root
+	}
+	return path
+}
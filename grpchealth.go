@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// grpcHealthStatus names the grpc.health.v1.HealthCheckResponse.ServingStatus values
+var grpcHealthStatus = map[byte]string{
+	0: "unknown",
+	1: "healthy",
+	2: "unhealthy",
+	3: "unknown service",
+}
+
+// checkGRPCHealth calls grpc.health.v1.Health/Check for the overall server ("" service)
+// over plaintext HTTP/2. Returns "" if the port doesn't speak gRPC at all.
+func checkGRPCHealth(port PortInfo, timeout time.Duration) string {
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// A length-prefixed message: uncompressed flag, 4-byte length, then the empty HealthCheckRequest
+	url := fmt.Sprintf("http://%s:%d/grpc.health.v1.Health/Check", portHost(port), port.Port)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader([]byte{0, 0, 0, 0, 0}))
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/grpc") {
+		return ""
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "unhealthy"
+	}
+
+	// grpc-status arrives in the trailers, or in the headers for trailers-only responses
+	status := resp.Trailer.Get("Grpc-Status")
+	if status == "" {
+		status = resp.Header.Get("Grpc-Status")
+	}
+	switch status {
+	case "0":
+	case "12":
+		return "no health service" // UNIMPLEMENTED: gRPC server without grpc.health.v1
+	default:
+		return "unhealthy"
+	}
+
+	// HealthCheckResponse is a single enum field: tag 0x08 followed by the status
+	if len(body) >= 7 && body[5] == 0x08 {
+		if name, ok := grpcHealthStatus[body[6]]; ok {
+			return name
+		}
+	}
+	if len(body) == 5 {
+		return "unknown" // Empty message: status left at its default
+	}
+	return "unhealthy"
+}
+
+// resolveGRPCHealth probes every port in parallel and fills in the Health field
+func resolveGRPCHealth(ports []PortInfo, timeout time.Duration) {
+	var wg sync.WaitGroup
+	for i := range ports {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ports[i].Health = checkGRPCHealth(ports[i], timeout)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// formatHealth colors a health status for the table; non-gRPC ports show "-"
+func formatHealth(health string) string {
+	switch health {
+	case "":
+		return "-"
+	case "healthy":
+		return ColorGreen + health + ColorReset
+	case "unhealthy":
+		return ColorRed + health + ColorReset
+	default:
+		return ColorYellow + health + ColorReset
+	}
+}
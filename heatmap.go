@@ -0,0 +1,152 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// activitySample is a single moment when a project was known to be active
+type activitySample struct {
+	Time    time.Time
+	Project string
+}
+
+// collectActivitySamples gathers activity timestamps from every local history source:
+// port discoveries and workspace open/close events (history.db), Claude prompts and
+// recorded editor activity (--record-activity)
+func collectActivitySamples(since time.Time) []activitySample {
+	var samples []activitySample
+
+	if events, err := loadPortEvents(since); err == nil {
+		for _, event := range events {
+			samples = append(samples, activitySample{Time: event.Started, Project: projectRoot(event.Path)})
+		}
+	}
+
+	if events, err := loadWorkspaceEvents(); err == nil {
+		for _, event := range events {
+			ts := time.Unix(event.Timestamp, 0)
+			if ts.Before(since) {
+				continue
+			}
+			samples = append(samples, activitySample{Time: ts, Project: event.Path})
+		}
+	}
+
+	if entries, err := loadClaudeHistory(); err == nil {
+		for _, entry := range entries {
+			ts := time.UnixMilli(entry.Timestamp)
+			if entry.Project == "" || ts.Before(since) {
+				continue
+			}
+			samples = append(samples, activitySample{Time: ts, Project: entry.Project})
+		}
+	}
+
+	samples = append(samples, loadRecordedActivity(since)...)
+
+	return samples
+}
+
+// heatmapLevels are the cell colors from "no activity" to "busiest day" (256-color palette)
+var heatmapLevels = []string{"\033[38;5;238m", "\033[38;5;22m", "\033[38;5;28m", "\033[38;5;34m", "\033[38;5;46m"}
+
+// runHeatmap implements `portage heatmap`
+func runHeatmap(args []string) {
+	fs := flag.NewFlagSet("heatmap", flag.ExitOnError)
+	months := fs.Int("months", 3, "Number of months to show")
+	metric := fs.String("metric", "projects", "What to count per day: 'projects' (distinct active projects) or 'hours' (hours with any activity)")
+	fs.Parse(args)
+
+	if *metric != "projects" && *metric != "hours" {
+		fmt.Fprintf(os.Stderr, "Error: --metric must be 'projects' or 'hours'\n")
+		os.Exit(1)
+	}
+	if *months < 1 {
+		*months = 1
+	}
+
+	// Start on the Monday on or before the first day of the range so columns are whole weeks
+	today := time.Now()
+	end := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local)
+	start := end.AddDate(0, -*months, 1)
+	start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+
+	// Count distinct projects or distinct hours per day
+	buckets := make(map[string]map[string]bool)
+	for _, sample := range collectActivitySamples(start) {
+		day := sample.Time.Format("2006-01-02")
+		if buckets[day] == nil {
+			buckets[day] = make(map[string]bool)
+		}
+		if *metric == "hours" {
+			buckets[day][sample.Time.Format("15")] = true
+		} else {
+			buckets[day][sample.Project] = true
+		}
+	}
+
+	counts := make(map[string]int)
+	maxCount, activeDays := 0, 0
+	for day, set := range buckets {
+		counts[day] = len(set)
+		activeDays++
+		if len(set) > maxCount {
+			maxCount = len(set)
+		}
+	}
+
+	weeks := int(end.Sub(start).Hours()/24)/7 + 1
+
+	fmt.Printf("\n%s%sPORTAGE - Activity (%s per day, last %d months)%s\n\n", ColorBold, ColorCyan, *metric, *months, ColorReset)
+
+	// Month labels above the first week of each month
+	labels := []byte(strings.Repeat(" ", 4+weeks*2+3))
+	lastMonth, labelEnd := time.Month(0), 0
+	for w := 0; w < weeks; w++ {
+		weekStart := start.AddDate(0, 0, w*7)
+		pos := 4 + w*2
+		if weekStart.Month() != lastMonth && pos >= labelEnd {
+			copy(labels[pos:], weekStart.Format("Jan"))
+			labelEnd = pos + 4
+		}
+		lastMonth = weekStart.Month()
+	}
+	fmt.Println(strings.TrimRight(string(labels), " "))
+
+	dayNames := []string{"Mon", "", "Wed", "", "Fri", "", "Sun"}
+	for dow := 0; dow < 7; dow++ {
+		fmt.Printf("%-4s", dayNames[dow])
+		for w := 0; w < weeks; w++ {
+			day := start.AddDate(0, 0, w*7+dow)
+			if day.After(end) {
+				break
+			}
+			fmt.Printf("%s■%s ", heatmapLevels[heatmapLevel(counts[day.Format("2006-01-02")], maxCount)], ColorReset)
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("\n    Less ")
+	for _, color := range heatmapLevels {
+		fmt.Printf("%s■%s ", color, ColorReset)
+	}
+	fmt.Printf("More\n")
+
+	fmt.Printf("\n%s%s%d active days, busiest day: %d %s%s\n\n", ColorBold, ColorCyan, activeDays, maxCount, *metric, ColorReset)
+}
+
+// heatmapLevel buckets a count into one of the heatmap colors relative to the busiest day
+func heatmapLevel(count, maxCount int) int {
+	if count == 0 || maxCount == 0 {
+		return 0
+	}
+	level := 1 + (count-1)*(len(heatmapLevels)-1)/maxCount
+	if level >= len(heatmapLevels) {
+		level = len(heatmapLevels) - 1
+	}
+	return level
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyBinding is one line of the ? overlay
+type keyBinding struct {
+	keys string
+	desc string
+}
+
+// helpSections is the full keybinding reference shown with ?
+var helpSections = []struct {
+	title    string
+	bindings []keyBinding
+}{
+	{"Navigation", []keyBinding{
+		{"↑/k, ↓/j", "move the cursor"},
+		{": or g", "go to a port by number"},
+		{"space", "mark the selected port"},
+		{"esc", "clear marks"},
+		{"s", "cycle the sort order (uptime, port, command, path, CPU)"},
+	}},
+	{"Actions", []keyBinding{
+		{"enter/o", "open in the browser (or the configured open action)"},
+		{"f", "reveal in Finder"},
+		{"e", "open the project in the editor"},
+		{"t", "open a terminal in the project directory"},
+		{"c / C / y", "copy URL / path / PID"},
+		{"l", "follow the server's log"},
+		{"i", "inspect the process: raw ps and lsof output, scrollable"},
+		{"K", "kill, picking the signal"},
+		{"ctrl+k", "kill every listener of the selected project"},
+		{"r", "restart in the same directory"},
+		{"U", "undo the last kill: start the process again"},
+		{"p", "pin or unpin the project (or port) to the top"},
+		{"P", "publish a container port on localhost"},
+		{"h", "hide the selected or marked ports (by directory, command or PID)"},
+		{"n", "write a note on the project (enter saves, empty removes)"},
+		{"H", "snooze the selected or marked ports (1h, 8h, until tomorrow)"},
+		{"u", "unhide all, including snoozes"},
+		{"w", "save the selected or marked ports as JSON"},
+		{"E", "export the listed rows to a JSON or CSV file"},
+		{"X", "kill all orphans"},
+	}},
+	{"Filters", []keyBinding{
+		{"a", "show all ports, not just dev ranges"},
+		{"d", "show tooling daemons"},
+		{"N / Y / D", "show only node, python or docker listeners (again: show all)"},
+		{"F", "cycle the command filter: node, python, docker, ruby, java, all"},
+		{"O", "show only orphans"},
+		{"v", "choose the columns, e.g. add CPU% or BRANCH (saved to config)"},
+	}},
+	{"Tabs", []keyBinding{
+		{"1-5, tab/shift+tab", "switch between Ports, Workspaces, Claude, History and Log"},
+		{"/", "search the view (esc clears)"},
+		{"enter/e", "open a workspace in the editor"},
+		{"f / t / C", "reveal / open a terminal in / copy a workspace path"},
+		{"x", "launch a workspace that isn't running (launch commands from config)"},
+		{"D / X", "Log: delete the entry / prune entries of deleted directories"},
+		{"J / K", "Claude: jump to the session's ports / kill the session"},
+		{"o / m / K", "Workspaces: open in Cursor / mark closed (like --log-close) / kill its ports"},
+	}},
+	{"General", []keyBinding{
+		{"?", "show this help"},
+		{"ctrl+z", "suspend"},
+		{"q", "quit"},
+	}},
+}
+
+// updateHelp closes the overlay on any key; ctrl+c still quits
+func (m model) updateHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.showHelp = false
+	if msg.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// viewHelp renders the keybinding reference, one column of keys per section
+func (m model) viewHelp() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Accent).MarginBottom(1)
+	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Accent)
+	keyStyle := lipgloss.NewStyle().Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(ui.Muted).MarginTop(1)
+
+	keyWidth := 0
+	for _, section := range helpSections {
+		for _, b := range section.bindings {
+			keyWidth = max(keyWidth, lipgloss.Width(b.keys))
+		}
+	}
+
+	var s strings.Builder
+	s.WriteString(titleStyle.Render("PORTAGE - Keys"))
+	s.WriteString("\n")
+	for i, section := range helpSections {
+		if i > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(sectionStyle.Render(section.title))
+		s.WriteString("\n")
+		for _, b := range section.bindings {
+			keys := b.keys + strings.Repeat(" ", keyWidth-lipgloss.Width(b.keys))
+			s.WriteString(fmt.Sprintf("  %s  %s\n", keyStyle.Render(keys), b.desc))
+		}
+	}
+	s.WriteString(helpStyle.Render("Press any key to close"))
+	return s.String()
+}
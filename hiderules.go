@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// HideRule hides every listener matching all of its fields, so unlike hidden_ports
+// (keyed on port and PID) it survives restarts
+type HideRule struct {
+	Path    string `json:"path,omitempty"`    // project directory, subdirectories included
+	Command string `json:"command,omitempty"` // process name, e.g. "node"
+	Port    int    `json:"port,omitempty"`    // port number, whatever listens on it
+}
+
+func (r HideRule) matches(port PortInfo) bool {
+	if r.Path == "" && r.Command == "" && r.Port == 0 {
+		return false
+	}
+	if r.Port != 0 && port.Port != r.Port {
+		return false
+	}
+	if r.Path != "" {
+		dir := strings.TrimSuffix(r.Path, "/")
+		if port.Path != dir && !strings.HasPrefix(port.Path, dir+"/") {
+			return false
+		}
+	}
+	return r.Command == "" || port.Command == r.Command
+}
+
+// isHidden reports whether a listener is hidden by its port-PID key, a hide rule (of the
+// active profile, if there is one) or a snooze
+func (c *Config) isHidden(port PortInfo) bool {
+	if c.HiddenPorts[fmt.Sprintf("%d-%s", port.Port, port.PID)] || c.isSnoozed(port) {
+		return true
+	}
+	for _, rule := range *c.hideRules() {
+		if rule.matches(port) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) addHideRule(rule HideRule) {
+	rules := c.hideRules()
+	for _, existing := range *rules {
+		if existing == rule {
+			return
+		}
+	}
+	*rules = append(*rules, rule)
+}
+
+// What h hides, picked from a small menu
+const (
+	hideServer  = iota // command in this directory, across restarts
+	hidePath           // everything in this directory
+	hideCommand        // every process with this name
+	hidePort           // whatever listens on this port
+	hideProcess        // this port and PID only
+)
+
+// hideRuleFor returns the rule hiding port for a menu choice; false means the port-PID
+// key, which only the last choice asks for. Without a known path the directory choices
+// hide the port instead, which survives restarts too.
+func hideRuleFor(choice int, port PortInfo) (HideRule, bool) {
+	hasPath := port.Path != "" && port.Path != "N/A" && port.Path != "/"
+	switch choice {
+	case hideServer:
+		if hasPath {
+			return HideRule{Path: port.Path, Command: port.Command}, true
+		}
+		return HideRule{Command: port.Command, Port: port.Port}, true
+	case hidePath:
+		if hasPath {
+			return HideRule{Path: port.Path}, true
+		}
+		return HideRule{Port: port.Port}, true
+	case hideCommand:
+		if port.Command != "" {
+			return HideRule{Command: port.Command}, true
+		}
+	case hidePort:
+		return HideRule{Port: port.Port}, true
+	}
+	return HideRule{}, false
+}
+
+// hideChoiceLabels describes the menu entries for the ports h applies to
+func hideChoiceLabels(targets []PortInfo) []string {
+	if len(targets) == 1 {
+		port := targets[0]
+		return []string{
+			fmt.Sprintf("%s in %s, also after restarts", port.Command, shortenPath(port.Path)),
+			fmt.Sprintf("everything in %s", shortenPath(port.Path)),
+			fmt.Sprintf("every %s process", port.Command),
+			fmt.Sprintf("port %d, whatever listens on it", port.Port),
+			fmt.Sprintf("only PID %s on port %d, until it restarts", port.PID, port.Port),
+		}
+	}
+	return []string{
+		"these servers, also after restarts",
+		"everything in their directories",
+		"every process with their commands",
+		"these ports, whatever listens on them",
+		"only these PIDs, until they restart",
+	}
+}
+
+// hidePicker is the open hide menu and the ports it applies to
+type hidePicker struct {
+	targets []PortInfo
+	cursor  int
+}
+
+// updateHidePicker handles keys while the hide menu is open
+func (m model) updateHidePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.hidePick = nil
+	case "up", "k":
+		if m.hidePick.cursor > 0 {
+			m.hidePick.cursor--
+		}
+	case "down", "j":
+		if m.hidePick.cursor < hideProcess {
+			m.hidePick.cursor++
+		}
+	case "1", "2", "3", "4", "5":
+		m.hidePick.cursor = int(msg.String()[0] - '1')
+		return m.pickHideChoice()
+	case "enter", "h":
+		return m.pickHideChoice()
+	}
+	return m, nil
+}
+
+// pickHideChoice closes the menu and hides the ports; hiding several asks first
+func (m model) pickHideChoice() (tea.Model, tea.Cmd) {
+	choice := m.hidePick.cursor
+	targets := m.hidePick.targets
+	m.hidePick = nil
+	if len(targets) > 1 {
+		question := fmt.Sprintf("Hide %s (%d marked ports)?", hideChoiceLabels(targets)[choice], len(targets))
+		return m.confirmThen(question, func(m model) (tea.Model, tea.Cmd) {
+			return m.hidePorts(targets, choice), nil
+		})
+	}
+	return m.hidePorts(targets, choice), nil
+}
+
+// viewHidePicker renders the hide menu in place of the help line
+func (m model) viewHidePicker() string {
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Accent)
+	selectedStyle := ui.selectedStyle()
+	helpStyle := lipgloss.NewStyle().Foreground(ui.Muted)
+
+	var s strings.Builder
+	s.WriteString(headerStyle.Render("Hide"))
+	s.WriteString("\n")
+	for i, label := range hideChoiceLabels(m.hidePick.targets) {
+		line := fmt.Sprintf("  %d  %s", i+1, label)
+		if i == m.hidePick.cursor {
+			line = selectedStyle.Render(line)
+		}
+		s.WriteString(line)
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render("↑/↓ or 1-5: choose • enter: hide • esc: cancel"))
+	return s.String()
+}
+
+// migrateHiddenPorts turns hidden_ports entries of running listeners, saved before hide
+// rules existed, into rules for their command and directory, once. Entries whose process
+// has exited are dropped every time since their PID key can't match again.
+func migrateHiddenPorts(ports []PortInfo, config *Config) {
+	if len(config.HiddenPorts) == 0 || demoMode {
+		return
+	}
+	running := make(map[string]PortInfo)
+	for _, port := range ports {
+		running[fmt.Sprintf("%d-%s", port.Port, port.PID)] = port
+	}
+
+	changed := !config.HideRulesMigrated
+	for key := range config.HiddenPorts {
+		port, ok := running[key]
+		if !ok {
+			_, pid, _ := strings.Cut(key, "-")
+			if !processExists(pid) {
+				delete(config.HiddenPorts, key)
+				changed = true
+			}
+			continue
+		}
+		if config.HideRulesMigrated {
+			continue
+		}
+		// Into the top-level rules even with --profile: they were made without one. Listeners
+		// without a known path get a rule for their command and port.
+		rule, _ := hideRuleFor(hideServer, port)
+		if !slices.Contains(config.HideRules, rule) {
+			config.HideRules = append(config.HideRules, rule)
+		}
+		delete(config.HiddenPorts, key)
+	}
+	config.HideRulesMigrated = true
+	if changed {
+		config.save() // in read-only mode the migration only lasts for this run
+	}
+}
+
+// processExists reports whether pid is running, including other users' processes
+func processExists(pid string) bool {
+	n, err := strconv.Atoi(pid)
+	if err != nil || n <= 0 {
+		return false
+	}
+	err = syscall.Kill(n, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
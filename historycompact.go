@@ -0,0 +1,174 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// compactResult says what `portage history compact` changed, or would change
+type compactResult struct {
+	PortEvents      int // duplicate port events removed
+	PortCloses      int
+	WorkspaceEvents int
+	MovedPaths      int    // port events kept under their resolved path
+	Backup          string // the copy taken first, "" for --dry-run
+}
+
+// historyPathForm resolves a recorded path the way --path is, so a directory reached
+// through a symlink (/tmp and /private/tmp on macOS) or with a trailing slash counts
+// once. Paths that no longer exist only get cleaned.
+func historyPathForm(path string) string {
+	if path == "N/A" || path == "" {
+		return path
+	}
+	path = filepath.Clean(path)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// compactHistory dedupes history.db in one transaction: a port counts once per
+// directory, at its first start (what recordScan keeps), and identical close and
+// workspace events once. The tables are then renumbered in time order and the file
+// vacuumed. A copy of the database is taken next to it first; dryRun only counts.
+func compactHistory(dryRun bool, now time.Time) (compactResult, error) {
+	var result compactResult
+	if readOnly && !dryRun {
+		return result, errReadOnly
+	}
+	db, err := openHistory()
+	if err != nil {
+		return result, err
+	}
+	if !dryRun {
+		result.Backup = getHistoryPath() + "." + now.Format("20060102-150405") + ".bak"
+		if _, err := db.Exec(`VACUUM INTO ?`, result.Backup); err != nil {
+			return result, fmt.Errorf("backing up to %s: %w", shortenPath(result.Backup), err)
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	duplicates, moved, err := compactPortEvents(tx)
+	if err != nil {
+		return result, err
+	}
+	result.PortEvents, result.MovedPaths = len(duplicates), len(moved)
+	for _, dedupe := range []struct {
+		table   string
+		columns string
+		removed *int
+	}{
+		{"port_closes", "closed_at, started_at, port, pid, command, path", &result.PortCloses},
+		{"workspace_events", "at, event, path", &result.WorkspaceEvents},
+	} {
+		where := ` WHERE id NOT IN (SELECT MIN(id) FROM ` + dedupe.table + ` GROUP BY ` + dedupe.columns + `)`
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM ` + dedupe.table + where).Scan(dedupe.removed); err != nil {
+			return result, err
+		}
+		if !dryRun {
+			if _, err := tx.Exec(`DELETE FROM ` + dedupe.table + where); err != nil {
+				return result, err
+			}
+		}
+	}
+	if dryRun {
+		return result, nil // nothing was written, the database may be read-only
+	}
+
+	for _, id := range duplicates {
+		if _, err := tx.Exec(`DELETE FROM port_events WHERE id = ?`, id); err != nil {
+			return result, err
+		}
+	}
+	for id, path := range moved {
+		if _, err := tx.Exec(`UPDATE port_events SET path = ? WHERE id = ?`, path, id); err != nil {
+			return result, err
+		}
+	}
+
+	for _, renumber := range []struct{ table, columns, order string }{
+		{"port_events", "started_at, port, pid, command, path, command_line, user", "started_at, id"},
+		{"port_closes", "closed_at, started_at, port, pid, command, path", "closed_at, id"},
+		{"workspace_events", "at, event, path", "at, id"},
+	} {
+		for _, query := range []string{
+			`CREATE TEMP TABLE compacted AS SELECT ` + renumber.columns + ` FROM ` + renumber.table + ` ORDER BY ` + renumber.order,
+			`DELETE FROM ` + renumber.table,
+			`INSERT INTO ` + renumber.table + ` (` + renumber.columns + `) SELECT ` + renumber.columns + ` FROM compacted ORDER BY rowid`,
+			`DROP TABLE compacted`,
+		} {
+			if _, err := tx.Exec(query); err != nil {
+				return result, err
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+	_, err = db.Exec(`VACUUM`)
+	return result, err
+}
+
+// compactPortEvents finds the port events to remove, all but the first start of each
+// port in each directory, and the paths of the kept ones that resolve differently
+func compactPortEvents(tx *sql.Tx) (duplicates []int64, moved map[int64]string, err error) {
+	rows, err := tx.Query(`SELECT id, port, path FROM port_events ORDER BY started_at, id`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	moved = make(map[int64]string)
+	kept := make(map[string]bool)
+	for rows.Next() {
+		var id int64
+		var port int
+		var path string
+		if err := rows.Scan(&id, &port, &path); err != nil {
+			return nil, nil, err
+		}
+		resolved := historyPathForm(path)
+		key := fmt.Sprintf("%d\t%s", port, resolved)
+		if kept[key] {
+			duplicates = append(duplicates, id)
+			continue
+		}
+		kept[key] = true
+		if resolved != path {
+			moved[id] = resolved
+		}
+	}
+	return duplicates, moved, rows.Err()
+}
+
+// runHistoryCompact implements `portage history compact`
+func runHistoryCompact(args []string) {
+	fs := flag.NewFlagSet("history compact", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "Show what would be removed without changing anything")
+	fs.Parse(args)
+
+	result, err := compactHistory(*dryRun, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error compacting %s: %v\n", shortenPath(getHistoryPath()), err)
+		os.Exit(1)
+	}
+	removed := fmt.Sprintf("%d duplicate port events, %d close events and %d workspace events",
+		result.PortEvents, result.PortCloses, result.WorkspaceEvents)
+	if *dryRun {
+		fmt.Printf("%s%sWould remove %s%s\n", ColorBold, ColorCyan, removed, ColorReset)
+		fmt.Printf("%d port events would move to their resolved path\n", result.MovedPaths)
+		return
+	}
+	fmt.Printf("%s%sCompacted %s: removed %s%s\n", ColorBold, ColorCyan, shortenPath(getHistoryPath()), removed, ColorReset)
+	fmt.Printf("%d port events moved to their resolved path\n", result.MovedPaths)
+	fmt.Printf("The copy from before is %s\n", shortenPath(result.Backup))
+}
@@ -0,0 +1,684 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// history.db holds everything portage records about the past: ports as they're first
+// seen in a directory, listeners as they stop, workspace open and close events, and
+// snapshots of the scans whose listeners differ from the scan before. Older versions kept the first two in
+// text logs, which are imported once.
+const historySchema = `
+CREATE TABLE IF NOT EXISTS port_events (
+	id           INTEGER PRIMARY KEY,
+	started_at   INTEGER NOT NULL, -- Unix time the process started, from its uptime
+	port         INTEGER NOT NULL,
+	pid          TEXT NOT NULL,
+	command      TEXT NOT NULL,
+	path         TEXT NOT NULL,
+	command_line TEXT NOT NULL DEFAULT '', -- from ps; '' in events from before version 4
+	user         TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS port_closes (
+	id         INTEGER PRIMARY KEY,
+	closed_at  INTEGER NOT NULL, -- the first scan it was missing from
+	started_at INTEGER NOT NULL,
+	port       INTEGER NOT NULL,
+	pid        TEXT NOT NULL,
+	command    TEXT NOT NULL,
+	path       TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS workspace_events (
+	id    INTEGER PRIMARY KEY,
+	at    INTEGER NOT NULL,
+	event TEXT NOT NULL, -- "open" or "close"
+	path  TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS scans (
+	id INTEGER PRIMARY KEY,
+	at INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS scan_ports (
+	scan_id    INTEGER NOT NULL,
+	port       INTEGER NOT NULL,
+	pid        TEXT NOT NULL,
+	command    TEXT NOT NULL,
+	path       TEXT NOT NULL,
+	started_at INTEGER NOT NULL DEFAULT 0 -- 0 in snapshots from version 1
+);
+CREATE INDEX IF NOT EXISTS port_events_port_path ON port_events (port, path);
+CREATE INDEX IF NOT EXISTS port_closes_port_pid ON port_closes (port, pid);
+CREATE INDEX IF NOT EXISTS scans_at ON scans (at);
+CREATE INDEX IF NOT EXISTS scan_ports_scan ON scan_ports (scan_id);
+PRAGMA user_version = 4;
+`
+
+// historyMigrations bring an older database up to historySchema; the one at index i
+// upgrades from user_version i+1. New tables need none, historySchema creates them:
+// version 3 added port_closes.
+var historyMigrations = []string{
+	`ALTER TABLE scan_ports ADD COLUMN started_at INTEGER NOT NULL DEFAULT 0`,
+	``,
+	`ALTER TABLE port_events ADD COLUMN command_line TEXT NOT NULL DEFAULT '';
+	ALTER TABLE port_events ADD COLUMN user TEXT NOT NULL DEFAULT ''`,
+}
+
+// scanRetention is how long scan snapshots are kept; port and workspace events stay
+// until [logs] rotation moves them out (logrotate.go)
+const scanRetention = 90 * 24 * time.Hour
+
+// PortEvent is a port first seen listening in a directory
+type PortEvent struct {
+	ID      int64
+	Started time.Time // when the process started, not when portage noticed it
+	Port    int
+	PID     string
+	Command string
+	Path    string
+
+	CommandLine string // "" for events from before version 4 and the old ports log
+	User        string
+}
+
+// PortClose is a listener that stopped: it was in a scan snapshot and missing from the
+// next one
+type PortClose struct {
+	ID      int64
+	Closed  time.Time
+	Started time.Time
+	Port    int
+	PID     string
+	Command string
+	Path    string
+}
+
+// lifetime is how long the listener ran
+func (c PortClose) lifetime() time.Duration {
+	return c.Closed.Sub(c.Started)
+}
+
+// logLine writes the event as a line of the old ports log
+func (e PortEvent) logLine() string {
+	return fmt.Sprintf("%s\t%d\t%s\t%s\t%s", e.Started.Format("2006-01-02 15:04:05"), e.Port, e.PID, e.Command, e.Path)
+}
+
+var historyDB struct {
+	sync.Once
+	db  *sql.DB
+	err error
+}
+
+// getHistoryPath returns history.db, [logs] history in config.toml
+func getHistoryPath() string {
+	return expandHome(loadSettings().Logs.History)
+}
+
+// openHistory opens history.db once, creating it and importing the old text logs on
+// first use. In read-only mode nothing is written: an existing database is opened
+// read-only, and a missing one is built in memory from the old logs for this run.
+func openHistory() (*sql.DB, error) {
+	historyDB.Do(func() {
+		historyDB.db, historyDB.err = openHistoryDB()
+	})
+	return historyDB.db, historyDB.err
+}
+
+func openHistoryDB() (*sql.DB, error) {
+	path := getHistoryPath()
+	_, statErr := os.Stat(path)
+	inMemory := readOnly && statErr != nil
+
+	// Other portage processes (--watch next to -i) may be writing
+	query := "_pragma=busy_timeout(5000)"
+	dsn := (&url.URL{Scheme: "file", Path: path, RawQuery: query}).String()
+	switch {
+	case inMemory:
+		dsn = "file::memory:?" + query
+	case readOnly:
+		dsn = (&url.URL{Scheme: "file", Path: path, RawQuery: "mode=ro&" + query}).String()
+	default:
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+	}
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1) // an in-memory database lives in its one connection
+
+	if readOnly && !inMemory {
+		return db, nil
+	}
+	if err := migrateHistory(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", shortenPath(path), err)
+	}
+	importLegacyLogs(db, !inMemory)
+	if !inMemory {
+		db.Exec(`DELETE FROM scan_ports WHERE scan_id IN (SELECT id FROM scans WHERE at < ?)`, time.Now().Add(-scanRetention).Unix())
+		db.Exec(`DELETE FROM scans WHERE at < ?`, time.Now().Add(-scanRetention).Unix())
+		rotateHistory(db, path, time.Now())
+	}
+	return db, nil
+}
+
+// migrateHistory creates the tables, upgrading the ones of an older version first
+func migrateHistory(db *sql.DB) error {
+	version, err := historyVersion(db)
+	if err != nil {
+		return err
+	}
+	if version > 0 { // 0 is a new, empty database
+		for _, migration := range historyMigrations[min(version-1, len(historyMigrations)):] {
+			if migration == "" {
+				continue
+			}
+			if _, err := db.Exec(migration); err != nil {
+				return err
+			}
+		}
+	}
+	_, err = db.Exec(historySchema)
+	return err
+}
+
+// historyVersion returns the schema version of the database, which can be older than
+// historySchema when it was opened read-only
+func historyVersion(db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRow(`PRAGMA user_version`).Scan(&version)
+	return version, err
+}
+
+// importLegacyLogs copies the tab-separated ports log and the comma-separated
+// workspaces log of older versions into the database. Each file is renamed to
+// *.imported afterwards so it's only imported once; rename is false for the in-memory
+// database of read-only mode.
+func importLegacyLogs(db *sql.DB, rename bool) {
+	workspaceLog, _ := getWorkspaceLogPath()
+	for _, legacy := range []struct {
+		path   string
+		insert func(tx *sql.Tx, line string) bool
+	}{
+		{readPath(getLogPath()), importPortLogLine},
+		{readPath(workspaceLog), importWorkspaceLogLine},
+	} {
+		f, err := os.Open(legacy.path)
+		if err != nil {
+			continue
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			f.Close()
+			continue
+		}
+		imported := 0
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if legacy.insert(tx, scanner.Text()) {
+				imported++
+			}
+		}
+		f.Close()
+		if scanner.Err() != nil || tx.Commit() != nil {
+			tx.Rollback()
+			fmt.Fprintf(os.Stderr, "portage: couldn't import %s into %s\n", shortenPath(legacy.path), shortenPath(getHistoryPath()))
+			continue
+		}
+		if !rename {
+			continue
+		}
+		if err := os.Rename(legacy.path, legacy.path+".imported"); err != nil {
+			fmt.Fprintf(os.Stderr, "portage: imported %s but couldn't rename it: %v\n", shortenPath(legacy.path), err)
+			continue
+		}
+		if !demoMode {
+			fmt.Fprintf(os.Stderr, "portage: imported %d entries of %s into %s (the old file is %s.imported)\n",
+				imported, shortenPath(legacy.path), shortenPath(getHistoryPath()), filepath.Base(legacy.path))
+		}
+	}
+}
+
+// importPortLogLine reads "started\tport\tpid\tcommand\tpath" from the old ports log
+func importPortLogLine(tx *sql.Tx, line string) bool {
+	parts := strings.Split(line, "\t")
+	if len(parts) < 5 {
+		return false
+	}
+	started, err := time.ParseInLocation("2006-01-02 15:04:05", parts[0], time.Local)
+	if err != nil {
+		return false
+	}
+	port, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	_, err = tx.Exec(`INSERT INTO port_events (started_at, port, pid, command, path) VALUES (?, ?, ?, ?, ?)`,
+		started.Unix(), port, parts[2], parts[3], parts[4])
+	return err == nil
+}
+
+// importWorkspaceLogLine reads "timestamp,event,path" from the old workspaces log
+func importWorkspaceLogLine(tx *sql.Tx, line string) bool {
+	parts := strings.SplitN(strings.TrimSpace(line), ",", 3)
+	if len(parts) != 3 {
+		return false
+	}
+	at, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return false
+	}
+	_, err = tx.Exec(`INSERT INTO workspace_events (at, event, path) VALUES (?, ?, ?)`, at, parts[1], parts[2])
+	return err == nil
+}
+
+// exportLegacyLogs writes the port and workspace events in the text formats of the old
+// logs, which importLegacyLogs reads back
+func exportLegacyLogs() (ports, workspaces string, err error) {
+	portEvents, err := loadPortEvents(time.Time{})
+	if err != nil {
+		return "", "", err
+	}
+	workspaceEvents, err := loadWorkspaceEvents()
+	if err != nil {
+		return "", "", err
+	}
+	var b strings.Builder
+	for _, event := range portEvents {
+		b.WriteString(event.logLine() + "\n")
+	}
+	ports = b.String()
+	b.Reset()
+	for _, event := range workspaceEvents {
+		fmt.Fprintf(&b, "%d,%s,%s\n", event.Timestamp, event.Event, event.Path)
+	}
+	return ports, b.String(), nil
+}
+
+// recordScan adds the listeners seen for the first time in their directory to the
+// history, with the time their process started, and snapshots the scan if its
+// listeners differ from the last snapshot, recording the ones that stopped since.
+// ports is the whole scan, before any filter, so that listeners a run doesn't show
+// aren't taken for stopped. History is best effort: errors are ignored, and nothing
+// is written in read-only mode.
+func recordScan(ports []PortInfo) {
+	if readOnly {
+		return
+	}
+	db, err := openHistory()
+	if err != nil {
+		return
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, port := range ports {
+		// Skip N/A and root paths
+		if port.Path == "N/A" || port.Path == "/" {
+			continue
+		}
+		started := now.Add(-time.Duration(port.UptimeSeconds) * time.Second)
+		tx.Exec(`INSERT INTO port_events (started_at, port, pid, command, path, command_line, user)
+			SELECT ?, ?, ?, ?, ?, ?, ? WHERE NOT EXISTS (SELECT 1 FROM port_events WHERE port = ? AND path = ?)`,
+			started.Unix(), port.Port, port.PID, port.Command, port.Path, port.CommandLine, port.User, port.Port, port.Path)
+	}
+
+	previous, hasPrevious := lastSnapshot(tx)
+	current := make(map[string]bool)
+	for _, port := range ports {
+		current[snapshotKey(port.Port, port.PID)] = true
+	}
+	changed := len(previous) != len(current) || (!hasPrevious && len(current) > 0)
+	for key, gone := range previous {
+		if current[key] {
+			continue
+		}
+		changed = true
+		tx.Exec(`INSERT INTO port_closes (closed_at, started_at, port, pid, command, path) VALUES (?, ?, ?, ?, ?, ?)`,
+			now.Unix(), gone.Started.Unix(), gone.Port, gone.PID, gone.Command, gone.Path)
+	}
+
+	if changed {
+		result, err := tx.Exec(`INSERT INTO scans (at) VALUES (?)`, now.Unix())
+		if err != nil {
+			return
+		}
+		scanID, _ := result.LastInsertId()
+		for _, port := range ports {
+			started := now.Add(-time.Duration(port.UptimeSeconds) * time.Second)
+			tx.Exec(`INSERT INTO scan_ports (scan_id, port, pid, command, path, started_at) VALUES (?, ?, ?, ?, ?, ?)`,
+				scanID, port.Port, port.PID, port.Command, port.Path, started.Unix())
+		}
+	}
+	tx.Commit()
+}
+
+func snapshotKey(port int, pid string) string {
+	return fmt.Sprintf("%d-%s", port, pid)
+}
+
+// listenerSet finds listeners by port, PID and start. PIDs get reused, so the starts
+// have to agree too, to within a minute: they come from uptimes read at different scans.
+type listenerSet map[string][]time.Time
+
+func (s listenerSet) add(port int, pid string, started time.Time) {
+	s[snapshotKey(port, pid)] = append(s[snapshotKey(port, pid)], started)
+}
+
+func (s listenerSet) has(port int, pid string, started time.Time) bool {
+	for _, other := range s[snapshotKey(port, pid)] {
+		if d := started.Sub(other); d > -time.Minute && d < time.Minute {
+			return true
+		}
+	}
+	return false
+}
+
+// historyQuerier is a database or a transaction
+type historyQuerier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// lastSnapshot returns the listeners of the last scan snapshot by port and PID, with
+// when they started: as recorded, else the first snapshot they're in. ok is false when
+// there is no snapshot yet.
+func lastSnapshot(q historyQuerier) (listeners map[string]PortClose, ok bool) {
+	listeners = make(map[string]PortClose)
+	var lastID sql.NullInt64
+	if q.QueryRow(`SELECT MAX(id) FROM scans`).Scan(&lastID) != nil || !lastID.Valid {
+		return listeners, false
+	}
+	rows, err := q.Query(`SELECT p.port, p.pid, p.command, p.path, CASE WHEN p.started_at > 0 THEN p.started_at ELSE
+			(SELECT MIN(s.at) FROM scans s JOIN scan_ports o ON o.scan_id = s.id WHERE o.port = p.port AND o.pid = p.pid) END
+		FROM scan_ports p WHERE p.scan_id = ?`, lastID.Int64)
+	if err != nil {
+		return listeners, true
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var listener PortClose
+		var started int64
+		if rows.Scan(&listener.Port, &listener.PID, &listener.Command, &listener.Path, &started) == nil {
+			listener.Started = time.Unix(started, 0)
+			listeners[snapshotKey(listener.Port, listener.PID)] = listener
+		}
+	}
+	return listeners, true
+}
+
+// loadPortEvents returns the ports first seen since a time, oldest first
+func loadPortEvents(since time.Time) ([]PortEvent, error) {
+	return queryPortEvents(`WHERE started_at >= ? ORDER BY started_at, id`, since.Unix())
+}
+
+// recentPortEvents returns the last n ports added to the history, oldest first
+func recentPortEvents(n int) ([]PortEvent, error) {
+	events, err := queryPortEvents(`ORDER BY id DESC LIMIT ?`, n)
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, err
+}
+
+// queryPortEvents selects port events with a WHERE and ORDER BY clause
+func queryPortEvents(clauses string, args ...interface{}) ([]PortEvent, error) {
+	db, err := openHistory()
+	if err != nil {
+		return nil, err
+	}
+	columns := "command_line, user"
+	if version, err := historyVersion(db); err != nil {
+		return nil, err
+	} else if version < 4 { // opened read-only, before port_events had them
+		columns = "'', ''"
+	}
+	rows, err := db.Query(`SELECT id, started_at, port, pid, command, path, `+columns+` FROM port_events `+clauses, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var events []PortEvent
+	for rows.Next() {
+		var event PortEvent
+		var started int64
+		if err := rows.Scan(&event.ID, &started, &event.Port, &event.PID, &event.Command, &event.Path, &event.CommandLine, &event.User); err != nil {
+			return nil, err
+		}
+		event.Started = time.Unix(started, 0)
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// loadPortCloses returns every listener recorded as stopped, oldest first. A database
+// opened read-only from before version 3 has none.
+func loadPortCloses() ([]PortClose, error) {
+	db, err := openHistory()
+	if err != nil {
+		return nil, err
+	}
+	if version, err := historyVersion(db); err != nil || version < 3 {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT id, closed_at, started_at, port, pid, command, path FROM port_closes ORDER BY closed_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var closes []PortClose
+	for rows.Next() {
+		var c PortClose
+		var closed, started int64
+		if err := rows.Scan(&c.ID, &closed, &started, &c.Port, &c.PID, &c.Command, &c.Path); err != nil {
+			return nil, err
+		}
+		c.Closed, c.Started = time.Unix(closed, 0), time.Unix(started, 0)
+		closes = append(closes, c)
+	}
+	return closes, rows.Err()
+}
+
+// portLifetime is how long a listener of the history ran, or has been running
+type portLifetime struct {
+	Started  time.Time
+	Duration time.Duration
+	Running  bool
+}
+
+// portLifetimes lists the lifetimes known by port and PID (snapshotKey): the stopped
+// listeners' and, up to now, the ones of the last snapshot that still run
+type portLifetimes map[string][]portLifetime
+
+func loadPortLifetimes(now time.Time) portLifetimes {
+	lifetimes := make(portLifetimes)
+	closes, err := loadPortCloses()
+	if err != nil {
+		return lifetimes
+	}
+	for _, c := range closes {
+		key := snapshotKey(c.Port, c.PID)
+		lifetimes[key] = append(lifetimes[key], portLifetime{Started: c.Started, Duration: c.lifetime()})
+	}
+	db, err := openHistory()
+	if err != nil {
+		return lifetimes
+	}
+	listeners, _ := lastSnapshot(db)
+	for key, listener := range listeners {
+		if processExists(listener.PID) {
+			lifetimes[key] = append(lifetimes[key], portLifetime{Started: listener.Started, Duration: now.Sub(listener.Started), Running: true})
+		}
+	}
+	return lifetimes
+}
+
+// of returns the lifetime of a port event's listener, matched like listenerSet does
+func (l portLifetimes) of(event PortEvent) (portLifetime, bool) {
+	for _, lifetime := range l[snapshotKey(event.Port, event.PID)] {
+		if d := event.Started.Sub(lifetime.Started); d > -time.Minute && d < time.Minute {
+			return lifetime, true
+		}
+	}
+	return portLifetime{}, false
+}
+
+// loadWorkspaceEvents returns every workspace open and close event, oldest first
+func loadWorkspaceEvents() ([]WorkspaceEvent, error) {
+	db, err := openHistory()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT id, at, event, path FROM workspace_events ORDER BY at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var events []WorkspaceEvent
+	for rows.Next() {
+		var event WorkspaceEvent
+		if err := rows.Scan(&event.ID, &event.Timestamp, &event.Event, &event.Path); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// appendWorkspaceEvent records a workspace being opened or closed
+func appendWorkspaceEvent(event, path string) error {
+	if readOnly {
+		return errReadOnly
+	}
+	db, err := openHistory()
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO workspace_events (at, event, path) VALUES (?, ?, ?)`, time.Now().Unix(), event, path)
+	return err
+}
+
+// deleteHistoryEntries removes port, close or workspace events by ID and returns how
+// many went
+func deleteHistoryEntries(table string, ids []int64) (int, error) {
+	if readOnly {
+		return 0, errReadOnly
+	}
+	if table != "port_events" && table != "port_closes" && table != "workspace_events" {
+		return 0, fmt.Errorf("no history table %q", table)
+	}
+	db, err := openHistory()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, id := range ids {
+		result, err := db.Exec(`DELETE FROM `+table+` WHERE id = ?`, id)
+		if err != nil {
+			return removed, err
+		}
+		n, _ := result.RowsAffected()
+		removed += int(n)
+	}
+	return removed, nil
+}
+
+// loadScanAt returns the last snapshot taken at or before a time, with when it was
+// taken; ok is false when there is none that old
+func loadScanAt(at time.Time) (taken time.Time, ports []PortInfo, ok bool, err error) {
+	db, err := openHistory()
+	if err != nil {
+		return time.Time{}, nil, false, err
+	}
+	var scanID, takenAt int64
+	err = db.QueryRow(`SELECT id, at FROM scans WHERE at <= ? ORDER BY at DESC, id DESC LIMIT 1`, at.Unix()).Scan(&scanID, &takenAt)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil, false, nil
+	} else if err != nil {
+		return time.Time{}, nil, false, err
+	}
+	rows, err := db.Query(`SELECT port, pid, command, path FROM scan_ports WHERE scan_id = ? ORDER BY port`, scanID)
+	if err != nil {
+		return time.Time{}, nil, false, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var port PortInfo
+		if err := rows.Scan(&port.Port, &port.PID, &port.Command, &port.Path); err != nil {
+			return time.Time{}, nil, false, err
+		}
+		ports = append(ports, port)
+	}
+	return time.Unix(takenAt, 0), ports, true, rows.Err()
+}
+
+// parseHistoryTime reads a point in the past: a duration ago ("2h", "90m", "3d"), a time
+// today ("14:30"), the start of a day ("yesterday", "tuesday"), or a date with or
+// without a time ("2025-06-01 14:30")
+func parseHistoryTime(when string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(when); err == nil {
+		return now.Add(-d), nil
+	}
+	if days, err := strconv.Atoi(strings.TrimSuffix(when, "d")); err == nil && strings.HasSuffix(when, "d") {
+		return now.AddDate(0, 0, -days), nil
+	}
+	if day, ok := parseHistoryDay(when, now); ok {
+		return day, nil
+	}
+	if t, err := time.ParseInLocation("15:04", when, time.Local); err == nil {
+		return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, time.Local), nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, when, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a time: use a duration ago like 2h, a time today like 14:30, a day like tuesday, or \"2025-06-01 14:30\"", when)
+}
+
+// displayScanAt prints what was listening at a point in the past, from the last scan
+// snapshot taken before it
+func displayScanAt(when string) {
+	at, err := parseHistoryTime(when, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	taken, ports, ok, err := loadScanAt(at)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", shortenPath(getHistoryPath()), err)
+		os.Exit(1)
+	}
+	if !ok {
+		fmt.Printf("\n%s%sNo scans recorded before %s.%s\n\n", ColorBold, ColorYellow, at.Format("2006-01-02 15:04"), ColorReset)
+		return
+	}
+
+	fmt.Printf("\n%s%sPORTAGE - Listening at %s%s (scan of %s)\n\n", ColorBold, ColorCyan, at.Format("2006-01-02 15:04"), ColorReset, taken.Format("2006-01-02 15:04:05"))
+	if len(ports) == 0 {
+		fmt.Printf("%s%sNothing was listening.%s\n\n", ColorBold, ColorYellow, ColorReset)
+		return
+	}
+	t := newTable(table.StyleRounded)
+	t.AppendHeader(table.Row{"PORT", "PID", "COMMAND", "PATH"})
+	for _, port := range ports {
+		t.AppendRow(table.Row{port.Port, port.PID, port.Command, shortenPath(port.Path)})
+	}
+	fmt.Println(t.Render())
+	fmt.Printf("\n%s%sTotal: %d ports%s\n\n", ColorBold, ColorCyan, len(ports), ColorReset)
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// historyImporters read an external source into activity samples. file is the
+// --file override, empty for the source's default location.
+var historyImporters = map[string]func(file string) ([]activitySample, error){
+	"zsh-history":     importZshHistory,
+	"vscode-state":    importVSCodeState,
+	"wakatime-export": importWakaTimeExport,
+}
+
+// runHistory implements `portage history`, `portage history at`, `portage history
+// compact` and `portage history import`
+func runHistory(args []string) {
+	if len(args) > 0 && args[0] == "at" {
+		if len(args) < 2 {
+			fmt.Fprintf(os.Stderr, "Usage: portage history at <when>  (e.g. 2h, 14:30, \"2025-06-01 14:30\")\n")
+			os.Exit(2)
+		}
+		displayScanAt(strings.Join(args[1:], " "))
+		return
+	}
+	if len(args) > 0 && args[0] == "compact" {
+		runHistoryCompact(args[1:])
+		return
+	}
+	if len(args) == 0 || args[0] != "import" {
+		fs := flag.NewFlagSet("history", flag.ExitOnError)
+		fs.BoolVar(&jsonOutput, "json", false, "Output as JSON")
+		fs.BoolVar(&wideTable, "wide", false, "Show full command lines")
+		fs.Parse(args)
+		displayHistory(historyFilter{})
+		return
+	}
+
+	fs := flag.NewFlagSet("history import", flag.ExitOnError)
+	from := fs.String("from", "", "Source to import: zsh-history, vscode-state or wakatime-export")
+	file := fs.String("file", "", "Read from this file or directory instead of the source's default location")
+	dryRun := fs.Bool("dry-run", false, "Show what would be imported without writing anything")
+	fs.Parse(args[1:])
+
+	importer, ok := historyImporters[*from]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: --from must be one of zsh-history, vscode-state, wakatime-export\n")
+		os.Exit(1)
+	}
+
+	samples, err := importer(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error importing %s: %v\n", *from, err)
+		os.Exit(1)
+	}
+
+	// Keep imports coarse (one sample per project per minute) and skip anything already imported
+	existing := make(map[string]bool)
+	for _, sample := range loadRecordedActivity(time.Time{}) {
+		existing[activitySampleKey(sample)] = true
+	}
+	var fresh []activitySample
+	projects := make(map[string]bool)
+	for _, sample := range samples {
+		key := activitySampleKey(sample)
+		if existing[key] {
+			continue
+		}
+		existing[key] = true
+		fresh = append(fresh, sample)
+		projects[sample.Project] = true
+	}
+
+	if *dryRun {
+		fmt.Printf("%s%sWould import %d samples across %d projects from %s%s\n", ColorBold, ColorCyan, len(fresh), len(projects), *from, ColorReset)
+		return
+	}
+
+	if err := appendActivitySamples(*from, fresh); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", getActivityLogPath(), err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s%sImported %d samples across %d projects from %s%s\n", ColorBold, ColorCyan, len(fresh), len(projects), *from, ColorReset)
+}
+
+// activitySampleKey identifies a sample at minute granularity for deduplication
+func activitySampleKey(sample activitySample) string {
+	return sample.Time.Format("2006-01-02 15:04") + "\t" + sample.Project
+}
+
+// importZshHistory attributes each command in an extended zsh history to the git
+// repository it ran in (see readZshHistory for how directories are worked out)
+func importZshHistory(file string) ([]activitySample, error) {
+	commands, err := readZshHistory(file)
+	if err != nil {
+		return nil, err
+	}
+
+	repoCache := make(map[string]string)
+	var samples []activitySample
+	for _, command := range commands {
+		if command.Dir == "" {
+			continue
+		}
+		root, cached := repoCache[command.Dir]
+		if !cached {
+			root = findRepoRoot(command.Dir)
+			repoCache[command.Dir] = root
+		}
+		if root != "" {
+			samples = append(samples, activitySample{Time: command.Time, Project: root})
+		}
+	}
+	return samples, nil
+}
+
+// importVSCodeState uses the modification time of each VS Code workspace's
+// state.vscdb as a last-active sample for its folder
+func importVSCodeState(dir string) ([]activitySample, error) {
+	if dir == "" {
+		dir = expandHome("~/Library/Application Support/Code/User")
+	}
+	storage := filepath.Join(dir, "workspaceStorage")
+
+	entries, err := os.ReadDir(storage)
+	if err != nil {
+		return nil, err
+	}
+
+	var samples []activitySample
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		stat, err := os.Stat(filepath.Join(storage, entry.Name(), "state.vscdb"))
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(storage, entry.Name(), "workspace.json"))
+		if err != nil {
+			continue
+		}
+		var workspace struct {
+			Folder string `json:"folder"`
+		}
+		if err := json.Unmarshal(data, &workspace); err != nil || !strings.HasPrefix(workspace.Folder, "file://") {
+			continue // Remote and virtual workspaces have no local path
+		}
+		samples = append(samples, activitySample{
+			Time:    stat.ModTime(),
+			Project: strings.TrimPrefix(workspace.Folder, "file://"),
+		})
+	}
+	return samples, nil
+}
+
+// wakaTimeExport is the subset of WakaTime's data export (Settings → Export) we read.
+// Heartbeat exports carry file paths; summary-only exports just project names.
+type wakaTimeExport struct {
+	Days []struct {
+		Date       string `json:"date"`
+		Heartbeats []struct {
+			Time   float64 `json:"time"`
+			Entity string  `json:"entity"`
+			Type   string  `json:"type"`
+		} `json:"heartbeats"`
+		Projects []struct {
+			Name string `json:"name"`
+		} `json:"projects"`
+	} `json:"days"`
+}
+
+// importWakaTimeExport reads a WakaTime JSON export. Heartbeats on files are mapped
+// to their git repository; daily project totals are matched to known workspaces by name.
+func importWakaTimeExport(file string) ([]activitySample, error) {
+	if file == "" {
+		return nil, fmt.Errorf("--file is required (path to the exported JSON)")
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var export wakaTimeExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("not a WakaTime export: %w", err)
+	}
+
+	// Project names can only be mapped to directories we've seen before
+	knownPaths := make(map[string]string)
+	for _, entry := range collectWorkspaceHistory(0) {
+		knownPaths[strings.ToLower(filepath.Base(entry.Path))] = entry.Path
+	}
+
+	repoCache := make(map[string]string)
+	var samples []activitySample
+	for _, day := range export.Days {
+		if len(day.Heartbeats) > 0 {
+			for _, beat := range day.Heartbeats {
+				if beat.Type != "file" || !filepath.IsAbs(beat.Entity) {
+					continue
+				}
+				dir := filepath.Dir(beat.Entity)
+				root, cached := repoCache[dir]
+				if !cached {
+					root = findRepoRoot(dir)
+					repoCache[dir] = root
+				}
+				if root != "" {
+					samples = append(samples, activitySample{Time: time.Unix(int64(beat.Time), 0), Project: root})
+				}
+			}
+			continue
+		}
+
+		date, err := time.ParseInLocation("2006-01-02", day.Date, time.Local)
+		if err != nil {
+			continue
+		}
+		for _, project := range day.Projects {
+			if path, ok := knownPaths[strings.ToLower(project.Name)]; ok {
+				samples = append(samples, activitySample{Time: date.Add(12 * time.Hour), Project: path})
+			}
+		}
+	}
+	return samples, nil
+}
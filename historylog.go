@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"portage/durations"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// loadLogTabData merges port discoveries (as in `portage history`), stopped listeners
+// and workspace open/close events from history.db into one list, most recent first
+func loadLogTabData() tabData {
+	data := tabData{Header: []string{"WHEN", "EVENT", "PATH"}, Empty: "No history yet; portage logs new ports as it sees them"}
+	events, _ := queryEvents(eventQuery{Types: []string{EventPortStart, EventPortStop, EventWorkspaceOpen, EventWorkspaceClose}})
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].At.After(events[j].At)
+	})
+
+	for _, event := range events {
+		var description string
+		switch event.Type {
+		case EventPortStart:
+			if !isUserPort(PortInfo{Port: event.Port.Port, Command: event.Port.Command, Path: event.Path}) {
+				continue
+			}
+			description = fmt.Sprintf("%s on :%d", event.Port.Command, event.Port.Port)
+		case EventPortStop:
+			if !isUserPort(PortInfo{Port: event.Close.Port, Command: event.Close.Command, Path: event.Path}) {
+				continue
+			}
+			description = fmt.Sprintf("%s on :%d stopped (%s)", event.Close.Command, event.Close.Port, durations.Uptime.Format(event.Close.lifetime()))
+		default:
+			description = "workspace " + event.Workspace.Event
+		}
+		if !isUnderPathFilter(event.Path) {
+			continue
+		}
+		data.Rows = append(data.Rows, tabRow{
+			Columns:      []string{event.At.Format("2006-01-02 15:04"), description, shortenPath(event.Path)},
+			Path:         event.Path,
+			historyTable: event.Table,
+			historyID:    event.ID,
+		})
+	}
+	return data
+}
+
+// pruneLogRows removes rows of the Log view from history.db and reloads the view
+func (m model) pruneLogRows(rows []tabRow) (tea.Model, tea.Cmd) {
+	byTable := make(map[string][]int64)
+	for _, row := range rows {
+		byTable[row.historyTable] = append(byTable[row.historyTable], row.historyID)
+	}
+
+	total := 0
+	for table, ids := range byTable {
+		removed, err := deleteHistoryEntries(table, ids)
+		total += removed
+		if err != nil {
+			m.message = fmt.Sprintf("Failed to prune %s: %v", shortenPath(getHistoryPath()), err)
+			return m, nil
+		}
+	}
+	if total == 1 {
+		m.message = "Deleted 1 history entry"
+	} else {
+		m.message = fmt.Sprintf("Deleted %d history entries", total)
+	}
+	return m, loadTab(tabLog)
+}
+
+// deleteLogEntry removes the selected entry of the Log view, after asking
+func (m model) deleteLogEntry() (tea.Model, tea.Cmd) {
+	rows := m.tabRows()
+	if m.cursor >= len(rows) {
+		return m, nil
+	}
+	row := rows[m.cursor]
+	question := fmt.Sprintf("Delete \"%s %s\" from %s?", row.Columns[1], row.Columns[2], shortenPath(getHistoryPath()))
+	return m.confirmThen(question, func(m model) (tea.Model, tea.Cmd) {
+		return m.pruneLogRows([]tabRow{row})
+	})
+}
+
+// pruneMissingLogEntries removes every entry whose directory no longer exists, after asking
+func (m model) pruneMissingLogEntries() (tea.Model, tea.Cmd) {
+	var missing []tabRow
+	for _, row := range m.tabs[tabLog].Rows {
+		if !isDirectory(row.Path) {
+			missing = append(missing, row)
+		}
+	}
+	if len(missing) == 0 {
+		m.message = "Every logged directory still exists"
+		return m, nil
+	}
+	question := fmt.Sprintf("Delete %d entries of directories that no longer exist?", len(missing))
+	if len(missing) == 1 {
+		question = fmt.Sprintf("Delete the entry of %s, which no longer exists?", shortenPath(missing[0].Path))
+	}
+	return m.confirmThen(question, func(m model) (tea.Model, tea.Cmd) {
+		return m.pruneLogRows(missing)
+	})
+}
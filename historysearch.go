@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var historySearch string
+var historySince string
+var historyUntil string
+
+// historyFilter narrows the discovery history to ports whose path, command or number
+// contains Term, up at some point in [Since, Until). Zero values don't filter.
+type historyFilter struct {
+	Term  string
+	Since time.Time
+	Until time.Time
+}
+
+// searchingHistory reports whether --history-search, --since or --until was given
+func searchingHistory() bool {
+	return historySearch != "" || historySince != "" || historyUntil != ""
+}
+
+// parseHistoryFilter reads --history-search, --since and --until. A day given to
+// --until ("2025-06-01", "tuesday") includes the whole day.
+func parseHistoryFilter(now time.Time) (historyFilter, error) {
+	filter := historyFilter{Term: historySearch}
+	if historySince != "" {
+		since, err := parseHistoryTime(historySince, now)
+		if err != nil {
+			return filter, fmt.Errorf("--since: %w", err)
+		}
+		filter.Since = since
+	}
+	if historyUntil != "" {
+		if day, ok := parseHistoryDay(historyUntil, now); ok {
+			filter.Until = day.AddDate(0, 0, 1)
+		} else {
+			until, err := parseHistoryTime(historyUntil, now)
+			if err != nil {
+				return filter, fmt.Errorf("--until: %w", err)
+			}
+			filter.Until = until
+		}
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Since.Before(filter.Until) {
+		return filter, fmt.Errorf("--since %s is not before --until %s", historySince, historyUntil)
+	}
+	return filter, nil
+}
+
+// parseHistoryDay reads a whole day: "today", "yesterday", a weekday name for the last
+// one before today ("tuesday", "tue"), or a date. It returns the day's midnight.
+func parseHistoryDay(when string, now time.Time) (time.Time, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	when = strings.ToLower(strings.TrimSpace(when))
+	switch when {
+	case "today":
+		return today, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	}
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		name := strings.ToLower(weekday.String())
+		if when == name || when == name[:3] {
+			back := (int(today.Weekday()) - int(weekday) + 7) % 7
+			if back == 0 {
+				back = 7
+			}
+			return today.AddDate(0, 0, -back), true
+		}
+	}
+	if day, err := time.ParseInLocation("2006-01-02", when, time.Local); err == nil {
+		return day, true
+	}
+	return time.Time{}, false
+}
+
+// matches reports whether a port event passes the filter. Its listener counts from its
+// start until it stopped, or until now while it runs; one whose end isn't known only at
+// its start. The term is matched without case against the full and the ~ path, the
+// command and the port number.
+func (f historyFilter) matches(event PortEvent, lifetimes portLifetimes) bool {
+	if !f.Until.IsZero() && !event.Started.Before(f.Until) {
+		return false
+	}
+	if !f.Since.IsZero() {
+		end := event.Started
+		if lifetime, ok := lifetimes.of(event); ok {
+			end = lifetime.Started.Add(lifetime.Duration)
+		}
+		if end.Before(f.Since) {
+			return false
+		}
+	}
+	if f.Term == "" {
+		return true
+	}
+	term := strings.ToLower(f.Term)
+	for _, field := range []string{event.Path, shortenPath(event.Path), event.Command, strconv.Itoa(event.Port)} {
+		if strings.Contains(strings.ToLower(field), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// describe says what the filter keeps, for the history header
+func (f historyFilter) describe() string {
+	var parts []string
+	if f.Term != "" {
+		parts = append(parts, fmt.Sprintf("matching %q", f.Term))
+	}
+	if !f.Since.IsZero() {
+		parts = append(parts, "since "+f.Since.Format("2006-01-02 15:04"))
+	}
+	if !f.Until.IsZero() {
+		parts = append(parts, "until "+f.Until.Format("2006-01-02 15:04"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// runHistorySearch implements --history-search, --since and --until
+func runHistorySearch() {
+	filter, err := parseHistoryFilter(time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	displayHistory(filter)
+}
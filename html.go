@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// htmlActivityDays is how far back the project sparklines of --html go
+const htmlActivityDays = 30
+
+// htmlReport is what the --html page shows
+type htmlReport struct {
+	Host      string
+	Generated string
+	Total     int
+	Projects  []htmlProject
+}
+
+type htmlProject struct {
+	Path       string // shortened, as in the terminal
+	FullPath   string
+	Branch     string
+	Pinned     bool
+	ActiveDays int           // days with any activity in the last htmlActivityDays
+	Sparkline  template.HTML // inline SVG of active hours per day
+	Ports      []htmlPortRow
+}
+
+type htmlPortRow struct {
+	PortInfo
+	Dir string // working directory relative to the project
+}
+
+// displayHTML prints a standalone HTML page of the scan for --html: one sortable table,
+// grouped by project, with each project's recent activity as a sparkline. It has no
+// external assets, so it can be mailed or attached to a bug report as is.
+func displayHTML(portsByRange map[int][]PortInfo, sortOrder string) {
+	config := loadConfig()
+	allPorts := collectSortedPorts(portsByRange, sortOrder)
+	sortPinnedFirst(allPorts, config)
+	projects, groups := groupPortsByProject(allPorts)
+
+	host, _ := os.Hostname()
+	report := htmlReport{Host: host, Generated: time.Now().Format("2006-01-02 15:04:05 MST")}
+
+	activity := projectActivityHours(projects)
+	maxHours := 1
+	for _, days := range activity {
+		for _, hours := range days {
+			maxHours = max(maxHours, hours)
+		}
+	}
+
+	for _, root := range projects {
+		project := htmlProject{Path: "Unknown project", FullPath: root}
+		if root != "" {
+			project.Path = shortenPath(root)
+			project.Branch = gitBranch(root)
+			project.Pinned = config.isPinned(root)
+			for _, hours := range activity[root] {
+				if hours > 0 {
+					project.ActiveDays++
+				}
+			}
+			project.Sparkline = activitySparkline(activity[root], maxHours)
+		}
+		for _, port := range groups[root] {
+			dir := "-"
+			if root != "" {
+				if rel, err := filepath.Rel(root, port.Path); err == nil {
+					dir = rel
+				}
+			}
+			project.Ports = append(project.Ports, htmlPortRow{PortInfo: port, Dir: dir})
+		}
+		report.Total += len(project.Ports)
+		report.Projects = append(report.Projects, project)
+	}
+
+	if err := htmlPage.Execute(os.Stdout, report); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing HTML: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// projectActivityHours counts the distinct hours with activity on each of the last
+// htmlActivityDays days (oldest first) for every project, from the heatmap's sources
+func projectActivityHours(projects []string) map[string][]int {
+	today := time.Now()
+	end := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local)
+	start := end.AddDate(0, 0, -(htmlActivityDays - 1))
+
+	hours := make(map[string]map[string]bool)
+	for _, sample := range collectActivitySamples(start) {
+		for _, root := range projects {
+			if root == "" || (sample.Project != root && !strings.HasPrefix(sample.Project, root+"/")) {
+				continue
+			}
+			day := sample.Time.Format("2006-01-02")
+			if hours[root] == nil {
+				hours[root] = make(map[string]bool)
+			}
+			hours[root][day+sample.Time.Format(" 15")] = true
+		}
+	}
+
+	activity := make(map[string][]int)
+	for _, root := range projects {
+		days := make([]int, htmlActivityDays)
+		for i := range days {
+			day := start.AddDate(0, 0, i).Format("2006-01-02")
+			for hour := 0; hour < 24; hour++ {
+				if hours[root][fmt.Sprintf("%s %02d", day, hour)] {
+					days[i]++
+				}
+			}
+		}
+		activity[root] = days
+	}
+	return activity
+}
+
+// activitySparkline draws active hours per day as bars, scaled to the busiest day of
+// any project so rows can be compared
+func activitySparkline(days []int, maxHours int) template.HTML {
+	const barWidth, height = 3, 18
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="spark" width="%d" height="%d" viewBox="0 0 %d %d">`, len(days)*barWidth, height, len(days)*barWidth, height)
+	for i, hours := range days {
+		h := 1 // a baseline tick for idle days
+		if hours > 0 {
+			h = max(2, hours*height/maxHours)
+		}
+		class := "idle"
+		if hours > 0 {
+			class = "active"
+		}
+		fmt.Fprintf(&b, `<rect class="%s" x="%d" y="%d" width="%d" height="%d"/>`, class, i*barWidth, height-h, barWidth-1, h)
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String()) // built from numbers only
+}
+
+var htmlPage = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>portage · {{.Host}}</title>
+<style>
+  :root { --fg: #1f2328; --muted: #656d76; --border: #d0d7de; --group: #f6f8fa; --accent: #0969da; --spark: #2da44e; --idle: #d0d7de; --warn: #9a6700; }
+  @media (prefers-color-scheme: dark) {
+    :root { --fg: #e6edf3; --muted: #8d96a0; --border: #30363d; --group: #161b22; --accent: #4493f8; --spark: #3fb950; --idle: #30363d; --warn: #d29922; }
+    body { background: #0d1117; }
+  }
+  body { font: 14px/1.5 -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; color: var(--fg); margin: 2em; }
+  h1 { font-size: 1.4em; margin: 0; }
+  .meta { color: var(--muted); margin: .2em 0 1.5em; }
+  table { border-collapse: collapse; width: 100%; }
+  th, td { text-align: left; padding: .35em .8em; border-bottom: 1px solid var(--border); }
+  thead th { cursor: pointer; user-select: none; white-space: nowrap; }
+  thead th:hover { color: var(--accent); }
+  thead th[data-dir="asc"]::after { content: " ▲"; }
+  thead th[data-dir="desc"]::after { content: " ▼"; }
+  tr.project th { background: var(--group); font-weight: 600; }
+  tr.project .branch { color: var(--accent); font-weight: normal; }
+  tr.project .activity { float: right; color: var(--muted); font-weight: normal; }
+  .spark { vertical-align: middle; margin-left: .5em; }
+  .spark .active { fill: var(--spark); }
+  .spark .idle { fill: var(--idle); }
+  .num { font-variant-numeric: tabular-nums; }
+  .muted { color: var(--muted); }
+  .warn { color: var(--warn); }
+  code { font: 12px ui-monospace, SFMono-Regular, Menlo, monospace; }
+</style>
+</head>
+<body>
+<h1>portage</h1>
+<p class="meta">{{.Total}} ports in {{len .Projects}} projects on {{.Host}} · {{.Generated}}</p>
+{{if .Projects}}
+<table id="ports">
+<thead><tr>
+  <th data-type="num">Port</th><th>Command</th><th>Role</th><th data-type="num">PID</th><th data-type="num">Uptime</th><th>Address</th><th>Dir</th><th>Check</th><th>Note</th>
+</tr></thead>
+{{range .Projects}}
+<tbody>
+<tr class="project"><th colspan="9" title="{{.FullPath}}">
+  {{if .Pinned}}★ {{end}}{{.Path}}{{if .Branch}} <span class="branch">({{.Branch}})</span>{{end}}
+  {{if .Sparkline}}<span class="activity" title="active hours per day, last 30 days">{{.ActiveDays}} active day{{if ne .ActiveDays 1}}s{{end}}{{.Sparkline}}</span>{{end}}
+</th></tr>
+{{range .Ports}}
+<tr>
+  <td class="num" data-sort="{{.Port}}">{{.Port}}{{if .Name}} <span class="muted">{{.Name}}</span>{{end}}</td>
+  <td title="{{.CommandLine}}"><code>{{.Command}}</code></td>
+  <td>{{.Role}}</td>
+  <td class="num" data-sort="{{.PID}}">{{.PID}}</td>
+  <td class="num" data-sort="{{.UptimeSeconds}}">{{.Uptime}}</td>
+  <td><code>{{.Address}}</code></td>
+  <td>{{.Dir}}{{if .Orphaned}} <span class="warn">(missing)</span>{{end}}</td>
+  <td>{{.Check}}</td>
+  <td>{{.Note}}</td>
+</tr>
+{{end}}
+</tbody>
+{{end}}
+</table>
+{{else}}
+<p>No active ports found.</p>
+{{end}}
+<script>
+// Sort the rows within each project; the project row stays on top
+document.querySelectorAll("#ports thead th").forEach(function (th, column) {
+  th.addEventListener("click", function () {
+    var dir = th.dataset.dir === "asc" ? "desc" : "asc";
+    document.querySelectorAll("#ports thead th").forEach(function (other) { delete other.dataset.dir; });
+    th.dataset.dir = dir;
+    var numeric = th.dataset.type === "num";
+    document.querySelectorAll("#ports tbody").forEach(function (body) {
+      var rows = Array.prototype.slice.call(body.querySelectorAll("tr:not(.project)"));
+      rows.sort(function (a, b) {
+        var x = a.cells[column].dataset.sort || a.cells[column].textContent.trim();
+        var y = b.cells[column].dataset.sort || b.cells[column].textContent.trim();
+        var order = numeric ? Number(x) - Number(y) : x.localeCompare(y);
+        return dir === "asc" ? order : -order;
+      });
+      rows.forEach(function (row) { body.appendChild(row); });
+    });
+  });
+});
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// checkHTTP requests / on a port without following redirects and returns the status
+// code, "timeout" when nothing answers in time, "refused", or "" when the port doesn't
+// speak HTTP (databases, tunnels to non-web services, ...)
+func checkHTTP(port PortInfo, timeout time.Duration) string {
+	if demoMode {
+		return demoHTTPCheck(port)
+	}
+	client := &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get(fmt.Sprintf("http://%s:%d/", portHost(port), port.Port))
+	if err != nil {
+		var netErr net.Error
+		switch {
+		case errors.As(err, &netErr) && netErr.Timeout():
+			return "timeout"
+		case errors.Is(err, syscall.ECONNREFUSED):
+			return "refused"
+		}
+		return ""
+	}
+	resp.Body.Close()
+	return strconv.Itoa(resp.StatusCode)
+}
+
+// resolveHTTPChecks probes every port in parallel and fills in the Check field
+func resolveHTTPChecks(ports []PortInfo, timeout time.Duration) {
+	var wg sync.WaitGroup
+	for i := range ports {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ports[i].Check = checkHTTP(ports[i], timeout)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// checkLevel buckets a check result: "ok" for 2xx, "warn" for 3xx and 4xx, "fail" for
+// 5xx, timeouts and refused connections, "" for ports that don't speak HTTP
+func checkLevel(check string) string {
+	switch {
+	case check == "":
+		return ""
+	case check == "timeout" || check == "refused":
+		return "fail"
+	case check[0] == '2':
+		return "ok"
+	case check[0] == '3' || check[0] == '4':
+		return "warn"
+	}
+	return "fail"
+}
+
+// formatCheck colors a check result for the table; non-HTTP ports show "-"
+func formatCheck(check string) string {
+	switch checkLevel(check) {
+	case "ok":
+		return ColorGreen + check + ColorReset
+	case "warn":
+		return ColorYellow + check + ColorReset
+	case "fail":
+		return ColorRed + check + ColorReset
+	}
+	return "-"
+}
+
+// checkDot is the row indicator of interactive mode; the shapes differ too, so the state
+// reads without colors (and on the selected row, which isn't colored)
+func checkDot(check string, checked, colored bool) string {
+	dot, color := "·", ui.Muted
+	switch checkLevel(check) {
+	case "ok":
+		dot, color = "●", ui.Success
+	case "warn":
+		dot, color = "◐", ui.Warning
+	case "fail":
+		dot, color = "✕", ui.Danger
+	default:
+		if !checked {
+			return " " // not probed yet
+		}
+	}
+	if !colored {
+		return dot
+	}
+	return lipgloss.NewStyle().Foreground(color).Render(dot)
+}
+
+// checkTickMsg starts the next round of checks in interactive mode
+type checkTickMsg time.Time
+
+// checksDoneMsg carries a round of check results by "port-pid"
+type checksDoneMsg map[string]string
+
+func checkTick() tea.Cmd {
+	return tea.Tick(checkInterval, func(t time.Time) tea.Msg {
+		return checkTickMsg(t)
+	})
+}
+
+// runChecks probes the current ports off the UI goroutine
+func (m model) runChecks() tea.Cmd {
+	ports := append([]PortInfo(nil), m.ports...)
+	return func() tea.Msg {
+		resolveHTTPChecks(ports, checkTimeout)
+		results := make(checksDoneMsg)
+		for _, port := range ports {
+			results[fmt.Sprintf("%d-%s", port.Port, port.PID)] = port.Check
+		}
+		return results
+	}
+}
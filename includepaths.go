@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// foldsOtherPorts reports whether listeners outside filters.include_paths are folded
+// into "other": only when include_paths is set, and not when everything was asked for
+// or something specific was, with --port or --path
+func foldsOtherPorts() bool {
+	return len(loadSettings().Filters.IncludePaths) > 0 &&
+		!showAllPorts && !showSystemPorts && !includeSystem && len(portFilter) == 0 && pathFilter == ""
+}
+
+func filterOtherPorts(portsByRange map[int][]PortInfo) map[int][]PortInfo {
+	filters := loadSettings().Filters
+	filtered := make(map[int][]PortInfo)
+
+	for rangeStart, ports := range portsByRange {
+		filtered[rangeStart] = []PortInfo{}
+		for _, port := range ports {
+			if filters.includesPath(port.Path) {
+				filtered[rangeStart] = append(filtered[rangeStart], port)
+			}
+		}
+	}
+
+	return filtered
+}
+
+// otherPortsSummary describes the ports outside filters.include_paths, e.g. "2 other
+// ports (ruby :4000, postgres :5432)"
+func otherPortsSummary(ports []PortInfo) string {
+	filters := loadSettings().Filters
+	var outside []PortInfo
+	for _, port := range ports {
+		if !filters.includesPath(port.Path) {
+			outside = append(outside, port)
+		}
+	}
+	if len(outside) == 0 {
+		return ""
+	}
+	sort.Slice(outside, func(i, j int) bool { return outside[i].Port < outside[j].Port })
+	var other []string
+	for _, port := range outside {
+		other = append(other, fmt.Sprintf("%s :%d", port.Command, port.Port))
+	}
+	count, noun := len(other), "other ports"
+	if count == 1 {
+		noun = "other port"
+	}
+	if count > 4 {
+		other = append(other[:3], fmt.Sprintf("%d more", count-3))
+	}
+	return fmt.Sprintf("%d %s (%s)", count, noun, strings.Join(other, ", "))
+}
+
+// displayFoldedOtherPorts mentions the listeners left out of the table for being outside
+// filters.include_paths, scoped like the table
+func displayFoldedOtherPorts(ports []PortInfo, config *Config) {
+	if !foldsOtherPorts() || showOrphans {
+		return
+	}
+	var folded []PortInfo
+	for _, port := range ports {
+		if !isUserPort(port) || config.isHidden(port) || (port.Daemon != "" && !showDaemons) {
+			continue
+		}
+		if !matchesUserFilter(port) || !matchesRegexFilter(port) {
+			continue
+		}
+		folded = append(folded, port)
+	}
+	if summary := otherPortsSummary(folded); summary != "" {
+		fmt.Printf("Folded %s outside include_paths - show them with --include-system\n\n", summary)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// inspectView shows the unsummarized ps and lsof output of a process, opened with i in
+// interactive mode for listeners the columns don't explain
+type inspectView struct {
+	port   PortInfo
+	lines  []string
+	offset int // first line shown
+	column int // horizontal scroll, lsof lines are wide
+}
+
+// inspectCommands are run for the selected PID, in this order
+func inspectCommands(pid string) [][]string {
+	return [][]string{
+		{"ps", "-ww", "-o", "pid,ppid,user,lstart,etime,%cpu,%mem,rss,command", "-p", pid},
+		{"lsof", "-n", "-P", "-p", pid},
+	}
+}
+
+// loadInspectLines runs the inspect commands, each output under a "$ command" line
+func loadInspectLines(pid string) []string {
+	var lines []string
+	for i, command := range inspectCommands(pid) {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "$ "+strings.Join(command, " "))
+		output, err := commandOutput(command[0], command[1:]...)
+		if text := strings.TrimRight(string(output), "\n"); text != "" {
+			lines = append(lines, strings.Split(text, "\n")...)
+		}
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("(%v)", err))
+		}
+	}
+	return lines
+}
+
+// openInspectView shows the raw ps and lsof output of the selected port's process
+func (m model) openInspectView(port PortInfo) model {
+	m.inspect = &inspectView{port: port, lines: loadInspectLines(port.PID)}
+	return m
+}
+
+// updateInspect handles keys while the inspect view is open
+func (m model) updateInspect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := m.inspect
+	height := m.logViewHeight()
+	last := max(len(v.lines)-height, 0)
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q", "i":
+		m.inspect = nil
+	case "up", "k":
+		v.offset = max(v.offset-1, 0)
+	case "down", "j":
+		v.offset = min(v.offset+1, last)
+	case "pgup", "b":
+		v.offset = max(v.offset-height, 0)
+	case "pgdown", " ":
+		v.offset = min(v.offset+height, last)
+	case "g", "home":
+		v.offset = 0
+	case "G", "end":
+		v.offset = last
+	case "left", "h":
+		v.column = max(v.column-20, 0)
+	case "right", "l":
+		v.column += 20
+	case "r":
+		// Run the commands again, e.g. after the process opened more files
+		v.lines = loadInspectLines(v.port.PID)
+		v.offset = min(v.offset, max(len(v.lines)-height, 0))
+	}
+	return m, nil
+}
+
+// viewInspect renders the inspect view
+func (m model) viewInspect() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Accent)
+	commandStyle := lipgloss.NewStyle().Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(ui.Muted)
+
+	v := m.inspect
+	width := m.width
+	if width == 0 {
+		width = getTerminalWidth()
+	}
+	height := m.logViewHeight()
+
+	var s strings.Builder
+	s.WriteString(titleStyle.Render(fmt.Sprintf("INSPECT - %s (PID %s) on port %d", v.port.Command, v.port.PID, v.port.Port)))
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render(shortenPath(v.port.Path)))
+	s.WriteString("\n\n")
+
+	end := min(v.offset+height, len(v.lines))
+	for _, line := range v.lines[v.offset:end] {
+		runes := []rune(strings.ReplaceAll(line, "\t", "    "))
+		if v.column < len(runes) {
+			line = truncate(string(runes[v.column:]), width)
+		} else {
+			line = ""
+		}
+		if strings.HasPrefix(line, "$ ") && v.column == 0 {
+			line = commandStyle.Render(line)
+		}
+		s.WriteString(line)
+		s.WriteString("\n")
+	}
+	for i := end - v.offset; i < height; i++ {
+		s.WriteString("\n")
+	}
+
+	status := fmt.Sprintf("line %d of %d", v.offset+1, len(v.lines))
+	if v.column > 0 {
+		status += fmt.Sprintf(", column %d", v.column+1)
+	}
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Width(width).Render("↑/↓ j/k: scroll • pgup/pgdn: page • g/G: top/bottom • ←/→: scroll sideways • r: run again • esc/i: back • " + status))
+	return s.String()
+}
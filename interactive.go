@@ -2,11 +2,14 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -14,12 +17,49 @@ import (
 )
 
 type Config struct {
-	HiddenPorts map[string]bool `json:"hidden_ports"` // key: "port-pid"
+	HiddenPorts       map[string]bool   `json:"hidden_ports"`                  // key: "port-pid", until the process restarts
+	HideRules         []HideRule        `json:"hide_rules,omitempty"`          // hide by directory and/or command (hiderules.go)
+	HideRulesMigrated bool              `json:"hide_rules_migrated,omitempty"` // old hidden_ports entries were turned into rules
+	Snoozed           []Snooze          `json:"snoozed,omitempty"`             // hidden until a given time with H (snooze.go)
+	SensitivePorts    []int             `json:"sensitive_ports,omitempty"`     // alert in --watch mode when these start listening
+	Aliases           map[string]string `json:"aliases,omitempty"`             // "3000" or "~/dev/api" -> display name
+	OpenActions       []OpenAction      `json:"open_actions,omitempty"`        // per-port-range behavior of the open action
+	AuditAllow        []int             `json:"audit_allow,omitempty"`         // ports expected to listen on all interfaces (--audit)
+	ReadOnly          bool              `json:"read_only,omitempty"`           // make --read-only the default
+	KillGraceSeconds  int               `json:"kill_grace_seconds,omitempty"`  // "TERM, then KILL" delay of the K picker (default 5)
+	Pinned            []string          `json:"pinned,omitempty"`              // projects listed first and starred (`portage pin`)
+	PinnedPorts       []int             `json:"pinned_ports,omitempty"`        // ports pinned when there is no project to pin
+	SkipConfirm       bool              `json:"skip_confirm,omitempty"`        // don't ask before kill, restart and bulk actions
+	TerminalCommand   string            `json:"terminal_command,omitempty"`    // run by t, e.g. "kitty --directory {path}" (openterminal.go)
+	Notes             map[string]string `json:"notes,omitempty"`               // project directory -> note, edited with n (notes.go)
+	Launch            map[string]string `json:"launch,omitempty"`              // project directory -> command started with x (launch.go)
+	Columns           []string          `json:"columns,omitempty"`             // port list columns, e.g. ["port","command","cpu","branch","path"] (columns.go)
+
+	Notifications *NotificationConfig `json:"notifications,omitempty"` // where --watch alerts are sent (notify.go)
+	Theme         *ThemeConfig        `json:"theme,omitempty"`         // colors and table borders (theme.go)
+
+	Profiles map[string]*ProfileConfig `json:"profiles,omitempty"` // what each --profile keeps for itself (profiles.go)
+}
+
+// terminalCommand is what t runs: editor.terminal in config.toml, else terminal_command
+func (c *Config) terminalCommand() string {
+	if command := loadSettings().Editor.Terminal; command != "" {
+		return command
+	}
+	return c.TerminalCommand
 }
 
+// theme is [theme] in config.toml, else "theme" in portage.json
+func (c *Config) theme() *ThemeConfig {
+	if theme := loadSettings().Theme; theme != nil && *theme != (ThemeConfig{}) {
+		return theme
+	}
+	return c.Theme
+}
+
+// getConfigPath returns portage.json in the config directory (xdg.go)
 func getConfigPath() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".portage.json")
+	return filepath.Join(configDir(), "portage.json")
 }
 
 func loadConfig() *Config {
@@ -27,7 +67,7 @@ func loadConfig() *Config {
 		HiddenPorts: make(map[string]bool),
 	}
 
-	data, err := os.ReadFile(getConfigPath())
+	data, err := os.ReadFile(readPath(getConfigPath()))
 	if err != nil {
 		return config
 	}
@@ -37,6 +77,9 @@ func loadConfig() *Config {
 }
 
 func (c *Config) save() error {
+	if readOnly {
+		return errReadOnly
+	}
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
 		return err
@@ -45,33 +88,360 @@ func (c *Config) save() error {
 }
 
 type model struct {
-	ports    []PortInfo
-	cursor   int
-	config   *Config
-	message  string
-	showAll  bool
+	ports       []PortInfo
+	cursor      int
+	config      *Config
+	message     string
+	showAll     bool
+	orphansOnly bool
+	family      string // command family shown alone, picked with N/Y/D or cycled with F (commandfilter.go)
+	showDaemons bool   // tooling daemons (daemons.go) are folded unless toggled with d
+	sortBy      string // one of interactiveSortOrders, cycled with s (tuisort.go)
+	width       int    // terminal size from the last WindowSizeMsg (0 until known)
+	height      int
+	marked      map[string]bool // "port-pid" keys selected with space for bulk actions
+
+	// Unpublished container ports are listed below the ports; the cursor moves on to
+	// them past the last port
+	containerPorts []containerPort
+
+	shellHistory []shellCommand // for LastCommand on rescans (--shell-history)
+	lastRefresh  time.Time
+	refreshing   bool
+	scanTime     time.Duration     // how long the last scan took, for the status bar
+	checks       map[string]string // --check results by "port-pid", redone every --check-interval (httpcheck.go)
+
+	// Workspaces, Claude, History and Log views (tabs.go); cursor belongs to the current one
+	tab          int
+	tabCursors   [5]int
+	tabs         map[int]tabData
+	tabQuery     string // / search of the current view
+	tabSearching bool   // the search input is open
+	claudeTick   bool   // a reload of the Claude view is scheduled (claudemonitor.go)
+
+	log        *logView       // tail of the selected server's log while open (logtail.go)
+	inspect    *inspectView   // raw ps and lsof output opened with i (inspect.go)
+	killPick   *killPicker    // signal picker opened with K (signals.go)
+	confirm    *confirmPrompt // y/n question before a destructive action (confirm.go)
+	hidePick   *hidePicker    // hide menu opened with h (hiderules.go)
+	snoozePick *snoozePicker  // snooze menu opened with H (snooze.go)
+	note       *noteEditor    // note input opened with n (notes.go)
+	export     *exportPrompt  // export path input opened with E (export.go)
+	jump       *jumpPrompt    // port number input opened with : or g (jumpport.go)
+	killed     []PortInfo     // processes killed in this session, newest last, for U (undo.go)
+	columnPick *columnPicker  // column menu opened with v (columns.go)
+	showHelp   bool           // keybinding overlay opened with ? (help.go)
+}
+
+// clockTickMsg fires every second to update the "refreshed Ns ago" footer and start rescans
+type clockTickMsg time.Time
+
+// publishedMsg reports the outcome of publishing a container port
+type publishedMsg struct {
+	port containerPort
+	err  error
+}
+
+// restartedMsg reports the outcome of the restart action
+type restartedMsg struct {
+	port    PortInfo
+	logPath string
+	err     error
+}
+
+// portsRefreshedMsg carries the result of a background rescan
+type portsRefreshedMsg struct {
+	ports          []PortInfo
+	containerPorts []containerPort
+	took           time.Duration
+	err            error
+}
+
+func clockTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return clockTickMsg(t)
+	})
+}
+
+// prepareInteractivePorts sorts by uptime (longest first) and adds the per-port details
+// interactive mode shows for the selected row
+func prepareInteractivePorts(ports []PortInfo, shellHistory []shellCommand) {
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i].UptimeSeconds > ports[j].UptimeSeconds
+	})
+	resolvePortTerminals(ports)
+	resolveLastCommands(ports, shellHistory)
+	resolveCPUUsage(ports)
+	resolveGitBranches(ports)
+	if showBrowserTabs {
+		resolveBrowserTabs(ports)
+	}
+}
+
+// rescanPorts runs a full scan off the UI goroutine
+func (m model) rescanPorts() tea.Cmd {
+	shellHistory := m.shellHistory
+	return func() tea.Msg {
+		start := time.Now()
+		ports, err := scanPorts()
+		if err == nil {
+			prepareInteractivePorts(ports, shellHistory)
+		}
+		return portsRefreshedMsg{ports: ports, containerPorts: listUnpublishedContainerPorts(), took: time.Since(start), err: err}
+	}
 }
 
 func initialModel(ports []PortInfo) model {
+	config := loadConfig()
+	sortInteractivePorts(ports, initialSortOrder(), config)
 	return model{
-		ports:   ports,
-		cursor:  0,
-		config:  loadConfig(),
-		showAll: false,
+		ports:       ports,
+		cursor:      0,
+		config:      config,
+		showAll:     showAllPorts,
+		orphansOnly: showOrphans,
+		showDaemons: showDaemons,
+		sortBy:      initialSortOrder(),
+		marked:      make(map[string]bool),
+		tabs:        make(map[int]tabData),
+		lastRefresh: time.Now(),
+	}
+}
+
+// initialSortOrder is --sort if it names an interactive sort order, uptime otherwise
+func initialSortOrder() string {
+	for _, order := range interactiveSortOrders {
+		if order == sortBy {
+			return order
+		}
 	}
+	return "uptime"
 }
 
 func (m model) Init() tea.Cmd {
-	return nil
+	var cmds []tea.Cmd
+	if refreshInterval > 0 {
+		cmds = append(cmds, clockTick())
+	}
+	if httpCheck {
+		cmds = append(cmds, m.runChecks())
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.log != nil {
+		switch msg.(type) {
+		case tea.KeyMsg, logTickMsg:
+			return m.updateLog(msg)
+		}
+	}
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.inspect != nil {
+		return m.updateInspect(keyMsg)
+	}
+
 	switch msg := msg.(type) {
+	case clockTickMsg:
+		if !m.refreshing && time.Since(m.lastRefresh) >= refreshInterval {
+			m.refreshing = true
+			return m, tea.Batch(clockTick(), m.rescanPorts())
+		}
+		return m, clockTick()
+
+	case portsRefreshedMsg:
+		m.refreshing = false
+		m.lastRefresh = time.Now()
+		m.scanTime = msg.took
+		if msg.err != nil {
+			m.message = fmt.Sprintf("Refresh failed: %v", msg.err)
+			return m, nil
+		}
+
+		// Keep the selection on the same listener if it's still there, even while
+		// another view is shown
+		cursor := &m.cursor
+		if m.tab != tabPorts {
+			cursor = &m.tabCursors[tabPorts]
+		}
+		var selectedKey string
+		if visible := m.getVisiblePorts(); *cursor < len(visible) {
+			selectedKey = fmt.Sprintf("%d-%s", visible[*cursor].Port, visible[*cursor].PID)
+		}
+		m.ports = msg.ports
+		sortInteractivePorts(m.ports, m.sortBy, m.config)
+		m.containerPorts = msg.containerPorts
+		*cursor = 0
+		for i, port := range m.getVisiblePorts() {
+			if fmt.Sprintf("%d-%s", port.Port, port.PID) == selectedKey {
+				*cursor = i
+				break
+			}
+		}
+
+		// Drop marks of listeners that went away
+		stillMarked := make(map[string]bool)
+		for _, port := range m.ports {
+			key := fmt.Sprintf("%d-%s", port.Port, port.PID)
+			if m.marked[key] {
+				stillMarked[key] = true
+			}
+		}
+		m.marked = stillMarked
+
+	case checkTickMsg:
+		return m, m.runChecks()
+
+	case checksDoneMsg:
+		m.checks = msg
+		return m, checkTick()
+
+	case tabLoadedMsg:
+		m.tabs[msg.tab] = msg.data
+		if rows := m.tabRows(); m.tab == msg.tab && m.cursor >= len(rows) {
+			m.cursor = max(len(rows)-1, 0)
+		}
+		// The Claude view keeps reloading while it's shown, one tick at a time
+		if msg.tab == tabClaude && m.tab == tabClaude && !m.claudeTick {
+			m.claudeTick = true
+			return m, claudeTick()
+		}
+
+	case claudeTickMsg:
+		m.claudeTick = false
+		if m.tab == tabClaude {
+			return m, loadTab(tabClaude)
+		}
+
+	case publishedMsg:
+		if msg.err != nil {
+			m.message = fmt.Sprintf("Failed to publish %s:%d: %v", msg.port.Container, msg.port.Port, msg.err)
+			return m, nil
+		}
+		m.message = fmt.Sprintf("Published %s:%d on localhost:%d (stop the portage-publish container to undo)", msg.port.Container, msg.port.Port, msg.port.Port)
+		if !m.refreshing {
+			m.refreshing = true
+			return m, m.rescanPorts()
+		}
+
+	case launchedMsg:
+		switch {
+		case msg.err != nil:
+			m.message = fmt.Sprintf("Failed to launch %s in %s: %v", msg.command, shortenPath(msg.dir), msg.err)
+		case msg.port != nil:
+			m.message = fmt.Sprintf("Started %s in %s: listening on port %d", msg.command, shortenPath(msg.dir), msg.port.Port)
+			if project := findProjectFile(msg.dir); project != nil && project.err == nil && !project.expects(msg.port.Port) {
+				m.message += fmt.Sprintf(", expected %s", project.describePorts())
+			}
+		default:
+			m.message = fmt.Sprintf("Started %s in %s", msg.command, shortenPath(msg.dir))
+		}
+		if msg.logPath != "" {
+			m.message += fmt.Sprintf(" (output in %s)", msg.logPath)
+		}
+		if msg.port != nil && !m.refreshing {
+			m.refreshing = true
+			return m, m.rescanPorts()
+		}
+
+	case restartedMsg:
+		if msg.err != nil {
+			m.message = fmt.Sprintf("Failed to restart %s: %v", msg.port.Command, msg.err)
+			return m, nil
+		}
+		m.message = fmt.Sprintf("Restarted %s on port %d", msg.port.Command, msg.port.Port)
+		if msg.logPath != "" {
+			m.message += fmt.Sprintf(" (output in %s)", msg.logPath)
+		}
+		// Pick up the new PID and uptime
+		if !m.refreshing {
+			m.refreshing = true
+			return m, m.rescanPorts()
+		}
+
+	case relaunchedMsg:
+		return m.handleRelaunched(msg)
+
+	case tea.WindowSizeMsg:
+		// Re-layout on resize; bubbletea also sends this after resuming from suspend
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.ResumeMsg:
+		// Back from ctrl+z: the terminal may have been resized while we were stopped
+		m.width = 0
+		m.height = 0
+		return m, tea.WindowSize()
+
+	case escalatedMsg:
+		m = m.recordKills(msg.stopped)
+		switch {
+		case msg.failed > 0:
+			m.message = fmt.Sprintf("Stopping %s: %d exited, %d needed KILL, %d failed", msg.target, msg.exited, msg.forced, msg.failed)
+		case msg.forced > 0:
+			m.message = fmt.Sprintf("Stopped %s: %d ignored TERM and got KILL", msg.target, msg.forced)
+		default:
+			m.message = fmt.Sprintf("Stopped %s with TERM", msg.target)
+		}
+		if !m.refreshing {
+			m.refreshing = true
+			return m, m.rescanPorts()
+		}
+
 	case tea.KeyMsg:
+		if m.note != nil {
+			return m.updateNoteEditor(msg)
+		}
+		if m.export != nil {
+			return m.updateExportPrompt(msg)
+		}
+		if m.jump != nil {
+			return m.updateJumpPrompt(msg)
+		}
+		if m.tabSearching {
+			return m.updateTabSearch(msg)
+		}
+		if m.confirm != nil {
+			return m.updateConfirm(msg)
+		}
+		if m.killPick != nil {
+			return m.updateKillPicker(msg)
+		}
+		if m.hidePick != nil {
+			return m.updateHidePicker(msg)
+		}
+		if m.snoozePick != nil {
+			return m.updateSnoozePicker(msg)
+		}
+		if m.columnPick != nil {
+			return m.updateColumnPicker(msg)
+		}
+		if m.showHelp {
+			return m.updateHelp(msg)
+		}
+		if msg.String() == "?" {
+			m.showHelp = true
+			return m, nil
+		}
+		if tab, ok := tabForKey(msg.String(), m.tab); ok {
+			return m.switchTab(tab)
+		}
+		if m.tab != tabPorts {
+			switch msg.String() {
+			case "ctrl+c", "q", "ctrl+z":
+				// Shared with the ports view below
+			default:
+				return m.updateTab(msg)
+			}
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
 
+		case "ctrl+z":
+			// Leave the alt screen and stop; bubbletea restores everything on SIGCONT (fg)
+			return m, tea.Suspend
+
 		case "up", "k":
 			if m.cursor > 0 {
 				m.cursor--
@@ -79,29 +449,134 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "down", "j":
 			visiblePorts := m.getVisiblePorts()
-			if m.cursor < len(visiblePorts)-1 {
+			if m.cursor < len(visiblePorts)+len(m.containerPorts)-1 {
 				m.cursor++
 			}
 
-		case "h":
-			// Hide selected port
+		case "p":
+			// Pin or unpin the selected port's project (or the port itself)
+			visiblePorts := m.getVisiblePorts()
+			if m.cursor < len(visiblePorts) {
+				port := visiblePorts[m.cursor]
+				what := fmt.Sprintf("port %d", port.Port)
+				if root := pinnableProject(port); root != "" && !containsInt(m.config.PinnedPorts, port.Port) {
+					what = shortenPath(root)
+				}
+				pinned := m.config.togglePin(port)
+				m.config.save()
+				if pinned {
+					m.message = "Pinned " + what
+				} else {
+					m.message = "Unpinned " + what
+				}
+				if readOnly {
+					m.message += " for this session (read-only)"
+				}
+				sortInteractivePorts(m.ports, m.sortBy, m.config)
+				for i, visible := range m.getVisiblePorts() {
+					if visible.Port == port.Port && visible.PID == port.PID {
+						m.cursor = i
+						break
+					}
+				}
+			}
+
+		case "P":
+			// Publish the selected container port on localhost
+			index := m.cursor - len(m.getVisiblePorts())
+			if index >= 0 && index < len(m.containerPorts) {
+				cp := m.containerPorts[index]
+				m.message = fmt.Sprintf("Publishing %s:%d…", cp.Container, cp.Port)
+				return m, func() tea.Msg {
+					return publishedMsg{port: cp, err: publishContainerPort(cp)}
+				}
+			}
+
+		case " ":
+			// Mark or unmark the selected port for bulk actions and move on
 			visiblePorts := m.getVisiblePorts()
 			if len(visiblePorts) > 0 && m.cursor < len(visiblePorts) {
 				port := visiblePorts[m.cursor]
 				key := fmt.Sprintf("%d-%s", port.Port, port.PID)
-				m.config.HiddenPorts[key] = true
-				m.config.save()
-				m.message = fmt.Sprintf("Hidden port %d (PID %s)", port.Port, port.PID)
+				if m.marked[key] {
+					delete(m.marked, key)
+				} else {
+					m.marked[key] = true
+				}
+				if m.cursor < len(visiblePorts)-1 {
+					m.cursor++
+				}
+			}
+
+		case "esc":
+			// Clear all marks
+			if len(m.marked) > 0 {
+				m.marked = make(map[string]bool)
+				m.message = "Cleared selection"
+			}
 
-				// Adjust cursor if needed
-				if m.cursor >= len(m.getVisiblePorts()) && m.cursor > 0 {
-					m.cursor--
+		case "h":
+			// Hide marked ports, or the selected one, picking what to hide
+			if targets := m.actionTargets(); len(targets) > 0 {
+				m.hidePick = &hidePicker{targets: targets}
+			}
+
+		case "n":
+			// Attach a note to the selected port's project
+			m = m.openNoteEditor()
+
+		case "H":
+			// Snooze marked ports, or the selected one, for a while
+			if targets := m.actionTargets(); len(targets) > 0 {
+				m.snoozePick = &snoozePicker{targets: targets}
+			}
+
+		case "s":
+			// Cycle the sort order, keeping the selection on the same listener
+			var selectedKey string
+			if visible := m.getVisiblePorts(); m.cursor < len(visible) {
+				selectedKey = fmt.Sprintf("%d-%s", visible[m.cursor].Port, visible[m.cursor].PID)
+			}
+			m.sortBy = nextSortOrder(m.sortBy)
+			sortInteractivePorts(m.ports, m.sortBy, m.config)
+			for i, port := range m.getVisiblePorts() {
+				if fmt.Sprintf("%d-%s", port.Port, port.PID) == selectedKey {
+					m.cursor = i
+					break
 				}
 			}
+			m.message = ""
+
+		case "v":
+			// Choose the columns of the list
+			m.columnPick = &columnPicker{}
+
+		case "w":
+			// Save marked ports, or the selected one, as JSON in the current directory
+			targets := m.actionTargets()
+			if len(targets) > 0 {
+				path, err := exportPorts(targets)
+				if err != nil {
+					m.message = fmt.Sprintf("Failed to export: %v", err)
+				} else {
+					m.message = fmt.Sprintf("Exported %d port(s) to %s", len(targets), path)
+					m.marked = make(map[string]bool)
+				}
+			}
+
+		case ":", "g":
+			// Jump to a port by number
+			m.jump = &jumpPrompt{}
+
+		case "E":
+			// Export the listed rows, as filtered and sorted, to a file of your choosing
+			m.export = &exportPrompt{text: []rune(defaultExportPath())}
 
 		case "u":
 			// Unhide all
 			m.config.HiddenPorts = make(map[string]bool)
+			*m.config.hideRules() = nil
+			m.config.Snoozed = nil
 			m.config.save()
 			m.message = "Unhidden all ports"
 			m.cursor = 0
@@ -109,59 +584,126 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "a":
 			// Toggle show all ports
 			m.showAll = !m.showAll
-			if m.showAll {
-				m.message = "Showing ALL ports"
-			} else {
-				m.message = "Showing filtered ports (3000+, 4000+, 8000+)"
+			m.message = ""
+			m.cursor = 0
+
+		case "d":
+			// Unfold or fold tooling daemons (nx, turbo, pnpm, ...)
+			m.showDaemons = !m.showDaemons
+			m.message = ""
+			m.cursor = 0
+
+		case "O":
+			// Toggle orphaned-only view
+			m.orphansOnly = !m.orphansOnly
+			m.message = ""
+			m.cursor = 0
+
+		case "N", "Y", "D":
+			// Show only node, python or docker listeners; the same key again shows all
+			family, _ := commandFamilyForKey(msg.String())
+			if m.family == family {
+				family = ""
 			}
+			m.family = family
+			m.message = ""
+			m.cursor = 0
+
+		case "F":
+			// Cycle the command family filter
+			m.family = nextCommandFamily(m.family)
+			m.message = ""
 			m.cursor = 0
 
+		case "X":
+			// Kill every visible orphaned listener
+			orphans := 0
+			for _, port := range m.getVisiblePorts() {
+				if port.Orphaned {
+					orphans++
+				}
+			}
+			if orphans == 0 {
+				m.message = "No orphaned listeners to kill"
+				return m, nil
+			}
+			return m.confirmThen(fmt.Sprintf("Kill %d orphaned listeners?", orphans), func(m model) (tea.Model, tea.Cmd) {
+				return m.killOrphans(), nil
+			})
+
 		case "K":
-			// Kill process (capital K for safety)
+			// Pick a signal for the marked processes, or the selected one (capital K for safety)
+			if targets := m.actionTargets(); len(targets) > 0 {
+				m.killPick = &killPicker{targets: targets}
+			}
+
+		case "ctrl+k":
+			// Kill every listener of the selected port's project
+			return m.killProject()
+
+		case "r":
+			// Restart: stop the process and run its command line again in the same directory
 			visiblePorts := m.getVisiblePorts()
 			if len(visiblePorts) > 0 && m.cursor < len(visiblePorts) {
 				port := visiblePorts[m.cursor]
-				cmd := exec.Command("kill", port.PID)
-				err := cmd.Run()
-				if err != nil {
-					m.message = fmt.Sprintf("Failed to kill PID %s: %v", port.PID, err)
-				} else {
-					m.message = fmt.Sprintf("Killed process %s (PID %s)", port.Command, port.PID)
-					// Remove from list
-					m.ports = removePort(m.ports, port)
-					if m.cursor >= len(m.getVisiblePorts()) && m.cursor > 0 {
-						m.cursor--
+				question := fmt.Sprintf("Restart %s (PID %s) on port %d?", port.Command, port.PID, port.Port)
+				return m.confirmThen(question, func(m model) (tea.Model, tea.Cmd) {
+					m.message = fmt.Sprintf("Restarting %s (PID %s)…", port.Command, port.PID)
+					return m, func() tea.Msg {
+						logPath, err := restartProcess(port)
+						return restartedMsg{port: port, logPath: logPath, err: err}
 					}
-				}
+				})
+			}
+
+		case "U":
+			// Undo the last kill by starting the process again
+			return m.undoKill()
+
+		case "l":
+			// Tail the server's log without leaving portage
+			visiblePorts := m.getVisiblePorts()
+			if len(visiblePorts) > 0 && m.cursor < len(visiblePorts) {
+				return m.openLogView(visiblePorts[m.cursor])
+			}
+
+		case "i":
+			// Raw ps and lsof output of the process
+			visiblePorts := m.getVisiblePorts()
+			if len(visiblePorts) > 0 && m.cursor < len(visiblePorts) {
+				return m.openInspectView(visiblePorts[m.cursor]), nil
 			}
 
 		case "o", "enter":
 			// Open port URL in browser
 			visiblePorts := m.getVisiblePorts()
 			if len(visiblePorts) > 0 && m.cursor < len(visiblePorts) {
-				port := visiblePorts[m.cursor]
+				m.message = openPort(visiblePorts[m.cursor], m.config)
+			}
 
-				// Use the actual address if it contains IP:port, otherwise use localhost
-				var url string
-				if strings.Contains(port.Address, ":") {
-					// Address already has format like "127.0.0.1:8000" or "*:3000"
-					addr := port.Address
-					if strings.HasPrefix(addr, "*:") {
-						url = fmt.Sprintf("http://localhost:%d", port.Port)
-					} else {
-						url = fmt.Sprintf("http://%s", addr)
+		case "c", "C", "y":
+			// Copy the URL, path or PID of the selected port
+			visiblePorts := m.getVisiblePorts()
+			if len(visiblePorts) > 0 && m.cursor < len(visiblePorts) {
+				port := visiblePorts[m.cursor]
+				var what, text string
+				switch msg.String() {
+				case "c":
+					what, _ = resolveOpenTarget(port, m.config)
+					text = what
+				case "C":
+					if port.Path == "N/A" || port.Path == "/" {
+						m.message = "No path available to copy"
+						return m, nil
 					}
-				} else {
-					url = fmt.Sprintf("http://localhost:%d", port.Port)
+					what, text = shortenPath(port.Path), port.Path
+				case "y":
+					what, text = "PID "+port.PID, port.PID
 				}
-
-				// Use 'open' command on macOS
-				cmd := exec.Command("open", url)
-				err := cmd.Run()
-				if err != nil {
-					m.message = fmt.Sprintf("Failed to open %s: %v", url, err)
+				if err := copyToClipboard(text); err != nil {
+					m.message = fmt.Sprintf("Failed to copy: %v", err)
 				} else {
-					m.message = fmt.Sprintf("Opened %s in browser", url)
+					m.message = fmt.Sprintf("Copied %s", what)
 				}
 			}
 
@@ -169,37 +711,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Open path in Finder
 			visiblePorts := m.getVisiblePorts()
 			if len(visiblePorts) > 0 && m.cursor < len(visiblePorts) {
-				port := visiblePorts[m.cursor]
-				if port.Path != "N/A" && port.Path != "/" {
-					cmd := exec.Command("open", port.Path)
-					err := cmd.Run()
-					if err != nil {
-						m.message = fmt.Sprintf("Failed to open in Finder: %v", err)
-					} else {
-						m.message = fmt.Sprintf("Opened %s in Finder", shortenPath(port.Path))
-					}
-				} else {
-					m.message = "No path available to open"
-				}
+				m.message = openInFinder(visiblePorts[m.cursor].Path)
 			}
 
 		case "e":
 			// Open path in editor
 			visiblePorts := m.getVisiblePorts()
 			if len(visiblePorts) > 0 && m.cursor < len(visiblePorts) {
-				port := visiblePorts[m.cursor]
-				if port.Path != "N/A" && port.Path != "/" {
-					editor := getEditor()
-					cmd := exec.Command(editor, port.Path)
-					err := cmd.Start() // Use Start() to not block
-					if err != nil {
-						m.message = fmt.Sprintf("Failed to open in %s: %v", editor, err)
-					} else {
-						m.message = fmt.Sprintf("Opened %s in %s", shortenPath(port.Path), editor)
-					}
-				} else {
-					m.message = "No path available to open"
-				}
+				m.message = openInEditor(visiblePorts[m.cursor].Path, visiblePorts[m.cursor].Port)
+			}
+
+		case "t":
+			// Open a terminal in the project directory
+			visiblePorts := m.getVisiblePorts()
+			if len(visiblePorts) > 0 && m.cursor < len(visiblePorts) {
+				m.message = openInTerminal(visiblePorts[m.cursor].Path, m.config.terminalCommand())
 			}
 		}
 	}
@@ -207,16 +733,97 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// getEditor returns the editor command to use
-// Priority: PORTAGE_EDITOR > EDITOR > "cursor" (default)
-func getEditor() string {
-	if editor := os.Getenv("PORTAGE_EDITOR"); editor != "" {
-		return editor
+// actionTargets returns the visible marked ports, or just the selected one when
+// nothing is marked
+func (m model) actionTargets() []PortInfo {
+	visiblePorts := m.getVisiblePorts()
+	var targets []PortInfo
+	for _, port := range visiblePorts {
+		if m.marked[fmt.Sprintf("%d-%s", port.Port, port.PID)] {
+			targets = append(targets, port)
+		}
+	}
+	if len(targets) == 0 && m.cursor < len(visiblePorts) {
+		targets = append(targets, visiblePorts[m.cursor])
+	}
+	return targets
+}
+
+// hidePorts hides ports with a rule or their port-PID key, depending on the hide menu
+// choice (for the session only in read-only mode), and clears the marks
+func (m model) hidePorts(targets []PortInfo, choice int) model {
+	if len(targets) == 0 {
+		return m
+	}
+	for _, port := range targets {
+		if rule, ok := hideRuleFor(choice, port); ok {
+			m.config.addHideRule(rule)
+		} else {
+			m.config.HiddenPorts[fmt.Sprintf("%d-%s", port.Port, port.PID)] = true
+		}
 	}
-	if editor := os.Getenv("EDITOR"); editor != "" {
-		return editor
+	m.config.HideRulesMigrated = true
+	m.config.save()
+	m.marked = make(map[string]bool)
+	m.message = "Hidden " + hideChoiceLabels(targets)[choice]
+	if readOnly {
+		m.message += " for this session (read-only)"
 	}
-	return "cursor" // Default to Cursor
+
+	// Adjust cursor if needed
+	if visible := len(m.getVisiblePorts()); m.cursor >= visible {
+		m.cursor = max(visible-1, 0)
+	}
+	return m
+}
+
+// killOrphans kills every visible orphaned listener
+func (m model) killOrphans() model {
+	var killed, failed int
+	for _, port := range m.getVisiblePorts() {
+		if !port.Orphaned {
+			continue
+		}
+		if err := killProcess(port.PID); err != nil {
+			failed++
+			continue
+		}
+		killed++
+		m.ports = removePort(m.ports, port)
+		m = m.recordKills([]PortInfo{port})
+	}
+	if failed > 0 {
+		m.message = fmt.Sprintf("Killed %d orphaned listeners, %d failed", killed, failed)
+	} else {
+		m.message = fmt.Sprintf("Killed %d orphaned listeners", killed)
+	}
+	if m.cursor >= len(m.getVisiblePorts()) {
+		m.cursor = 0
+	}
+	return m
+}
+
+// openInFinder opens a directory in Finder and returns the status message
+func openInFinder(path string) string {
+	if path == "" || path == "N/A" || path == "/" {
+		return "No path available to open"
+	}
+	if err := exec.Command("open", path).Run(); err != nil {
+		return fmt.Sprintf("Failed to open in Finder: %v", err)
+	}
+	return fmt.Sprintf("Opened %s in Finder", shortenPath(path))
+}
+
+// openInEditor opens a project in its editor (editor.go); port is 0 outside the Ports view
+func openInEditor(path string, port int) string {
+	if path == "" || path == "N/A" || path == "/" {
+		return "No path available to open"
+	}
+	editor, err := startEditor(path, port)
+	if err != nil {
+		return fmt.Sprintf("Failed to open in %s: %v", editor, err)
+	}
+	return fmt.Sprintf("Opened %s in %s", shortenPath(path), editor)
 }
 
 func removePort(ports []PortInfo, toRemove PortInfo) []PortInfo {
@@ -231,19 +838,30 @@ func removePort(ports []PortInfo, toRemove PortInfo) []PortInfo {
 
 func (m model) getVisiblePorts() []PortInfo {
 	var visible []PortInfo
+	ranges := portRanges() // [ranges] starts, as in the table
 	for _, port := range m.ports {
-		key := fmt.Sprintf("%d-%s", port.Port, port.PID)
-		if !m.config.HiddenPorts[key] {
+		if m.orphansOnly && !port.Orphaned {
+			continue
+		}
+		if !matchesCommandFamily(port, m.family) {
+			continue
+		}
+		if port.Daemon != "" && !m.showDaemons {
+			continue
+		}
+		if !m.showAll && foldsOtherPorts() && !loadSettings().Filters.includesPath(port.Path) {
+			continue // a shows them along with every range
+		}
+		if !isUnderPathFilter(port.Path) {
+			continue
+		}
+		if !matchesUserFilter(port) || !matchesRegexFilter(port) || !matchesPortFilter(port) {
+			continue
+		}
+		if !m.config.isHidden(port) {
 			// Filter by range if not showing all
-			if m.showAll {
+			if m.showAll || inPortRanges(port.Port, ranges) {
 				visible = append(visible, port)
-			} else {
-				// Only show 3000+, 4000+, 8000+
-				if (port.Port >= 3000 && port.Port < 4000) ||
-					(port.Port >= 4000 && port.Port < 5000) ||
-					(port.Port >= 8000 && port.Port < 9000) {
-					visible = append(visible, port)
-				}
 			}
 		}
 	}
@@ -251,25 +869,33 @@ func (m model) getVisiblePorts() []PortInfo {
 }
 
 func (m model) View() string {
+	if m.log != nil {
+		return m.viewLog()
+	}
+	if m.inspect != nil {
+		return m.viewInspect()
+	}
+	if m.showHelp {
+		return m.viewHelp()
+	}
+
 	titleStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("cyan")).
+		Foreground(ui.Accent).
 		MarginBottom(1)
 
 	headerStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("cyan"))
+		Foreground(ui.Accent)
 
-	selectedStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("240")).
-		Foreground(lipgloss.Color("white"))
+	selectedStyle := ui.selectedStyle()
 
 	messageStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("yellow")).
+		Foreground(ui.Warning).
 		MarginTop(1)
 
 	helpStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("244")).
+		Foreground(ui.Muted).
 		MarginTop(1)
 
 	var s strings.Builder
@@ -278,22 +904,82 @@ func (m model) View() string {
 	if m.showAll {
 		title += " [ALL PORTS]"
 	}
+	if m.orphansOnly {
+		title += " [ORPHANS]"
+	}
+	if m.family != "" {
+		title += " [" + strings.ToUpper(m.family) + "]"
+	}
+	if readOnly {
+		title += " [READ-ONLY]"
+	}
+	if activeProfile != "" {
+		title += " [PROFILE " + strings.ToUpper(activeProfile) + "]"
+	}
 	s.WriteString(titleStyle.Render(title))
 	s.WriteString("\n\n")
+	s.WriteString(m.viewTabBar())
+	s.WriteString("\n\n")
 
-	// Get terminal width and calculate path column width
-	// Fixed columns: PORT(6) + COMMAND(16) + PID(8) + UPTIME(8) + ADDRESS(18) + spaces(5) = 61
-	termWidth := getTerminalWidth()
-	fixedWidth := 61
+	// Get terminal width (kept current by WindowSizeMsg) and calculate path column width
+	// Fixed columns: mark and pin(3) + the enabled columns and a space after each;
+	// the defaults (PORT, COMMAND, PID, UPTIME, ADDRESS) add up to 64. On narrow
+	// terminals COMMAND, ADDRESS and BRANCH give up space before the path does.
+	termWidth := m.width
+	if termWidth == 0 {
+		termWidth = getTerminalWidth()
+	}
+	var shownColumns []portColumn
+	for _, column := range portColumns {
+		if m.config.showsColumn(column.name) {
+			shownColumns = append(shownColumns, column)
+		}
+	}
+	prefixWidth := 3 // mark and pin, and the --check dot
+	if httpCheck {
+		prefixWidth = 4
+	}
+	shownColumns = fitColumns(shownColumns, termWidth, prefixWidth+2)
+	fixedWidth := prefixWidth
+	for _, column := range shownColumns {
+		fixedWidth += column.width + 1
+	}
 	pathWidth := termWidth - fixedWidth - 2 // -2 for padding
-	if pathWidth < 20 {
-		pathWidth = 20 // Minimum width
+	if pathWidth < minPathWidth {
+		pathWidth = minPathWidth
 	}
 	totalWidth := fixedWidth + pathWidth
+	if !m.config.showsColumn("path") {
+		totalWidth = fixedWidth
+	}
+
+	if m.tab != tabPorts {
+		s.WriteString(m.viewTab(termWidth))
+		return s.String()
+	}
 
 	// Header
-	header := headerStyle.Render(fmt.Sprintf("%-6s %-16s %-8s %-8s %-18s %s",
-		"PORT", "COMMAND", "PID", "UPTIME", "ADDRESS", "PATH"))
+	// The sorted-by column gets an arrow; sorting by CPU without a CPU% column shows
+	// CPU% in place of uptime
+	sorted := m.sortBy
+	cpuInUptime := sorted == "cpu" && !m.config.showsColumn("cpu")
+	if cpuInUptime {
+		sorted = "uptime"
+	}
+	titles := make([]string, len(shownColumns))
+	for i, column := range shownColumns {
+		titles[i] = column.title
+		if cpuInUptime && column.name == "uptime" {
+			titles[i] = "CPU%"
+		}
+		if column.name == sorted {
+			titles[i] += " " + sortIndicator(m.sortBy)
+		}
+		if column.width > 0 {
+			titles[i] = fmt.Sprintf("%-*s", column.width, titles[i])
+		}
+	}
+	header := headerStyle.Render(strings.TrimRight(strings.Repeat(" ", prefixWidth)+strings.Join(titles, " "), " "))
 	s.WriteString(header)
 	s.WriteString("\n")
 	s.WriteString(strings.Repeat("─", totalWidth))
@@ -304,19 +990,57 @@ func (m model) View() string {
 	if len(visiblePorts) == 0 {
 		s.WriteString("No ports to display\n")
 	} else {
-		for i, port := range visiblePorts {
+		start, end := m.visibleRange(len(visiblePorts))
+		if start > 0 {
+			s.WriteString(helpStyle.UnsetMarginTop().Render(fmt.Sprintf("  ↑ %d more", start)))
+			s.WriteString("\n")
+		}
+		for i := start; i < end; i++ {
+			port := visiblePorts[i]
 			pathDisplay := shortenPath(port.Path)
 			if pathDisplay == "N/A" {
 				pathDisplay = "-"
 			}
+			if port.Orphaned {
+				pathDisplay += " (missing)"
+			}
+			// The path keeps its last segments; a note gets whatever room is left
+			pathDisplay = elidePath(pathDisplay, pathWidth)
+			if note := m.config.noteFor(port.Path); note != "" {
+				if room := pathWidth - len([]rune(pathDisplay)) - 4; room >= 8 {
+					pathDisplay += "  # " + truncate(note, room)
+				}
+			}
 
-			line := fmt.Sprintf("%-6d %-16s %-8s %-8s %-18s %s",
-				port.Port,
-				truncate(port.Command, 16),
-				truncate(port.PID, 8),
-				truncate(port.Uptime, 8),
-				truncate(port.Address, 18),
-				truncate(pathDisplay, pathWidth))
+			mark := " "
+			if m.marked[fmt.Sprintf("%d-%s", port.Port, port.PID)] {
+				mark = "●"
+			}
+			pin := " "
+			if m.config.isPinnedPort(port) {
+				pin = "★"
+			}
+
+			cells := make([]string, len(shownColumns))
+			for j, column := range shownColumns {
+				switch {
+				case column.name == "path":
+					cells[j] = pathDisplay
+				case column.name == "uptime" && cpuInUptime:
+					cells[j] = fmt.Sprintf("%-*s", column.width, portCell(port, "cpu"))
+				case column.name == "address":
+					cells[j] = fmt.Sprintf("%-*s", column.width, fitAddress(port.Address, column.width))
+				default:
+					cells[j] = fmt.Sprintf("%-*s", column.width, truncate(portCell(port, column.name), column.width))
+				}
+			}
+			prefix := mark + pin
+			if httpCheck {
+				key := fmt.Sprintf("%d-%s", port.Port, port.PID)
+				_, checked := m.checks[key]
+				prefix += checkDot(m.checks[key], checked, i != m.cursor)
+			}
+			line := strings.TrimRight(prefix+" "+strings.Join(cells, " "), " ")
 
 			if i == m.cursor {
 				line = selectedStyle.Render(line)
@@ -324,6 +1048,58 @@ func (m model) View() string {
 			s.WriteString(line)
 			s.WriteString("\n")
 		}
+		if end < len(visiblePorts) {
+			s.WriteString(helpStyle.UnsetMarginTop().Render(fmt.Sprintf("  ↓ %d more", len(visiblePorts)-end)))
+			s.WriteString("\n")
+		}
+	}
+
+	// Container ports localhost can't reach, selectable for P
+	if len(m.containerPorts) > 0 {
+		s.WriteString("\n")
+		s.WriteString(headerStyle.Render("NOT PUBLISHED (listening inside the container, localhost refuses) - P: publish"))
+		s.WriteString("\n")
+		for i, cp := range m.containerPorts {
+			line := fmt.Sprintf("  %-6d %-25s %s", cp.Port, truncate(cp.Container, 25), elidePath(shortenPath(cp.Project), pathWidth))
+			if m.cursor == len(visiblePorts)+i {
+				line = selectedStyle.Render(line)
+			}
+			s.WriteString(line)
+			s.WriteString("\n")
+		}
+	}
+
+	// Details of the selected process: full command line, and where it was started so
+	// it can be stopped there instead of killed
+	if m.cursor < len(visiblePorts) {
+		selected := visiblePorts[m.cursor]
+		if selected.CommandLine != "" {
+			s.WriteString(helpStyle.UnsetMarginTop().Render(truncate("$ "+selected.CommandLine, totalWidth)))
+			s.WriteString("\n")
+		}
+		if selected.BrowserTabs > 0 {
+			s.WriteString(helpStyle.UnsetMarginTop().Render(fmt.Sprintf("open in %d browser tab(s)", selected.BrowserTabs)))
+			s.WriteString("\n")
+		}
+		if selected.LastCommand != "" {
+			s.WriteString(helpStyle.UnsetMarginTop().Render(truncate("last run: "+selected.LastCommand, totalWidth)))
+			s.WriteString("\n")
+		}
+		if selected.Terminal != "" {
+			s.WriteString(helpStyle.UnsetMarginTop().Render("started in " + selected.Terminal))
+			s.WriteString("\n")
+		}
+		if note := m.config.noteFor(selected.Path); note != "" {
+			s.WriteString(messageStyle.UnsetMarginTop().Render(truncate("note: "+note, totalWidth)))
+			s.WriteString("\n")
+		}
+		// Where the project's listeners differ from its .portage.yml
+		if project := findProjectFile(selected.Path); project != nil {
+			for _, problem := range project.problems(m.ports) {
+				s.WriteString(messageStyle.UnsetMarginTop().Render(truncate("! "+problem, totalWidth)))
+				s.WriteString("\n")
+			}
+		}
 	}
 
 	// Message
@@ -333,18 +1109,69 @@ func (m model) View() string {
 		s.WriteString("\n")
 	}
 
-	// Help
+	// Help, or the confirmation or one of the pickers in its place
+	s.WriteString("\n")
+	switch {
+	case m.note != nil:
+		s.WriteString(m.viewNoteEditor())
+	case m.export != nil:
+		s.WriteString(m.viewExportPrompt())
+	case m.jump != nil:
+		s.WriteString(m.viewJumpPrompt())
+	case m.confirm != nil:
+		s.WriteString(m.viewConfirm())
+	case m.killPick != nil:
+		s.WriteString(m.viewKillPicker())
+	case m.hidePick != nil:
+		s.WriteString(m.viewHidePicker())
+	case m.snoozePick != nil:
+		s.WriteString(m.viewSnoozePicker())
+	case m.columnPick != nil:
+		s.WriteString(m.viewColumnPicker())
+	default:
+		s.WriteString(helpStyle.Width(termWidth).Render(
+			"1-5/tab: switch view • enter/o: open • t: terminal • space: mark • K: kill • r: restart • l: log • s: sort • a: toggle all • ?: all keys • q: quit"))
+	}
+
+	// Status bar: counts, filters, sort and scan time (statusbar.go)
 	s.WriteString("\n")
-	help := helpStyle.Render(
-		"enter/o: open in browser • f: Finder • e: editor • h: hide • u: unhide all • K: kill • a: toggle all • q: quit")
-	s.WriteString(help)
+	s.WriteString(m.viewStatusBar(visiblePorts, termWidth))
 
 	return s.String()
 }
 
-func runInteractive(ports []PortInfo) error {
-	p := tea.NewProgram(initialModel(ports))
+// visibleRange returns the slice of rows that fits the terminal height, scrolled so
+// the cursor stays on screen. Without a known height every row is shown.
+func (m model) visibleRange(total int) (int, int) {
+	// Title, tabs, header, divider, scroll markers, details, message, help and status bar take about 18 lines
+	rows := m.height - 18
+	if m.height == 0 || total <= rows {
+		return 0, total
+	}
+	if rows < 3 {
+		rows = 3
+	}
+
+	start := m.cursor - rows/2
+	if start < 0 {
+		start = 0
+	}
+	if start+rows > total {
+		start = total - rows
+	}
+	return start, start + rows
+}
+
+func runInteractive(ports []PortInfo, shellHistory []shellCommand, scanTime time.Duration) error {
+	m := initialModel(ports)
+	m.shellHistory = shellHistory
+	m.scanTime = scanTime
+	m.containerPorts = listUnpublishedContainerPorts()
+	p := tea.NewProgram(crashSafeModel{inner: m}, tea.WithAltScreen())
 	_, err := p.Run()
+	if errors.Is(err, tea.ErrProgramPanic) && lastCrashReport != "" {
+		fmt.Fprintf(os.Stderr, "Crash report saved to %s - please attach it to a bug report\n", lastCrashReport)
+	}
 	return err
 }
 
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// jumpPrompt is the open port number input of : and g
+type jumpPrompt struct {
+	digits []rune
+}
+
+// updateJumpPrompt handles keys while the port number input is open; only digits are taken
+func (m model) updateJumpPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		m.jump = nil
+
+	case tea.KeyEnter:
+		port, err := strconv.Atoi(string(m.jump.digits))
+		m.jump = nil
+		if err == nil {
+			m = m.jumpToPort(port)
+		}
+
+	case tea.KeyBackspace:
+		if len(m.jump.digits) > 0 {
+			m.jump.digits = m.jump.digits[:len(m.jump.digits)-1]
+		}
+
+	case tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if r >= '0' && r <= '9' && len(m.jump.digits) < 5 {
+				m.jump.digits = append(m.jump.digits, r)
+			}
+		}
+	}
+	return m, nil
+}
+
+// jumpToPort moves the cursor to the row of port, or says why there is none
+func (m model) jumpToPort(port int) model {
+	for i, visible := range m.getVisiblePorts() {
+		if visible.Port == port {
+			m.cursor = i
+			m.message = ""
+			return m
+		}
+	}
+	for _, listening := range m.ports {
+		if listening.Port == port {
+			m.message = fmt.Sprintf("Port %d (%s) is listening but filtered out of the list; a shows all ports, u unhides", port, listening.Command)
+			return m
+		}
+	}
+	m.message = fmt.Sprintf("Nothing is listening on port %d", port)
+	return m
+}
+
+// viewJumpPrompt renders the port number input in place of the help line
+func (m model) viewJumpPrompt() string {
+	promptStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Accent)
+	helpStyle := lipgloss.NewStyle().Foreground(ui.Muted)
+	return promptStyle.Render("Go to port: ") + string(m.jump.digits) + "█\n" +
+		helpStyle.Render("enter: jump • esc: cancel")
+}
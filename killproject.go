@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// killProject asks to TERM every listener under the selected port's project (git root or
+// directory), listing them first, e.g. the Vite, API and Storybook servers of a monorepo
+func (m model) killProject() (tea.Model, tea.Cmd) {
+	visiblePorts := m.getVisiblePorts()
+	if m.cursor >= len(visiblePorts) {
+		return m, nil
+	}
+	dir := pinnableProject(visiblePorts[m.cursor])
+	if dir == "" {
+		m.message = "The selected port has no project directory"
+		return m, nil
+	}
+	return m.killProjectDir(dir)
+}
+
+// killProjectDir asks to TERM every listener under dir, listing them first
+func (m model) killProjectDir(dir string) (tea.Model, tea.Cmd) {
+	targets := portsUnder(m.ports, dir)
+	if len(targets) == 0 {
+		m.message = fmt.Sprintf("Nothing of %s is listening", shortenPath(dir))
+		return m, nil
+	}
+
+	var details []string
+	for _, port := range targets {
+		what := port.Command
+		if port.Role != "" {
+			what += " (" + port.Role + ")"
+		}
+		where := "."
+		if rel, err := filepath.Rel(dir, port.Path); err == nil {
+			where = rel
+		}
+		details = append(details, fmt.Sprintf(":%-5d %-24s PID %-7s %s", port.Port, truncate(what, 24), port.PID, where))
+	}
+	question := fmt.Sprintf("Kill all %d listeners of %s with TERM?", len(targets), shortenPath(dir))
+	if len(targets) == 1 {
+		question = fmt.Sprintf("Kill the only listener of %s with TERM?", shortenPath(dir))
+	}
+	return m.confirmListThen(question, details, func(m model) (tea.Model, tea.Cmd) {
+		if m.tab != tabPorts {
+			// applyKillChoice keeps the Ports view's cursor in range, not this view's
+			cursor := m.cursor
+			m.cursor = m.tabCursors[tabPorts]
+			next, cmd := m.applyKillChoice(killChoices[0], targets)
+			m = next.(model)
+			m.tabCursors[tabPorts], m.cursor = m.cursor, cursor
+			return m, cmd
+		}
+		return m.applyKillChoice(killChoices[0], targets)
+	})
+}
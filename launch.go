@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// launchTimeout is how long x waits for a launched project to start listening
+const launchTimeout = 60 * time.Second
+
+// launchedMsg reports the outcome of x: the first new port under the project, or why
+// there is none
+type launchedMsg struct {
+	dir     string
+	command string
+	logPath string
+	port    *PortInfo
+	err     error
+}
+
+// launchCommand returns the configured launch command of the deepest project directory
+// containing path, and that directory. "launch" in a .portage.yml counts as configured
+// for its directory, and wins over portage.json for the same one.
+func (c *Config) launchCommand(path string) (dir, command string) {
+	bestLen := 0
+	for key, cmd := range c.Launch {
+		candidate := strings.TrimSuffix(expandHome(key), "/")
+		if (path == candidate || strings.HasPrefix(path, candidate+"/")) && len(candidate) > bestLen {
+			dir, command = candidate, cmd
+			bestLen = len(candidate)
+		}
+	}
+	if project := findProjectFile(path); project != nil && project.Launch != "" && len(project.dir) >= bestLen {
+		dir, command = project.dir, project.Launch
+	}
+	return dir, command
+}
+
+// portsUnder returns the ports whose working directory is dir or inside it
+func portsUnder(ports []PortInfo, dir string) []PortInfo {
+	var under []PortInfo
+	for _, port := range ports {
+		if port.Path == dir || strings.HasPrefix(port.Path, dir+"/") {
+			under = append(under, port)
+		}
+	}
+	return under
+}
+
+// launchProject starts a project's launch command for x, unless something of the
+// project is already listening
+func (m model) launchProject(path string) (tea.Model, tea.Cmd) {
+	dir, command := m.config.launchCommand(path)
+	if command == "" {
+		m.message = fmt.Sprintf("No launch command for %s (add \"launch\" to its .portage.yml, or under \"launch\" in portage.json)", shortenPath(path))
+		return m, nil
+	}
+	if running := portsUnder(m.ports, dir); len(running) > 0 {
+		m.message = fmt.Sprintf("%s is already running on port %d", shortenPath(dir), running[0].Port)
+		return m, nil
+	}
+	if readOnly {
+		m.message = fmt.Sprintf("Can't launch %s: %v", shortenPath(dir), errReadOnly)
+		return m, nil
+	}
+
+	known := make(map[string]bool)
+	for _, port := range m.ports {
+		known[fmt.Sprintf("%d-%s", port.Port, port.PID)] = true
+	}
+	m.message = fmt.Sprintf("Starting %s in %s…", command, shortenPath(dir))
+	return m, func() tea.Msg {
+		return startLaunch(dir, command, known)
+	}
+}
+
+// startLaunch runs command detached in dir, like a restart, and waits until a port that
+// wasn't in known starts listening under dir, the command fails, or launchTimeout passes
+func startLaunch(dir, command string, known map[string]bool) launchedMsg {
+	result := launchedMsg{dir: dir, command: command}
+	if demoMode {
+		return result
+	}
+
+	result.logPath = filepath.Join(os.TempDir(), fmt.Sprintf("portage-launch-%s.log", filepath.Base(dir)))
+	logFile, err := os.Create(result.logPath)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		result.err = err
+		return result
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	deadline := time.Now().Add(launchTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-exited:
+			if err != nil {
+				result.err = err
+				return result
+			}
+			exited = nil // the command backgrounded the server and returned; keep looking
+		case <-time.After(2 * time.Second):
+		}
+
+		ports, err := scanPorts()
+		if err != nil {
+			continue
+		}
+		for _, port := range portsUnder(ports, dir) {
+			if !known[fmt.Sprintf("%d-%s", port.Port, port.PID)] {
+				result.port = &port
+				return result
+			}
+		}
+	}
+	result.err = fmt.Errorf("no port after %v, still running", launchTimeout)
+	return result
+}
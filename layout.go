@@ -0,0 +1,74 @@
+package main
+
+import "strings"
+
+// minPathWidth is the narrowest the path column gets before other columns stop shrinking
+const minPathWidth = 20
+
+// elidePath fits a path into width by dropping the directories between its start and
+// the last segments that still fit: "~/…/apps/web" rather than "~/dev/monor...", since
+// the end of a path is what tells projects apart
+func elidePath(path string, width int) string {
+	runes := []rune(path)
+	if len(runes) <= width {
+		return path
+	}
+	if width < 2 {
+		return string(runes[len(runes)-width:])
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) > 1 {
+		prefix := parts[0] + "/…/" // "~/…/", or "/…/" for absolute paths
+		kept := parts[len(parts)-1]
+		for i := len(parts) - 2; i > 0; i-- {
+			candidate := parts[i] + "/" + kept
+			if len([]rune(prefix+candidate)) > width {
+				break
+			}
+			kept = candidate
+		}
+		if elided := prefix + kept; len([]rune(elided)) <= width {
+			return elided
+		}
+	}
+
+	// Not even the last segment fits behind the prefix: keep its end
+	return "…" + string(runes[len(runes)-width+1:])
+}
+
+// fitColumns narrows the columns that have a minWidth, in order, until the path gets at
+// least minPathWidth of termWidth (fixed is what the row uses besides the columns)
+func fitColumns(columns []portColumn, termWidth, fixed int) []portColumn {
+	used := fixed
+	for _, column := range columns {
+		used += column.width + 1
+	}
+	excess := used + minPathWidth - termWidth
+	if excess <= 0 {
+		return columns
+	}
+
+	fitted := make([]portColumn, len(columns))
+	copy(fitted, columns)
+	for i := range fitted {
+		if fitted[i].minWidth == 0 || excess <= 0 {
+			continue
+		}
+		shrink := min(fitted[i].width-fitted[i].minWidth, excess)
+		fitted[i].width -= shrink
+		excess -= shrink
+	}
+	return fitted
+}
+
+// fitAddress fits a listen address into width, dropping the ":port" that repeats the PORT
+// column before truncating the host
+func fitAddress(address string, width int) string {
+	if len([]rune(address)) > width {
+		if i := strings.LastIndex(address, ":"); i > 0 {
+			address = address[:i]
+		}
+	}
+	return truncate(address, width)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// rotationCutoff returns the time before which entries are rotated out of a log whose
+// oldest entry is oldest, zero for none: entries older than [logs] max_age_days, and
+// when the file is past max_size_mb, the older half of its time span too
+func rotationCutoff(logs LogSettings, size int64, oldest, now time.Time) time.Time {
+	if oldest.IsZero() {
+		return time.Time{}
+	}
+	var cutoff time.Time
+	if logs.MaxAgeDays > 0 {
+		if maxAge := now.AddDate(0, 0, -logs.MaxAgeDays); oldest.Before(maxAge) {
+			cutoff = maxAge
+		}
+	}
+	if logs.MaxSizeMB > 0 && size > int64(logs.MaxSizeMB)<<20 {
+		if half := oldest.Add(now.Sub(oldest) / 2); half.After(cutoff) {
+			cutoff = half
+		}
+	}
+	return cutoff
+}
+
+// archiveLog has write put what's rotated out of a log into a new archive next to it,
+// <path>.<time>.archive, then removes all but the newest [logs] archives of them.
+// Without archives to keep, nothing is written.
+func archiveLog(path string, archives int, now time.Time, write func(archive string) error) error {
+	if archives > 0 {
+		if err := write(path + "." + now.Format("20060102-150405") + ".archive"); err != nil {
+			return err
+		}
+	}
+	existing, _ := filepath.Glob(path + ".*.archive")
+	sort.Strings(existing) // the times sort in order
+	for len(existing) > archives {
+		os.Remove(existing[0])
+		existing = existing[1:]
+	}
+	return nil
+}
+
+// runningPortEvent matches the port_events rows of the listeners in the latest scan.
+// Rotation keeps them however old: recordScan would insert them again with the same
+// start, and they'd be rotated out again on every open while the listener runs.
+const runningPortEvent = `EXISTS (SELECT 1 FROM scan_ports WHERE scan_id = (SELECT MAX(id) FROM scans)
+	AND scan_ports.port = port_events.port AND scan_ports.path = port_events.path)`
+
+// rotateHistory moves the entries of history.db from before the rotation cutoff into an
+// archive: a copy of the whole database as it was, from which the live one then drops
+// them. The port events of running listeners and the latest scan, which the next one is
+// compared against, stay. Best effort, like the rest of the history.
+func rotateHistory(db *sql.DB, path string, now time.Time) {
+	logs := loadSettings().Logs
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	var oldest int64
+	err = db.QueryRow(`SELECT COALESCE(MIN(t), 0) FROM (
+		SELECT MIN(started_at) AS t FROM port_events WHERE NOT ` + runningPortEvent + ` UNION ALL
+		SELECT MIN(closed_at) FROM port_closes UNION ALL
+		SELECT MIN(at) FROM workspace_events UNION ALL
+		SELECT MIN(at) FROM scans WHERE id < (SELECT MAX(id) FROM scans))`).Scan(&oldest)
+	if err != nil || oldest == 0 {
+		return
+	}
+	cutoff := rotationCutoff(logs, info.Size(), time.Unix(oldest, 0), now)
+	if cutoff.IsZero() {
+		return
+	}
+
+	err = archiveLog(path, logs.Archives, now, func(archive string) error {
+		_, err := db.Exec(`VACUUM INTO ?`, archive)
+		return err
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "portage: couldn't archive %s, not rotating it: %v\n", shortenPath(path), err)
+		return
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+	for _, query := range []string{
+		`DELETE FROM port_events WHERE started_at < ? AND NOT ` + runningPortEvent,
+		`DELETE FROM port_closes WHERE closed_at < ?`,
+		`DELETE FROM workspace_events WHERE at < ?`,
+		`DELETE FROM scan_ports WHERE scan_id IN (SELECT id FROM scans WHERE at < ? AND id < (SELECT MAX(id) FROM scans))`,
+		`DELETE FROM scans WHERE at < ? AND id < (SELECT MAX(id) FROM scans)`,
+	} {
+		if _, err := tx.Exec(query, cutoff.Unix()); err != nil {
+			return
+		}
+	}
+	if tx.Commit() == nil {
+		db.Exec(`VACUUM`)
+		if !demoMode {
+			fmt.Fprintf(os.Stderr, "portage: rotated history from before %s out of %s\n", cutoff.Format("2006-01-02"), shortenPath(path))
+		}
+	}
+}
+
+// rotateActivityLog moves the samples from before the rotation cutoff out of the
+// activity log into an archive, keeping the newer ones in place. The log is appended to
+// in time order, so its first line says whether there's anything to rotate.
+func rotateActivityLog(path string, now time.Time) error {
+	logs := loadSettings().Logs
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	sampleTime := func(line string) time.Time {
+		at, _, _ := strings.Cut(line, "\t")
+		t, _ := time.ParseInLocation("2006-01-02 15:04:05", at, time.Local)
+		return t
+	}
+	first, _ := bufio.NewReader(f).ReadString('\n')
+	f.Close()
+	cutoff := rotationCutoff(logs, info.Size(), sampleTime(first), now)
+	if cutoff.IsZero() {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var old, kept strings.Builder
+	for _, line := range strings.Split(string(data), "\n") {
+		if t := sampleTime(line); !t.IsZero() && t.Before(cutoff) {
+			old.WriteString(line + "\n")
+		} else if line != "" {
+			kept.WriteString(line + "\n")
+		}
+	}
+	err = archiveLog(path, logs.Archives, now, func(archive string) error {
+		return os.WriteFile(archive, []byte(old.String()), 0600)
+	})
+	if err != nil {
+		return err
+	}
+	// Write then rename, so a crash leaves either log whole
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(kept.String()), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
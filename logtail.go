@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// projectLogPatterns are where dev servers commonly write logs, relative to the project
+var projectLogPatterns = []string{
+	"nohup.out",
+	"*.log",
+	"log/*.log",
+	"logs/*.log",
+	"tmp/*.log",
+	".next/trace",
+}
+
+// logTailBytes is how much of the end of a log file the tail view reads
+const logTailBytes = 256 * 1024
+
+// findLogFiles returns log files a listener probably writes to, best guess first:
+// files its stdout/stderr point to, portage's own restart log, then the most recently
+// written log files in the project. If stdout is a terminal, that's returned as tty.
+func findLogFiles(port PortInfo) (files []string, tty string) {
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() && !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	// stdout and stderr of the process (redirected with > or run under nohup)
+	if output, err := commandOutput("lsof", "-a", "-p", port.PID, "-d", "1,2", "-Fn"); err == nil {
+		for _, line := range strings.Split(string(output), "\n") {
+			if !strings.HasPrefix(line, "n") {
+				continue
+			}
+			name := line[1:]
+			if strings.HasPrefix(name, "/dev/") {
+				tty = name
+				continue
+			}
+			add(name)
+		}
+	}
+
+	add(filepath.Join(os.TempDir(), fmt.Sprintf("portage-restart-%d.log", port.Port)))
+
+	if port.Path != "N/A" && port.Path != "/" && !port.Orphaned {
+		var candidates []string
+		for _, pattern := range projectLogPatterns {
+			matches, _ := filepath.Glob(filepath.Join(port.Path, pattern))
+			candidates = append(candidates, matches...)
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return modTime(candidates[i]).After(modTime(candidates[j]))
+		})
+		for _, path := range candidates {
+			add(path)
+		}
+	}
+
+	return files, tty
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// readLogTail returns the last lines of a file, reading at most logTailBytes
+func readLogTail(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := info.Size() - logTailBytes
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if offset > 0 && len(lines) > 1 {
+		lines = lines[1:] // The first line was cut in the middle
+	}
+	return lines, nil
+}
+
+// logView is the scrollable tail of a server's log, opened with l in interactive mode
+type logView struct {
+	port   PortInfo
+	files  []string // candidates from findLogFiles; n cycles through them
+	file   int
+	lines  []string
+	offset int  // first line shown
+	follow bool // keep the view at the end as the file grows
+	err    error
+}
+
+// logTickMsg re-reads the log while the tail view is open
+type logTickMsg time.Time
+
+func logTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return logTickMsg(t)
+	})
+}
+
+// reload reads the current file again, keeping the scroll position unless following
+func (v *logView) reload(height int) {
+	v.lines, v.err = readLogTail(v.files[v.file])
+	if v.follow {
+		v.offset = max(len(v.lines)-height, 0)
+	}
+}
+
+// logViewHeight is how many log lines fit on screen (title, path, help and margins take 6);
+// the inspect view has the same layout
+func (m model) logViewHeight() int {
+	if m.height == 0 {
+		return 30
+	}
+	return max(m.height-6, 3)
+}
+
+// openLogView starts tailing the selected port's log, or explains why there is none
+func (m model) openLogView(port PortInfo) (tea.Model, tea.Cmd) {
+	files, tty := findLogFiles(port)
+	if len(files) == 0 {
+		if tty != "" {
+			m.message = fmt.Sprintf("%s (PID %s) logs to the terminal %s, not a file", port.Command, port.PID, tty)
+		} else {
+			m.message = fmt.Sprintf("No log file found for %s (PID %s)", port.Command, port.PID)
+		}
+		return m, nil
+	}
+
+	m.log = &logView{port: port, files: files, follow: true}
+	m.log.reload(m.logViewHeight())
+	return m, logTick()
+}
+
+// updateLog handles keys and refreshes while the tail view is open
+func (m model) updateLog(msg tea.Msg) (tea.Model, tea.Cmd) {
+	height := m.logViewHeight()
+	switch msg := msg.(type) {
+	case logTickMsg:
+		m.log.reload(height)
+		return m, logTick()
+
+	case tea.KeyMsg:
+		v := m.log
+		last := max(len(v.lines)-height, 0)
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc", "q", "l":
+			m.log = nil
+			return m, nil
+		case "up", "k":
+			v.offset = max(v.offset-1, 0)
+		case "down", "j":
+			v.offset = min(v.offset+1, last)
+		case "pgup", "b":
+			v.offset = max(v.offset-height, 0)
+		case "pgdown", " ":
+			v.offset = min(v.offset+height, last)
+		case "g", "home":
+			v.offset = 0
+		case "G", "end":
+			v.offset = last
+		case "n":
+			// Next candidate log file
+			v.file = (v.file + 1) % len(v.files)
+			v.follow = true
+			v.reload(height)
+			return m, nil
+		}
+		v.follow = v.offset == last
+	}
+	return m, nil
+}
+
+// viewLog renders the tail view
+func (m model) viewLog() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Accent)
+	helpStyle := lipgloss.NewStyle().Foreground(ui.Muted)
+
+	v := m.log
+	width := m.width
+	if width == 0 {
+		width = getTerminalWidth()
+	}
+	height := m.logViewHeight()
+
+	var s strings.Builder
+	s.WriteString(titleStyle.Render(fmt.Sprintf("LOG - %s (PID %s) on port %d", v.port.Command, v.port.PID, v.port.Port)))
+	s.WriteString("\n")
+	path := shortenPath(v.files[v.file])
+	if len(v.files) > 1 {
+		path += fmt.Sprintf("  [%d/%d, n: next file]", v.file+1, len(v.files))
+	}
+	s.WriteString(helpStyle.Render(path))
+	s.WriteString("\n\n")
+
+	if v.err != nil {
+		s.WriteString(fmt.Sprintf("Failed to read log: %v\n", v.err))
+	} else {
+		end := min(v.offset+height, len(v.lines))
+		for _, line := range v.lines[v.offset:end] {
+			s.WriteString(truncate(strings.ReplaceAll(line, "\t", "    "), width))
+			s.WriteString("\n")
+		}
+		for i := end - v.offset; i < height; i++ {
+			s.WriteString("\n")
+		}
+	}
+
+	status := "following"
+	if !v.follow {
+		status = fmt.Sprintf("line %d of %d", v.offset+1, len(v.lines))
+	}
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Width(width).Render("↑/↓ j/k: scroll • pgup/pgdn: page • g/G: top/bottom • n: next file • esc/l: back • " + status))
+	return s.String()
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -16,12 +15,14 @@ import (
 	"strings"
 	"time"
 
+	"portage/durations"
+
 	"github.com/jedib0t/go-pretty/v6/table"
 	_ "modernc.org/sqlite"
 )
 
-// Colors for terminal output
-const (
+// Colors for terminal output; the monochrome theme blanks them
+var (
 	ColorReset  = "\033[0m"
 	ColorRed    = "\033[31m"
 	ColorGreen  = "\033[32m"
@@ -34,14 +35,32 @@ const (
 )
 
 type PortInfo struct {
-	Port         int
-	PID          string
-	Command      string
-	Address      string
-	User         string
-	Path         string
-	Uptime       string
-	UptimeSeconds int
+	Port          int     `json:"port"`
+	PID           string  `json:"pid"`
+	Command       string  `json:"command"`
+	Address       string  `json:"address"`
+	User          string  `json:"user"`
+	Path          string  `json:"path"`
+	Uptime        string  `json:"uptime"`
+	UptimeSeconds int     `json:"uptime_seconds"`
+	Orphaned      bool    `json:"orphaned"`     // working directory no longer exists on disk
+	Owner         string  `json:"owner"`        // owners from the repository's CODEOWNERS, if any
+	Name          string  `json:"name"`         // alias from config, if any
+	Note          string  `json:"note"`         // project note from config (n in interactive mode), if any
+	Type          string  `json:"type"`         // "ssh-tunnel" for ssh -L listeners, empty for regular servers
+	Tunnel        string  `json:"tunnel"`       // forwarding details for ssh tunnels
+	Terminal      string  `json:"terminal"`     // tmux pane, iTerm session or tty the process was started from
+	Health        string  `json:"health"`       // grpc.health.v1 status with --grpc-health, empty if not gRPC
+	Check         string  `json:"check"`        // HTTP status, "timeout" or "refused" with --check, empty if not HTTP
+	Elevated      bool    `json:"elevated"`     // only visible to lsof when run through sudo (--sudo)
+	CommandLine   string  `json:"command_line"` // full command line from ps (Command is lsof's 9-character name)
+	LastCommand   string  `json:"last_command"` // last shell command run in the project before it started (--shell-history)
+	BrowserTabs   int     `json:"browser_tabs"` // open browser tabs pointing at this port (--tabs)
+	Role          string  `json:"role"`         // dev server framework detected from the command line, e.g. "Next.js"
+	Daemon        string  `json:"daemon"`       // tool whose background daemon this is (nx, turbo, pnpm, ...), folded by default
+	CPU           float64 `json:"cpu"`          // %CPU from ps, filled in by interactive mode for its cpu sort
+	Branch        string  `json:"branch"`       // git branch of the project, for the BRANCH column (columns.go)
+	Unexpected    bool    `json:"unexpected"`   // on a port the project's .portage.yml doesn't declare (projectfile.go)
 }
 
 type ClaudeSession struct {
@@ -80,13 +99,27 @@ type WorkspaceHistoryEntry struct {
 	Timestamp int64  `json:"timestamp"`  // Unix timestamp in milliseconds
 	SessionID string `json:"session_id,omitempty"` // For Claude sessions
 	Messages  int    `json:"messages,omitempty"`   // For Claude sessions
+	Pinned    bool   `json:"pinned,omitempty"`     // pinned with `portage pin`, listed first
 }
 
 var debugMode bool
+var showTimings bool
+var themeName string
+var showDaemons bool
 var sortBy string
 var interactive bool
 var showHistory bool
 var jsonOutput bool
+var yamlOutput bool
+var formatString string
+var quietOutput bool
+var metricsOutput bool
+var htmlOutput bool
+var wideTable bool
+var compactTable bool
+var printField string
+var portFilterFlag string
+var portFilter []int
 var showAllPorts bool
 var showCursor bool
 var showClaude bool
@@ -96,23 +129,229 @@ var showCursorHistory bool
 var cursorHistoryLimit int
 var logCloseWorkspace string
 var logOpenWorkspace string
+var showOrphans bool
+var groupByProject bool
+var groupByRange bool
+var watchMode bool
+var watchInterval time.Duration
+var watchBell bool
+var watchTmux bool
+var recordActivity bool
+var jqQuery string
+var pathFilter string
+var killPort string
+var userFilter string
+var matchPattern string
+var matchRegex *regexp.Regexp
+var showSystemPorts bool
+var includeSystem bool
+var profileName string
+var grpcHealth bool
+var useShellHistory bool
+var showBrowserTabs bool
+var auditMode bool
+var noEnrich bool
+var refreshInterval time.Duration
+var useSudo bool
+var grpcHealthTimeout time.Duration
+var httpCheck bool
+var checkTimeout time.Duration
+var checkInterval time.Duration
 
 func main() {
+	// `portage demo [flags]` runs everything against synthetic data, interactive by default
+	if len(os.Args) > 1 && os.Args[1] == "demo" {
+		cleanup, err := startDemo()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting up demo: %v\n", err)
+			os.Exit(1)
+		}
+		defer cleanup()
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		if len(os.Args) == 1 {
+			os.Args = append(os.Args, "-i")
+		}
+	}
+
+	// `portage config` comes before config.toml is loaded: it's how a broken one gets fixed
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		runConfig(os.Args[2:])
+		return
+	}
+
+	// config.toml is checked up front so a mistake in it is reported before anything runs
+	loadSettings()
+
+	// The config can make read-only the default; --read-only=false overrides it
+	readOnly = loadConfig().ReadOnly
+	if err := applyTheme(loadConfig().theme(), ""); err != nil {
+		fmt.Fprintf(os.Stderr, "Error in theme config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Subcommands take precedence over the flag-based modes
+	if len(os.Args) > 1 && runSubcommand(os.Args[1], os.Args[2:]) {
+		return
+	}
+
 	flag.BoolVar(&debugMode, "debug", false, "Enable debug mode with timing information")
-	flag.StringVar(&sortBy, "sort", "uptime", "Sort by: 'port' (ascending) or 'uptime' (descending)")
+	flag.BoolVar(&showDaemons, "daemons", false, "Include tooling daemons (nx, turbo, pnpm, eslint_d, ...) in the list, counts and alerts")
+	flag.BoolVar(&showTimings, "timings", false, "Print how long each provider and enrichment stage took (in the JSON envelope with --json)")
+	flag.StringVar(&sortBy, "sort", loadSettings().Defaults.Sort, "Sort by: 'port' (ascending) or 'uptime' (descending); interactive mode also takes 'command', 'path' and 'cpu'")
 	flag.BoolVar(&interactive, "i", false, "Interactive mode with navigation and controls")
 	flag.BoolVar(&showHistory, "history", false, "Show combined workspace history from both Claude and Cursor")
 	flag.BoolVar(&jsonOutput, "json", false, "Output in JSON format")
-	flag.BoolVar(&showAllPorts, "all", false, "Show all ports (not just 3000+, 4000+, 8000+)")
+	flag.BoolVar(&yamlOutput, "yaml", false, "Output in YAML format (the same structures as --json)")
+	flag.StringVar(&formatString, "format", "", "Format each item with a Go template, e.g. '{{.Port}}\\t{{.Path}}'")
+	flag.BoolVar(&showAllPorts, "all", loadSettings().Defaults.All, "Show all ports (not just 3000+, 4000+, 8000+)")
 	flag.BoolVar(&showCursor, "cursor", false, "Show active Cursor windows")
 	flag.BoolVar(&showClaude, "claude", false, "Show active Claude Code sessions")
 	flag.BoolVar(&showClaudeHistory, "claude-history", false, "Show Claude session history from ~/.claude/history.jsonl")
-	flag.BoolVar(&showUnified, "unified", false, "Show unified list of ports and Cursor workspaces")
+	flag.BoolVar(&showUnified, "unified", false, "Show unified list of ports and Cursor workspaces (JSON; a tree view with -i)")
 	flag.BoolVar(&showCursorHistory, "cursor-history", false, "Show Cursor workspace history from close events")
-	flag.IntVar(&cursorHistoryLimit, "limit", 10, "Limit number of history entries (use with --history or --cursor-history)")
+	flag.IntVar(&cursorHistoryLimit, "limit", loadSettings().Defaults.Limit, "Limit number of history entries (use with --history or --cursor-history)")
+	flag.StringVar(&historySearch, "history-search", "", "Search the port discovery history by path, command or port number")
+	flag.StringVar(&historySince, "since", "", "Only history entries still running since: a duration ago (2h), a day (yesterday, tuesday) or a date")
+	flag.StringVar(&historyUntil, "until", "", "Only history entries started before this; a day includes all of it")
 	flag.StringVar(&logCloseWorkspace, "log-close", "", "Log workspace closure (specify full path)")
 	flag.StringVar(&logOpenWorkspace, "log-open", "", "Remove workspace from close log (specify full path)")
+	flag.BoolVar(&showOrphans, "orphans", false, "Show only listeners whose working directory no longer exists")
+	flag.BoolVar(&groupByProject, "group", false, "Group ports under their project directory with git branch")
+	flag.BoolVar(&groupByRange, "by-range", false, "Split the table into sections per port range (3000s, 4000s, 8000s, other) with counts")
+	flag.BoolVar(&watchMode, "watch", false, "Keep running and report ports as they open and close")
+	flag.DurationVar(&watchInterval, "interval", 5*time.Second, "Rescan interval for --watch")
+	flag.BoolVar(&watchBell, "bell", false, "Ring the terminal bell on alerts in --watch mode (public binds, sensitive ports)")
+	flag.BoolVar(&watchTmux, "tmux-alert", false, "Set the tmux @portage_alert option and show a message on alerts in --watch mode")
+	flag.BoolVar(&recordActivity, "record-activity", false, "With --watch, sample the frontmost app and editor workspace once a minute into the activity log (local, opt-in)")
+	flag.StringVar(&pathFilter, "path", "", "Only show ports, Claude sessions and Cursor windows under this directory (or pass it as an argument)")
+	flag.StringVar(&killPort, "kill", "", "Kill whatever listens on a port number or configured alias")
+	flag.StringVar(&userFilter, "user", "", "Only show ports owned by this user")
+	flag.StringVar(&matchPattern, "match", "", "Only show ports whose command, command line, address or path matches this regex")
+	flag.StringVar(&portFilterFlag, "port", "", "Only show these ports (comma-separated), wherever their process runs from")
+	flag.BoolVar(&quietOutput, "q", false, "Print only the matching port numbers, one per line (exit 1 if none)")
+	flag.StringVar(&printField, "print", "port", "What -q prints: 'port', 'pid' or 'path'")
+	flag.BoolVar(&metricsOutput, "metrics", false, "Print the scan as Prometheus metrics (for node_exporter's textfile collector)")
+	flag.BoolVar(&htmlOutput, "html", false, "Print the scan as a standalone HTML page, grouped by project")
+	flag.BoolVar(&wideTable, "wide", false, "Show every column, full paths and full command lines")
+	flag.BoolVar(&compactTable, "compact", false, "Show only port, command and uptime, without borders")
+	flag.BoolVar(&grpcHealth, "grpc-health", false, "Run the standard gRPC health check against each port and show a HEALTH column")
+	flag.DurationVar(&grpcHealthTimeout, "grpc-timeout", 500*time.Millisecond, "Timeout for each --grpc-health check")
+	flag.BoolVar(&httpCheck, "check", false, "Request / on each port and show the HTTP status in a CHECK column (a colored dot per row in interactive mode)")
+	flag.DurationVar(&checkTimeout, "check-timeout", 2*time.Second, "How long --check waits for a response before reporting a timeout")
+	flag.DurationVar(&checkInterval, "check-interval", 10*time.Second, "How often interactive mode repeats --check")
+	flag.BoolVar(&useShellHistory, "shell-history", false, "Read zsh/fish history to show the last command run in each port's directory")
+	flag.BoolVar(&showBrowserTabs, "tabs", false, "Ask Chrome, Arc, Brave, Edge and Safari which ports have open localhost tabs")
+	flag.DurationVar(&refreshInterval, "refresh", 5*time.Second, "Rescan interval in interactive mode (0 to disable)")
+	flag.BoolVar(&auditMode, "audit", false, "Classify listeners by exposure (loopback, LAN, all interfaces, public); exits 1 if anything unexpected is reachable from other machines")
+	flag.BoolVar(&useSudo, "sudo", false, "Scan through sudo so other users' and root's listeners are included (marked with *)")
+	flag.BoolVar(&showSystemPorts, "system", false, "Include root and system daemons, with a USER column (run with sudo to see other users' processes)")
+	flag.BoolVar(&includeSystem, "include-system", false, "Ignore [filters] in config.toml: list what its exclude rules leave out (e.g. redis or postgres) and what's outside include_paths")
+	flag.StringVar(&profileName, "profile", "", "Use the filters, ranges and hides of a [profiles.<name>] table in config.toml (default from PORTAGE_PROFILE)")
+	flag.BoolVar(&noEnrich, "no-enrich", false, "Fast mode for scripts: only port, PID and command from a single lsof call (JSON uses null for the rest)")
+	flag.BoolVar(&readOnly, "read-only", readOnly, "Observe only: no killing, no saved hides, no log writes (default from \"read_only\" in portage.json)")
+	flag.StringVar(&themeName, "theme", "", "Color theme: dark, light, solarized or monochrome (default from [theme] in config.toml or \"theme\" in portage.json)")
+	flag.StringVar(&jqQuery, "jq", "", "Filter JSON output with a jq expression (implies --json), e.g. '.data[].port'")
 	flag.Parse()
+	applyDefaultOutput() // [defaults] in config.toml
+	migrateLegacyFiles() // now that --read-only is known
+
+	if profileName != "" {
+		if err := useProfile(profileName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if themeName != "" {
+		if err := applyTheme(loadConfig().theme(), themeName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// A jq query only makes sense against JSON output, and YAML is JSON's structures
+	if jqQuery != "" || yamlOutput {
+		jsonOutput = true
+	}
+
+	// --format templates run over the values the JSON output is made of
+	if formatString != "" {
+		if jqQuery != "" || yamlOutput {
+			fmt.Fprintf(os.Stderr, "Error: --format can't be combined with --jq or --yaml\n")
+			os.Exit(1)
+		}
+		tmpl, err := parseFormat(formatString)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing --format: %v\n", err)
+			os.Exit(1)
+		}
+		formatTemplate = tmpl
+		jsonOutput = true
+	}
+
+	// `portage ~/dev/myapp` is shorthand for --path
+	if pathFilter == "" && flag.NArg() > 0 {
+		pathFilter = flag.Arg(0)
+	}
+	if pathFilter != "" {
+		// Path aliases from config are accepted too
+		if _, aliasPath, ok := resolveAlias(pathFilter, loadConfig()); ok && aliasPath != "" {
+			pathFilter = aliasPath
+		}
+		pathFilter = resolvePathFilter(pathFilter)
+	}
+
+	if matchPattern != "" {
+		var err error
+		if matchRegex, err = regexp.Compile(matchPattern); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --match pattern: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if portFilterFlag != "" {
+		var err error
+		if portFilter, err = parsePortFilter(portFilterFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --port: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if metricsOutput && (interactive || jsonOutput || quietOutput) {
+		fmt.Fprintf(os.Stderr, "Error: --metrics can't be combined with -i, -q, --json, --yaml, --jq or --format\n")
+		os.Exit(1)
+	}
+	if groupByRange && groupByProject {
+		fmt.Fprintf(os.Stderr, "Error: --by-range and --group can't be combined\n")
+		os.Exit(1)
+	}
+	if wideTable && compactTable {
+		fmt.Fprintf(os.Stderr, "Error: --wide and --compact can't be combined\n")
+		os.Exit(1)
+	}
+	if htmlOutput && (interactive || jsonOutput || quietOutput || metricsOutput) {
+		fmt.Fprintf(os.Stderr, "Error: --html can't be combined with -i, -q, --metrics, --json, --yaml, --jq or --format\n")
+		os.Exit(1)
+	}
+
+	if quietOutput {
+		if interactive || jsonOutput {
+			fmt.Fprintf(os.Stderr, "Error: -q can't be combined with -i, --json, --yaml, --jq or --format\n")
+			os.Exit(1)
+		}
+		if printField != "port" && printField != "pid" && printField != "path" {
+			fmt.Fprintf(os.Stderr, "Error: --print must be 'port', 'pid' or 'path'\n")
+			os.Exit(1)
+		}
+	}
+
+	if killPort != "" {
+		if err := killTarget(killPort); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Handle workspace log commands
 	if logCloseWorkspace != "" {
@@ -133,6 +372,13 @@ func main() {
 
 	// If unified mode, display unified list and exit
 	if showUnified {
+		if interactive {
+			if err := runUnifiedTree(collectUnified()); err != nil {
+				fmt.Fprintf(os.Stderr, "Error running interactive mode: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
 		displayUnified()
 		return
 	}
@@ -161,101 +407,275 @@ func main() {
 		return
 	}
 
+	// Searching the discovery history
+	if searchingHistory() {
+		runHistorySearch()
+		return
+	}
+
 	// If history mode, display combined workspace history and exit
 	if showHistory {
 		displayWorkspaceHistory()
 		return
 	}
 
-	startTime := time.Now()
+	// Watch mode keeps rescanning until interrupted
+	if watchMode {
+		runWatch(watchInterval)
+		return
+	}
+
+	// Fast mode stops right after lsof
+	if noEnrich {
+		if interactive {
+			fmt.Fprintf(os.Stderr, "Error: --no-enrich can't be combined with interactive mode\n")
+			os.Exit(1)
+		}
+		runNoEnrich()
+		return
+	}
+
+	if showTimings || debugMode {
+		timings = newTimingReport()
+	}
 
 	// Execute lsof command
 	lsofStart := time.Now()
-	cmd := exec.Command("lsof", "-i", "-P", "-n")
-	output, err := cmd.Output()
+	output, err := lsofListing()
 	if err != nil {
 		fmt.Printf("Error executing lsof: %v\n", err)
-		fmt.Println("Try running with sudo if you need to see all processes")
+		fmt.Println("Try --sudo if you need to see all processes")
 		os.Exit(1)
 	}
-	if debugMode {
-		fmt.Printf("[DEBUG] lsof execution: %v\n", time.Since(lsofStart))
-	}
+	timings.record("lsof", lsofStart, "")
 
 	// Parse output
 	parseStart := time.Now()
 	ports := parseOutput(string(output))
-	if debugMode {
-		fmt.Printf("[DEBUG] Parsing output: %v (%d ports found)\n", time.Since(parseStart), len(ports))
-	}
+	markElevatedPorts(ports)
+	timings.record("parse", parseStart, fmt.Sprintf("%d ports", len(ports)))
 
 	// Get working directory and uptime for each process (with caching for same PIDs)
-	if !debugMode && !jsonOutput {
+	showProgress := !debugMode && !showTimings && !jsonOutput && !quietOutput && !metricsOutput && !htmlOutput
+	if showProgress {
 		fmt.Printf("Scanning ports")
 	}
 	scanStart := time.Now()
+	uniqueProcesses := 0
+	enrichPorts(ports, func(port PortInfo, processDuration time.Duration) {
+		if showProgress {
+			fmt.Printf(".")
+		}
+		uniqueProcesses++
+		timings.recordProcess(port, processDuration)
+	})
+	timings.record("enrich", scanStart, fmt.Sprintf("%d processes", uniqueProcesses))
+	tunnelStart := time.Now()
+	annotateSSHTunnels(ports)
+	timings.record("ssh tunnels", tunnelStart, "")
+	if showProgress {
+		fmt.Printf(" done\n")
+	}
+
+	// Filter ports by path, hidden ports and orphans
+	filterStart := time.Now()
+	config := loadConfig()
+	migrateHiddenPorts(ports, config)
+	filtered := selectPorts(ports, config)
+	timings.record("filter", filterStart, "")
+
+	// Resolve team ownership hints from CODEOWNERS and configured aliases
+	stageStart := time.Now()
+	resolvePortOwners(filtered)
+	timings.record("owners", stageStart, "")
+	stageStart = time.Now()
+	resolvePortAliases(filtered, config)
+	resolveProjectFiles(filtered)
+	resolvePortNotes(filtered, config)
+	timings.record("aliases", stageStart, "")
+	var shellHistory []shellCommand
+	if useShellHistory {
+		stageStart = time.Now()
+		shellHistory = loadShellHistory()
+		timings.record("shell history", stageStart, fmt.Sprintf("%d commands", len(shellHistory)))
+	}
+	for _, portList := range filtered {
+		stageStart = time.Now()
+		resolvePortTerminals(portList)
+		timings.record("terminals", stageStart, "")
+		stageStart = time.Now()
+		resolveLastCommands(portList, shellHistory)
+		timings.record("last commands", stageStart, "")
+		if showBrowserTabs {
+			stageStart = time.Now()
+			resolveBrowserTabs(portList)
+			timings.record("browser tabs", stageStart, "")
+		}
+		if grpcHealth {
+			stageStart = time.Now()
+			resolveGRPCHealth(portList, grpcHealthTimeout)
+			timings.record("grpc health", stageStart, "")
+		}
+		if httpCheck && !interactive {
+			// Interactive mode checks in the background instead
+			stageStart = time.Now()
+			resolveHTTPChecks(portList, checkTimeout)
+			timings.record("http check", stageStart, "")
+		}
+	}
+
+	// Record newly discovered ports and the scan in history.db. The filters only decide
+	// what's shown: a listener left out of this run hasn't stopped.
+	recordScan(ports)
+
+	if auditMode {
+		os.Exit(runAudit(filtered, config))
+	}
+
+	// Interactive mode or regular display
+	if interactive {
+		// Pass all ports to interactive mode
+		prepareInteractivePorts(ports, shellHistory)
+		timings.finish()
+		if err := runInteractive(ports, shellHistory, time.Since(lsofStart)); err != nil {
+			fmt.Printf("Error in interactive mode: %v\n", err)
+			os.Exit(1)
+		}
+		timings.print(debugMode)
+	} else {
+		// Display results (already filtered above)
+		displayStart := time.Now()
+
+		if quietOutput {
+			displayQuiet(filtered, sortBy)
+			return
+		} else if metricsOutput {
+			displayMetrics(filtered, time.Since(lsofStart))
+			return
+		} else if htmlOutput {
+			displayHTML(filtered, sortBy)
+			return
+		} else if jsonOutput {
+			displayPortsJSON(filtered, sortBy)
+			return
+		} else if groupByProject {
+			displayPortsGrouped(filtered, sortBy)
+		} else {
+			displayPorts(filtered, sortBy)
+		}
+		displayFoldedDaemons(ports, config)
+		displayFoldedOtherPorts(ports, config)
+		timings.record("display", displayStart, "")
+
+		dockerStart := time.Now()
+		containerPorts := listUnpublishedContainerPorts()
+		timings.record("docker", dockerStart, fmt.Sprintf("%d unpublished", len(containerPorts)))
+		displayUnpublishedContainerPorts(containerPorts)
+
+		timings.print(debugMode)
+	}
+}
+
+// runSubcommand dispatches `portage <name> [args]` and reports whether name was a known subcommand
+func runSubcommand(name string, args []string) bool {
+	var run func(args []string)
+	switch name {
+	case "record-fixtures":
+		run = runRecordFixtures
+	case "switch":
+		run = runSwitch
+	case "heatmap":
+		run = runHeatmap
+	case "history":
+		run = runHistory
+	case "capabilities":
+		run = runCapabilities
+	case "suggest":
+		run = runSuggest
+	case "stats":
+		run = runStats
+	case "timeline":
+		run = runTimeline
+	case "digest":
+		run = runDigest
+	case "time":
+		run = runTime
+	case "pin":
+		run = runPin
+	case "unpin":
+		run = runUnpin
+	default:
+		return false
+	}
+	migrateLegacyFiles() // subcommands only take read-only from the config
+	run(args)
+	return true
+}
+
+// scanPorts lists listening sockets and enriches them with working directory and uptime
+func scanPorts() ([]PortInfo, error) {
+	output, err := lsofListing()
+	if err != nil {
+		return nil, err
+	}
+
+	ports := parseOutput(string(output))
+	markElevatedPorts(ports)
+	enrichPorts(ports, nil)
+	annotateSSHTunnels(ports)
+	return ports, nil
+}
+
+// enrichPorts fills in path and uptime for each port, looking up every PID only once.
+// onProcess, if set, is called after each unique process lookup with its duration.
+func enrichPorts(ports []PortInfo, onProcess func(port PortInfo, duration time.Duration)) {
 	pathCache := make(map[string]string)
 	uptimeCache := make(map[string]string)
 	uptimeSecondsCache := make(map[string]int)
-	uniqueProcesses := 0
-	type ProcessTiming struct {
-		PID      string
-		Command  string
-		Duration time.Duration
-	}
-	var timings []ProcessTiming
 
 	for i := range ports {
 		if cachedPath, exists := pathCache[ports[i].PID]; exists {
 			ports[i].Path = cachedPath
+			ports[i].Orphaned = isOrphanedPath(cachedPath)
 			ports[i].Uptime = uptimeCache[ports[i].PID]
 			ports[i].UptimeSeconds = uptimeSecondsCache[ports[i].PID]
-		} else {
-			if !debugMode && !jsonOutput {
-				fmt.Printf(".")
-			}
-			uniqueProcesses++
-
-			processStart := time.Now()
-			ports[i].Path = getWorkingDirectory(ports[i].PID)
-			uptimeStr, uptimeSec := getProcessUptime(ports[i].PID)
-			processDuration := time.Since(processStart)
-
-			if debugMode {
-				timings = append(timings, ProcessTiming{
-					PID:      ports[i].PID,
-					Command:  ports[i].Command,
-					Duration: processDuration,
-				})
-			}
+			continue
+		}
+
+		processStart := time.Now()
+		ports[i].Path = getWorkingDirectory(ports[i].PID)
+		ports[i].Orphaned = isOrphanedPath(ports[i].Path)
+		ports[i].Uptime, ports[i].UptimeSeconds = getProcessUptime(ports[i].PID)
+		processDuration := time.Since(processStart)
+
+		pathCache[ports[i].PID] = ports[i].Path
+		uptimeCache[ports[i].PID] = ports[i].Uptime
+		uptimeSecondsCache[ports[i].PID] = ports[i].UptimeSeconds
 
-			ports[i].Uptime = uptimeStr
-			ports[i].UptimeSeconds = uptimeSec
-			pathCache[ports[i].PID] = ports[i].Path
-			uptimeCache[ports[i].PID] = uptimeStr
-			uptimeSecondsCache[ports[i].PID] = uptimeSec
+		if onProcess != nil {
+			onProcess(ports[i], processDuration)
 		}
 	}
-	if !debugMode && !jsonOutput {
-		fmt.Printf(" done\n")
+
+	// One ps call for every command line rather than one per process
+	var pids []string
+	for pid := range pathCache {
+		pids = append(pids, pid)
 	}
-	if debugMode {
-		fmt.Printf("[DEBUG] Scanning %d unique processes: %v\n", uniqueProcesses, time.Since(scanStart))
-		// Show slowest processes
-		sort.Slice(timings, func(i, j int) bool {
-			return timings[i].Duration > timings[j].Duration
-		})
-		fmt.Printf("[DEBUG] Top 5 slowest processes:\n")
-		for i := 0; i < 5 && i < len(timings); i++ {
-			fmt.Printf("[DEBUG]   PID %s (%s): %v\n", timings[i].PID, timings[i].Command, timings[i].Duration)
-		}
+	commandLines := getCommandLines(pids)
+	for i := range ports {
+		ports[i].CommandLine = commandLines[ports[i].PID]
+		ports[i].Role = detectFramework(ports[i].CommandLine)
+		ports[i].Daemon = detectToolingDaemon(ports[i].CommandLine)
 	}
+}
 
-	// Filter ports by path (exclude system directories) unless --all flag is set
-	filterStart := time.Now()
+// selectPorts applies the user-port, hidden and orphan filters shared by all port views
+func selectPorts(ports []PortInfo, config *Config) map[int][]PortInfo {
 	var filtered map[int][]PortInfo
-	if showAllPorts {
-		// Show all ports - put them in a dummy range
+	if showAllPorts || showSystemPorts || len(portFilter) > 0 {
+		// Show all ports - put them in a dummy range; ports asked for by number are
+		// shown wherever their process runs from
 		filtered = map[int][]PortInfo{0: ports}
 	} else {
 		// Filter by path - exclude system directories
@@ -267,50 +687,48 @@ func main() {
 		}
 		filtered = map[int][]PortInfo{0: userPorts}
 	}
-	if debugMode {
-		fmt.Printf("[DEBUG] Filtering ports: %v\n", time.Since(filterStart))
+
+	// Scope to --user, --match and --port if given
+	if userFilter != "" || matchRegex != nil || len(portFilter) > 0 {
+		var matching []PortInfo
+		for _, port := range filtered[0] {
+			if matchesUserFilter(port) && matchesRegexFilter(port) && matchesPortFilter(port) {
+				matching = append(matching, port)
+			}
+		}
+		filtered = map[int][]PortInfo{0: matching}
 	}
 
-	// Load config to filter hidden ports in non-interactive mode
-	config := loadConfig()
+	// Scope to --path if given
+	if pathFilter != "" {
+		var scoped []PortInfo
+		for _, port := range filtered[0] {
+			if isUnderPathFilter(port.Path) {
+				scoped = append(scoped, port)
+			}
+		}
+		filtered = map[int][]PortInfo{0: scoped}
+	}
 
 	// Filter out hidden ports from filtered list
 	filtered = filterHiddenPorts(filtered, config)
 
-	// Log newly discovered ports (only filtered ones, after hiding)
-	var filteredList []PortInfo
-	for _, portList := range filtered {
-		filteredList = append(filteredList, portList...)
+	// Narrow down to orphaned listeners if requested
+	if showOrphans {
+		filtered = filterOrphanedPorts(filtered)
 	}
-	logNewPorts(filteredList)
-
-	// Interactive mode or regular display
-	if interactive {
-		// Sort ports by uptime (descending - longest uptime first) for interactive mode
-		sort.Slice(ports, func(i, j int) bool {
-			return ports[i].UptimeSeconds > ports[j].UptimeSeconds
-		})
 
-		// Pass all ports to interactive mode
-		if err := runInteractive(ports); err != nil {
-			fmt.Printf("Error in interactive mode: %v\n", err)
-			os.Exit(1)
-		}
-	} else {
-		// Display results (already filtered above)
-		displayStart := time.Now()
-
-		if jsonOutput {
-			displayPortsJSON(filtered, sortBy)
-		} else {
-			displayPorts(filtered, sortBy)
-		}
+	// Fold tooling daemons (nx, turbo, pnpm, ...) unless asked for them
+	if !showDaemons && !showAllPorts && !showSystemPorts {
+		filtered = filterToolingDaemons(filtered)
+	}
 
-		if debugMode {
-			fmt.Printf("[DEBUG] Display: %v\n", time.Since(displayStart))
-			fmt.Printf("[DEBUG] Total time: %v\n", time.Since(startTime))
-		}
+	// Fold listeners outside filters.include_paths into "other"
+	if foldsOtherPorts() {
+		filtered = filterOtherPorts(filtered)
 	}
+
+	return filtered
 }
 
 func parseOutput(output string) []PortInfo {
@@ -367,17 +785,23 @@ func parseOutput(output string) []PortInfo {
 
 func getWorkingDirectory(pid string) string {
 	// Use optimized lsof flags: -a (AND), -d cwd (only cwd), -Fn (output format)
-	cmd := exec.Command("lsof", "-a", "-p", pid, "-d", "cwd", "-Fn")
-	output, err := cmd.Output()
+	output, err := commandOutput("lsof", "-a", "-p", pid, "-d", "cwd", "-Fn")
 	if err != nil {
-		return "N/A"
+		if !elevated() {
+			return "N/A"
+		}
+		// Other users' processes need root to read their cwd
+		if output, err = privilegedWorkingDirectory(pid); err != nil {
+			return "N/A"
+		}
 	}
 
 	// Output format: lines starting with 'n' contain the path
 	lines := strings.Split(string(output), "\n")
 	for _, line := range lines {
 		if strings.HasPrefix(line, "n") {
-			path := strings.TrimPrefix(line, "n")
+			// Linux lsof marks removed directories with a " (deleted)" suffix
+			path := strings.TrimSuffix(strings.TrimPrefix(line, "n"), " (deleted)")
 			if path != "" {
 				return path
 			}
@@ -387,9 +811,52 @@ func getWorkingDirectory(pid string) string {
 	return "N/A"
 }
 
+// isOrphanedPath reports whether a process working directory was deleted or renamed
+func isOrphanedPath(path string) bool {
+	if path == "N/A" || path == "/" || path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return os.IsNotExist(err)
+}
+
+// getCommandLines returns the full command line (ps args) of each PID
+func getCommandLines(pids []string) map[string]string {
+	commandLines := make(map[string]string)
+	if len(pids) == 0 {
+		return commandLines
+	}
+	// Sorted, so the same processes make the same ps call (and fixture key) every run
+	pids = append([]string(nil), pids...)
+	sort.Strings(pids)
+
+	// ps exits non-zero if any PID has already gone away, but still prints the rest
+	output, _ := commandOutput("ps", "-o", "pid=,args=", "-p", strings.Join(pids, ","))
+	for _, line := range strings.Split(string(output), "\n") {
+		pid, args, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if ok {
+			commandLines[pid] = strings.TrimSpace(args)
+		}
+	}
+	return commandLines
+}
+
+// processName returns the executable name, preferring the full command line over
+// lsof's COMMAND, which is truncated to 9 characters ("redis-ser")
+func processName(port PortInfo) string {
+	if fields := strings.Fields(port.CommandLine); len(fields) > 0 {
+		return filepath.Base(fields[0])
+	}
+	return port.Command
+}
+
+// killProcess sends SIGTERM to a process (a no-op in demo mode, whose PIDs are made up)
+func killProcess(pid string) error {
+	return signalProcess(pid, "TERM")
+}
+
 func getProcessUptime(pid string) (string, int) {
-	cmd := exec.Command("ps", "-p", pid, "-o", "etime=")
-	output, err := cmd.Output()
+	output, err := commandOutput("ps", "-p", pid, "-o", "etime=")
 	if err != nil {
 		return "N/A", 0
 	}
@@ -403,50 +870,12 @@ func getProcessUptime(pid string) (string, int) {
 }
 
 func formatUptime(etime string) (string, int) {
-	// etime format: [[DD-]HH:]MM:SS
-	// Examples: "5:23", "1:23:45", "3-12:34:56"
-
-	var days, hours, minutes, seconds int
-
-	// Check for days
-	if strings.Contains(etime, "-") {
-		parts := strings.Split(etime, "-")
-		fmt.Sscanf(parts[0], "%d", &days)
-		etime = parts[1]
-	}
-
-	// Parse remaining time
-	timeParts := strings.Split(etime, ":")
-	switch len(timeParts) {
-	case 2: // MM:SS
-		fmt.Sscanf(timeParts[0], "%d", &minutes)
-		fmt.Sscanf(timeParts[1], "%d", &seconds)
-	case 3: // HH:MM:SS
-		fmt.Sscanf(timeParts[0], "%d", &hours)
-		fmt.Sscanf(timeParts[1], "%d", &minutes)
-		fmt.Sscanf(timeParts[2], "%d", &seconds)
-	default:
+	// etime format: [[DD-]HH:]MM:SS, e.g. "5:23", "1:23:45", "3-12:34:56"
+	elapsed, err := durations.ParseEtime(etime)
+	if err != nil {
 		return etime, 0
 	}
-
-	// Calculate total seconds and hours
-	totalSeconds := days*86400 + hours*3600 + minutes*60 + seconds
-	totalHours := days*24 + hours
-	totalMinutes := totalHours*60 + minutes
-
-	// If less than 3 hours, show in minutes
-	if totalHours < 3 {
-		return fmt.Sprintf("%dm", totalMinutes), totalSeconds
-	}
-
-	// If more than 200 hours, show in days
-	if totalHours >= 200 {
-		totalDays := totalHours / 24
-		return fmt.Sprintf("%dd", totalDays), totalSeconds
-	}
-
-	// Otherwise show in hours
-	return fmt.Sprintf("%dh", totalHours), totalSeconds
+	return durations.Uptime.Format(elapsed), int(elapsed.Seconds())
 }
 
 func shortenPath(path string) string {
@@ -466,50 +895,59 @@ func shortenPath(path string) string {
 	return path
 }
 
-func isUserPort(port PortInfo) bool {
-	// Skip N/A and root paths
-	if port.Path == "N/A" || port.Path == "/" {
-		return false
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
 	}
+	return path
+}
 
-	// Exclude specific commands
-	excludeCommands := []string{
-		"redis-ser", // redis-server
+// resolvePathFilter turns a --path argument into a clean absolute path
+func resolvePathFilter(path string) string {
+	path = expandHome(path)
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
 	}
-	for _, cmd := range excludeCommands {
-		if port.Command == cmd {
-			return false
-		}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
 	}
+	return path
+}
 
-	// Expand tilde in path for comparison
-	expandedPath := port.Path
-	if strings.HasPrefix(expandedPath, "~/") {
-		home, _ := os.UserHomeDir()
-		expandedPath = filepath.Join(home, expandedPath[2:])
+// isUnderPathFilter reports whether path is inside the --path directory (always true without --path)
+func isUnderPathFilter(path string) bool {
+	if pathFilter == "" {
+		return true
 	}
+	return path == pathFilter || strings.HasPrefix(path, strings.TrimSuffix(pathFilter, "/")+"/")
+}
 
-	// Exclude system directories
-	excludePrefixes := []string{
-		"/opt/",
-		"/usr/",
-		"/System/",
-		"/Library/",
+// matchesRegexFilter reports whether --match (if given) matches any of the port's text fields
+func matchesRegexFilter(port PortInfo) bool {
+	if matchRegex == nil {
+		return true
 	}
-
-	for _, prefix := range excludePrefixes {
-		if strings.HasPrefix(expandedPath, prefix) {
-			return false
+	for _, field := range []string{port.Command, port.CommandLine, port.Address, port.Path} {
+		if matchRegex.MatchString(field) {
+			return true
 		}
 	}
+	return false
+}
 
-	// Exclude ~/Library/
-	home, _ := os.UserHomeDir()
-	if strings.HasPrefix(expandedPath, filepath.Join(home, "Library")) {
+func isUserPort(port PortInfo) bool {
+	// Skip N/A and root paths
+	if port.Path == "N/A" || port.Path == "/" {
 		return false
 	}
 
-	return true
+	// Exclude what [filters] in config.toml leaves out (system directories and redis by
+	// default), unless asked for it
+	return includeSystem || !loadSettings().Filters.excludes(port)
 }
 
 func filterPorts(ports []PortInfo, ranges []int) map[int][]PortInfo {
@@ -531,6 +969,9 @@ func filterPorts(ports []PortInfo, ranges []int) map[int][]PortInfo {
 }
 
 func displayPorts(portsByRange map[int][]PortInfo, sortOrder string) {
+	config := loadConfig()
+	applyTableLayout(config)
+
 	// Collect all ports into a single slice
 	var allPorts []PortInfo
 
@@ -539,6 +980,10 @@ func displayPorts(portsByRange map[int][]PortInfo, sortOrder string) {
 	}
 
 	if len(allPorts) == 0 {
+		if showOrphans {
+			fmt.Printf("\n%s%sNo orphaned listeners found%s\n\n", ColorBold, ColorGreen, ColorReset)
+			return
+		}
 		fmt.Printf("\n%s%sNo active ports found%s\n\n", ColorBold, ColorYellow, ColorReset)
 		return
 	}
@@ -556,16 +1001,128 @@ func displayPorts(portsByRange map[int][]PortInfo, sortOrder string) {
 		})
 	}
 
-	// Create table
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"PORT", "COMMAND", "PID", "UPTIME", "ADDRESS", "PATH"})
+	// Pinned projects and ports go first
+	sortPinnedFirst(allPorts, config)
+
+	// Keep dev servers of the same project together so they don't look unrelated
+	allPorts, groupStart, groupEnd, overlapWarnings := groupOverlappingProjects(allPorts)
+	overlapWarnings = append(overlapWarnings, projectFileProblems(allPorts)...)
+	var rangeSections map[string]int
+	if groupByRange {
+		// Range sections replace the project groups; the warnings still apply
+		rangeSections = portRangeSections(allPorts, portRanges())
+		groupStart, groupEnd = nil, nil
+	}
+
+	// Only show the NAME, TYPE, TERMINAL and OWNER columns when at least one port needs them
+	showUser := shouldShowUserColumn(allPorts)
+	me := currentUsername()
+	showName, showType, showTerminal, showOwner := false, false, false, false
+	showHealth := grpcHealth
+	showLastCommand, showRole, showPinned, showNote := false, false, false, false
+	if config.showsColumn("cpu") {
+		resolveCPUUsage(allPorts)
+	}
+	if config.showsColumn("branch") {
+		resolveGitBranches(allPorts)
+	}
+	for _, port := range allPorts {
+		showPinned = showPinned || config.isPinnedPort(port)
+		showNote = showNote || port.Note != ""
+		showLastCommand = showLastCommand || port.LastCommand != ""
+		showRole = showRole || port.Role != "" || port.Daemon != ""
+		showName = showName || port.Name != ""
+		showType = showType || port.Tunnel != ""
+		showTerminal = showTerminal || port.Terminal != ""
+		showOwner = showOwner || port.Owner != ""
+	}
+	if wideTable {
+		showUser, showRole, showNote, showLastCommand, showTerminal, showOwner = true, true, true, true, true, true
+	} else if compactTable {
+		showPinned, showName, showType, showRole, showUser = false, false, false, false, false
+		showNote, showLastCommand, showTerminal, showOwner = false, false, false, false
+	}
+
+	// Create table; --by-range makes one per range section
+	newPortTable := func() table.Writer {
+		t := newTable(table.StyleDefault)
+		t.SetOutputMirror(os.Stdout)
+		if compactTable {
+			compactTableStyle(t)
+		}
+		return t
+	}
+	t := newPortTable()
+	sectionTables := make(map[int]table.Writer)
+	sectionCounts := make(map[int]int)
+	var header table.Row
+	if showPinned {
+		header = append(header, "")
+	}
+	header = append(header, "PORT")
+	if showName {
+		header = append(header, "NAME")
+	}
+	if showType {
+		header = append(header, "TYPE")
+	}
+	if config.showsColumn("command") {
+		header = append(header, "COMMAND")
+	}
+	if showRole {
+		header = append(header, "ROLE")
+	}
+	if config.showsColumn("pid") {
+		header = append(header, "PID")
+	}
+	if showUser {
+		header = append(header, "USER")
+	}
+	// Columns chosen with "columns" in portage.json (columns.go)
+	for _, column := range []string{"uptime", "cpu", "address", "branch", "path"} {
+		if config.showsColumn(column) {
+			header = append(header, columnTitle(column))
+		}
+	}
+	if showNote {
+		header = append(header, "NOTE")
+	}
+	if showHealth {
+		header = append(header, "HEALTH")
+	}
+	if httpCheck {
+		header = append(header, "CHECK")
+	}
+	if showBrowserTabs {
+		header = append(header, "TABS")
+	}
+	if showLastCommand {
+		header = append(header, "LAST COMMAND")
+	}
+	if showType {
+		header = append(header, "TUNNEL")
+	}
+	if showTerminal {
+		header = append(header, "TERMINAL")
+	}
+	if showOwner {
+		header = append(header, "OWNER")
+	}
+	if wideTable {
+		header = append(header, "COMMAND LINE")
+	}
+	t.AppendHeader(header)
 
 	// Add rows
 	seen := make(map[string]bool)
-	for _, port := range allPorts {
-		// Skip processes with root path
-		if port.Path == "/" {
+	elevatedRows := 0
+	for i, port := range allPorts {
+		if i > 0 && (groupStart[i] || groupEnd[i-1]) {
+			t.AppendSeparator()
+		}
+
+		// Skip processes with root path (system daemons) unless asked for them
+		if port.Path == "/" && !showSystemPorts {
 			continue
 		}
 
@@ -577,24 +1134,274 @@ func displayPorts(portsByRange map[int][]PortInfo, sortOrder string) {
 		seen[key] = true
 
 		pathDisplay := shortenPath(port.Path)
+		if wideTable {
+			pathDisplay = port.Path
+		}
 		if pathDisplay == "N/A" {
 			pathDisplay = "-"
 		}
+		if port.Orphaned {
+			pathDisplay += " (missing)"
+		}
 
-		t.AppendRow(table.Row{
-			port.Port,
-			port.Command,
-			port.PID,
-			port.Uptime,
-			port.Address,
-			pathDisplay,
-		})
+		var row table.Row
+		if showPinned {
+			pin := ""
+			if config.isPinnedPort(port) {
+				pin = strings.TrimSpace(pinnedMarker)
+			}
+			row = append(row, pin)
+		}
+		row = append(row, port.Port)
+		if showName {
+			name := port.Name
+			if name == "" {
+				name = "-"
+			}
+			row = append(row, name)
+		}
+		if showType {
+			portType := port.Type
+			if portType == "" {
+				portType = "server"
+			}
+			row = append(row, portType)
+		}
+		pid := port.PID
+		if port.Elevated && config.showsColumn("pid") {
+			pid += "*"
+			elevatedRows++
+		}
+		if config.showsColumn("command") {
+			row = append(row, port.Command)
+		}
+		if showRole {
+			role := port.Role
+			if port.Daemon != "" {
+				role = port.Daemon + " daemon"
+			}
+			if role == "" {
+				role = "-"
+			}
+			row = append(row, role)
+		}
+		if config.showsColumn("pid") {
+			row = append(row, pid)
+		}
+		if showUser {
+			row = append(row, formatUser(port.User, me))
+		}
+		for _, column := range []string{"uptime", "cpu", "address", "branch"} {
+			if config.showsColumn(column) {
+				row = append(row, portCell(port, column))
+			}
+		}
+		if config.showsColumn("path") {
+			row = append(row, pathDisplay)
+		}
+		if showNote {
+			note := "-"
+			if port.Note != "" && wideTable {
+				note = port.Note
+			} else if port.Note != "" {
+				note = truncate(port.Note, 30)
+			}
+			row = append(row, note)
+		}
+		if showHealth {
+			row = append(row, formatHealth(port.Health))
+		}
+		if httpCheck {
+			row = append(row, formatCheck(port.Check))
+		}
+		if showBrowserTabs {
+			tabs := "-"
+			if port.BrowserTabs > 0 {
+				tabs = strconv.Itoa(port.BrowserTabs)
+			}
+			row = append(row, tabs)
+		}
+		if showLastCommand {
+			lastCommand := port.LastCommand
+			if lastCommand == "" {
+				lastCommand = "-"
+			}
+			if !wideTable {
+				lastCommand = truncate(lastCommand, 40)
+			}
+			row = append(row, lastCommand)
+		}
+		if showType {
+			tunnel := port.Tunnel
+			if tunnel == "" {
+				tunnel = "-"
+			}
+			row = append(row, tunnel)
+		}
+		if showTerminal {
+			terminal := port.Terminal
+			if terminal == "" {
+				terminal = "-"
+			}
+			row = append(row, terminal)
+		}
+		if showOwner {
+			owner := port.Owner
+			if owner == "" {
+				owner = "-"
+			}
+			row = append(row, owner)
+		}
+		if wideTable {
+			commandLine := port.CommandLine
+			if commandLine == "" {
+				commandLine = "-"
+			}
+			row = append(row, commandLine)
+		}
+		if groupByRange {
+			section := rangeSections[key]
+			if sectionTables[section] == nil {
+				sectionTables[section] = newPortTable()
+				sectionTables[section].AppendHeader(header)
+			}
+			sectionTables[section].AppendRow(row)
+			sectionCounts[section]++
+			continue
+		}
+		t.AppendRow(row)
 	}
 
-	// Render table
+	// Render table, or a headed table per range section in range order
+	if groupByRange {
+		for _, start := range append(portRanges(), otherPortRange) {
+			if sectionTables[start] == nil {
+				continue
+			}
+			unit := "ports"
+			if sectionCounts[start] == 1 {
+				unit = "port"
+			}
+			fmt.Printf("\n%s%s%s%s (%d %s)\n", ColorBold, ColorCyan, portRangeTitle(start), ColorReset, sectionCounts[start], unit)
+			sectionTables[start].Render()
+		}
+	} else {
+		fmt.Println()
+		t.Render()
+	}
+	fmt.Printf("\n%s%sTotal: %d ports%s\n", ColorBold, ColorCyan, len(seen), ColorReset)
+	if elevatedRows > 0 {
+		fmt.Printf("* %d only visible with elevated privileges (--sudo)\n", elevatedRows)
+	}
+	for _, warning := range overlapWarnings {
+		fmt.Printf("%s! %s%s\n", ColorYellow, warning, ColorReset)
+	}
 	fmt.Println()
-	t.Render()
-	fmt.Printf("\n%s%sTotal: %d ports%s\n\n", ColorBold, ColorCyan, len(seen), ColorReset)
+}
+
+// collectSortedPorts flattens ports by range into a single slice sorted by the given order
+func collectSortedPorts(portsByRange map[int][]PortInfo, sortOrder string) []PortInfo {
+	var allPorts []PortInfo
+	for _, ports := range portsByRange {
+		allPorts = append(allPorts, ports...)
+	}
+
+	if sortOrder == "port" {
+		sort.Slice(allPorts, func(i, j int) bool {
+			return allPorts[i].Port < allPorts[j].Port
+		})
+	} else {
+		sort.Slice(allPorts, func(i, j int) bool {
+			return allPorts[i].UptimeSeconds > allPorts[j].UptimeSeconds
+		})
+	}
+
+	return allPorts
+}
+
+// displayPortsGrouped renders one table per project directory (git root, or the
+// working directory outside of git) with the branch in the project header
+func displayPortsGrouped(portsByRange map[int][]PortInfo, sortOrder string) {
+	config := loadConfig()
+	allPorts := collectSortedPorts(portsByRange, sortOrder)
+	sortPinnedFirst(allPorts, config)
+
+	projects, groups := groupPortsByProject(allPorts)
+
+	if len(projects) == 0 {
+		fmt.Printf("\n%s%sNo active ports found%s\n\n", ColorBold, ColorYellow, ColorReset)
+		return
+	}
+
+	for _, root := range projects {
+		ports := groups[root]
+
+		// Project header: path and git branch
+		fmt.Println()
+		if root == "" {
+			fmt.Printf("%s%sUnknown project%s\n", ColorBold, ColorYellow, ColorReset)
+		} else {
+			header := shortenPath(root)
+			if config.isPinned(root) {
+				header = pinnedMarker + header
+			}
+			if branch := gitBranch(root); branch != "" {
+				header += fmt.Sprintf(" %s(%s)%s", ColorPurple, branch, ColorBold+ColorCyan)
+			}
+			fmt.Printf("%s%s%s%s\n", ColorBold, ColorCyan, header, ColorReset)
+		}
+
+		t := newTable(table.StyleDefault)
+		t.SetOutputMirror(os.Stdout)
+		t.AppendHeader(table.Row{"PORT", "COMMAND", "PID", "UPTIME", "ADDRESS", "DIR"})
+		for _, port := range ports {
+			// Show the working directory relative to the project root
+			dir := "-"
+			if root != "" {
+				if rel, err := filepath.Rel(root, port.Path); err == nil {
+					dir = rel
+				}
+			}
+			if port.Orphaned {
+				dir += " (missing)"
+			}
+
+			t.AppendRow(table.Row{port.Port, port.Command, port.PID, port.Uptime, port.Address, dir})
+		}
+		t.Render()
+	}
+
+	total := 0
+	for _, ports := range groups {
+		total += len(ports)
+	}
+	fmt.Printf("\n%s%sTotal: %d ports in %d projects%s\n\n", ColorBold, ColorCyan, total, len(projects), ColorReset)
+}
+
+// groupPortsByProject groups ports by project root (see projectRoot), keeping groups in
+// the order of their first port. Ports without a real working directory are left out,
+// and a process listening on IPv4 and IPv6 counts once.
+func groupPortsByProject(allPorts []PortInfo) ([]string, map[string][]PortInfo) {
+	var projects []string
+	groups := make(map[string][]PortInfo)
+	seen := make(map[string]bool)
+	for _, port := range allPorts {
+		if port.Path == "/" {
+			continue
+		}
+		key := fmt.Sprintf("%d-%s", port.Port, port.PID)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		root := projectRoot(port.Path)
+		if _, exists := groups[root]; !exists {
+			projects = append(projects, root)
+		}
+		groups[root] = append(groups[root], port)
+	}
+	return projects, groups
 }
 
 func getPortColor(port int) string {
@@ -602,10 +1409,11 @@ func getPortColor(port int) string {
 }
 
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
 		return s
 	}
-	return s[:maxLen-3] + "..."
+	return string(runes[:maxLen-3]) + "..."
 }
 
 func countTotal(portsByRange map[int][]PortInfo) int {
@@ -622,8 +1430,7 @@ func filterHiddenPorts(portsByRange map[int][]PortInfo, config *Config) map[int]
 	for rangeStart, ports := range portsByRange {
 		filtered[rangeStart] = []PortInfo{}
 		for _, port := range ports {
-			key := fmt.Sprintf("%d-%s", port.Port, port.PID)
-			if !config.HiddenPorts[key] {
+			if !config.isHidden(port) {
 				filtered[rangeStart] = append(filtered[rangeStart], port)
 			}
 		}
@@ -632,61 +1439,38 @@ func filterHiddenPorts(portsByRange map[int][]PortInfo, config *Config) map[int]
 	return filtered
 }
 
-func getLogPath() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".portage.log")
-}
-
-func logNewPorts(ports []PortInfo) {
-	logPath := getLogPath()
+func filterOrphanedPorts(portsByRange map[int][]PortInfo) map[int][]PortInfo {
+	filtered := make(map[int][]PortInfo)
 
-	// Load previously seen path+port combinations
-	seenCombos := make(map[string]bool)
-	if data, err := os.ReadFile(logPath); err == nil {
-		lines := strings.Split(string(data), "\n")
-		for _, line := range lines {
-			if line == "" {
-				continue
-			}
-			// Extract port and path from log line
-			parts := strings.Split(line, "\t")
-			if len(parts) >= 5 {
-				key := parts[1] + ":" + parts[4] // port:path
-				seenCombos[key] = true
+	for rangeStart, ports := range portsByRange {
+		filtered[rangeStart] = []PortInfo{}
+		for _, port := range ports {
+			if port.Orphaned {
+				filtered[rangeStart] = append(filtered[rangeStart], port)
 			}
 		}
 	}
 
-	// Check for new path+port combinations and log them
-	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return // Silently fail if we can't write log
-	}
-	defer f.Close()
+	return filtered
+}
 
-	now := time.Now()
-	for _, port := range ports {
-		// Skip N/A and root paths
-		if port.Path == "N/A" || port.Path == "/" {
-			continue
-		}
+// getLogPath returns [logs] ports in config.toml: the tab-separated log older versions
+// kept, imported into history.db once (historydb.go)
+func getLogPath() string {
+	return expandHome(loadSettings().Logs.Ports)
+}
 
-		key := fmt.Sprintf("%d:%s", port.Port, port.Path)
-		if !seenCombos[key] {
-			// Calculate when the process was actually started (now - uptime)
-			startTime := now.Add(-time.Duration(port.UptimeSeconds) * time.Second)
-			startTimeStr := startTime.Format("2006-01-02 15:04:05")
+func displayPortsJSON(portsByRange map[int][]PortInfo, sortOrder string) {
+	filtered := sortedPortList(portsByRange, sortOrder)
 
-			// New path+port combination discovered, log it with actual start time
-			logLine := fmt.Sprintf("%s\t%d\t%s\t%s\t%s\n",
-				startTimeStr, port.Port, port.PID, port.Command, port.Path)
-			f.WriteString(logLine)
-			seenCombos[key] = true // Mark as seen to avoid duplicates in same run
-		}
-	}
+	// With --timings the report goes next to the ports
+	envelope := newJSONEnvelope("ports", filtered)
+	envelope.Timings = timings.json()
+	writeEnvelope(envelope)
 }
 
-func displayPortsJSON(portsByRange map[int][]PortInfo, sortOrder string) {
+// sortedPortList flattens the selected ports in the --sort order, as the JSON output lists them
+func sortedPortList(portsByRange map[int][]PortInfo, sortOrder string) []PortInfo {
 	// Collect all ports into a single slice
 	var allPorts []PortInfo
 
@@ -708,84 +1492,113 @@ func displayPortsJSON(portsByRange map[int][]PortInfo, sortOrder string) {
 	// Filter out root paths
 	var filtered []PortInfo
 	for _, port := range allPorts {
-		if port.Path != "/" {
+		if port.Path != "/" || showSystemPorts {
 			filtered = append(filtered, port)
 		}
 	}
-
-	// Output as JSON
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(filtered); err != nil {
-		fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-	}
+	return filtered
 }
 
-type HistoryEntry struct {
-	Timestamp string
-	Port      int
-	PID       string
-	Command   string
-	Path      string
+// portHistoryJSON is a port event in `portage history --json`
+type portHistoryJSON struct {
+	StartedAt       string `json:"started_at"` // RFC 3339
+	Port            int    `json:"port"`
+	PID             string `json:"pid"`
+	Command         string `json:"command"`
+	CommandLine     string `json:"command_line"` // "" if recorded before portage kept it
+	User            string `json:"user"`
+	Path            string `json:"path"`
+	LifetimeSeconds *int   `json:"lifetime_seconds"` // null when not known
+	Running         bool   `json:"running"`
 }
 
-func displayHistory() {
-	logPath := getLogPath()
+func newPortHistoryJSON(event PortEvent, lifetimes portLifetimes) portHistoryJSON {
+	entry := portHistoryJSON{
+		StartedAt:   event.Started.Format(time.RFC3339),
+		Port:        event.Port,
+		PID:         event.PID,
+		Command:     event.Command,
+		CommandLine: event.CommandLine,
+		User:        event.User,
+		Path:        event.Path,
+	}
+	if lifetime, ok := lifetimes.of(event); ok {
+		seconds := int(lifetime.Duration.Seconds())
+		entry.LifetimeSeconds, entry.Running = &seconds, lifetime.Running
+	}
+	return entry
+}
 
-	data, err := os.ReadFile(logPath)
+func displayHistory(filter historyFilter) {
+	// Listeners started before --since may have still been up in it
+	events, err := queryEvents(eventQuery{Types: []string{EventPortStart}, Until: filter.Until})
 	if err != nil {
-		fmt.Printf("\n%s%sNo history found. Run portage to start logging.%s\n\n", ColorBold, ColorYellow, ColorReset)
-		return
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", shortenPath(getHistoryPath()), err)
+		os.Exit(1)
 	}
 
-	lines := strings.Split(string(data), "\n")
-	var entries []HistoryEntry
-
-	for _, line := range lines {
-		if line == "" {
-			continue
+	lifetimes := loadPortLifetimes(time.Now())
+	var entries []PortEvent
+	for _, event := range events {
+		// Filter using isUserPort logic
+		if isUserPort(PortInfo{Port: event.Port.Port, Command: event.Port.Command, Path: event.Path}) && filter.matches(*event.Port, lifetimes) {
+			entries = append(entries, *event.Port)
 		}
-		parts := strings.Split(line, "\t")
-		if len(parts) >= 5 {
-			port, _ := strconv.Atoi(parts[1])
-			entry := HistoryEntry{
-				Timestamp: parts[0],
-				Port:      port,
-				PID:       parts[2],
-				Command:   parts[3],
-				Path:      parts[4],
-			}
+	}
 
-			// Filter using isUserPort logic
-			portInfo := PortInfo{
-				Port:    port,
-				Command: entry.Command,
-				Path:    entry.Path,
-			}
-			if isUserPort(portInfo) {
-				entries = append(entries, entry)
-			}
+	if jsonOutput {
+		history := []portHistoryJSON{}
+		for i := len(entries) - 1; i >= 0; i-- {
+			history = append(history, newPortHistoryJSON(entries[i], lifetimes))
 		}
+		writeJSON("port_history", history)
+		return
 	}
 
 	if len(entries) == 0 {
-		fmt.Printf("\n%s%sNo history entries found.%s\n\n", ColorBold, ColorYellow, ColorReset)
+		if description := filter.describe(); description != "" {
+			fmt.Printf("\n%s%sNo history entries %s.%s\n\n", ColorBold, ColorYellow, description, ColorReset)
+			return
+		}
+		fmt.Printf("\n%s%sNo history found. Run portage to start logging.%s\n\n", ColorBold, ColorYellow, ColorReset)
 		return
 	}
 
 	// Print header
-	fmt.Printf("\n%s%sPORTAGE - Discovery History%s\n\n", ColorBold, ColorCyan, ColorReset)
+	header := "PORTAGE - Discovery History"
+	if description := filter.describe(); description != "" {
+		header += " " + description
+	}
+	fmt.Printf("\n%s%s%s%s\n\n", ColorBold, ColorCyan, header, ColorReset)
 
 	// Create table
-	t := table.NewWriter()
-	t.SetStyle(table.StyleRounded)
-	t.AppendHeader(table.Row{"STARTED", "PORT", "COMMAND", "PATH"})
+	t := newTable(table.StyleRounded)
+	t.AppendHeader(table.Row{"STARTED", "PORT", "COMMAND", "USER", "LIFETIME", "PATH", "COMMAND LINE"})
 
 	// Print entries (most recent first)
+	me := currentUsername()
 	for i := len(entries) - 1; i >= 0; i-- {
 		entry := entries[i]
 		pathDisplay := shortenPath(entry.Path)
-		t.AppendRow(table.Row{entry.Timestamp, entry.Port, entry.Command, pathDisplay})
+		lifetimeDisplay := "-"
+		if lifetime, ok := lifetimes.of(entry); ok {
+			lifetimeDisplay = durations.Uptime.Format(lifetime.Duration)
+			if lifetime.Running {
+				lifetimeDisplay += " (running)"
+			}
+		}
+		user := formatUser(entry.User, me)
+		if user == "" {
+			user = "-"
+		}
+		commandLine := entry.CommandLine
+		switch {
+		case commandLine == "":
+			commandLine = "-" // recorded before portage kept command lines
+		case !wideTable:
+			commandLine = truncate(commandLine, 50)
+		}
+		t.AppendRow(table.Row{entry.Started.Format("2006-01-02 15:04:05"), entry.Port, entry.Command, user, lifetimeDisplay, pathDisplay, commandLine})
 	}
 
 	fmt.Println(t.Render())
@@ -803,6 +1616,7 @@ type UnifiedItem struct {
 	WorkspacePath string     `json:"workspace_path,omitempty"`
 	WorkspaceName string     `json:"workspace_name,omitempty"`
 	LastActive    int64      `json:"last_active,omitempty"`
+	Pinned        bool       `json:"pinned,omitempty"`
 	Ports         []PortJSON `json:"ports,omitempty"`
 }
 
@@ -812,83 +1626,83 @@ type PortJSON struct {
 	PID     string `json:"pid"`
 	Uptime  string `json:"uptime"`
 	WorkDir string `json:"workdir"`
+	Address string `json:"address,omitempty"`
+	Type    string `json:"type,omitempty"`   // "ssh-tunnel" for ssh -L listeners
+	Tunnel  string `json:"tunnel,omitempty"` // forwarding details
+	Args    string `json:"command_line,omitempty"`
+}
+
+func newPortJSON(port PortInfo) PortJSON {
+	return PortJSON{
+		Port:    port.Port,
+		Command: port.Command,
+		PID:     port.PID,
+		Uptime:  port.Uptime,
+		WorkDir: port.Path,
+		Address: port.Address,
+		Type:    port.Type,
+		Tunnel:  port.Tunnel,
+		Args:    port.CommandLine,
+	}
+}
+
+// portInfo converts back for the actions shared with interactive mode
+func (p PortJSON) portInfo() PortInfo {
+	return PortInfo{
+		Port:        p.Port,
+		Command:     p.Command,
+		PID:         p.PID,
+		Uptime:      p.Uptime,
+		Path:        p.WorkDir,
+		Address:     p.Address,
+		Type:        p.Type,
+		Tunnel:      p.Tunnel,
+		CommandLine: p.Args,
+	}
 }
 
 func getOpenCursorWindows() map[string]bool {
 	// Get list of open Cursor windows via AppleScript
-	cmd := exec.Command("osascript", "-e", `tell application "System Events" to get name of every window of application process "Cursor"`)
-	output, err := cmd.Output()
+	output, err := commandOutput("osascript", "-e", `tell application "System Events" to get name of every window of application process "Cursor"`)
 	if err != nil {
 		return nil
 	}
 
 	// Parse output: "filename — project-name, filename — project-name, ..."
 	// With new window.title setting, it could be: "filename — ~/path/to/workspace"
-	// We store both full paths and basenames for matching
 	openProjects := make(map[string]bool)
 
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		homeDir = ""
-	}
-
 	// Split by comma
 	windows := strings.Split(string(output), ",")
 	for _, window := range windows {
-		window = strings.TrimSpace(window)
-		// Split by " — " to find the path part
-		// Window format: "filename — ~/path/to/workspace" or "filename — ~/path/to/workspace — ExtraInfo"
-		parts := strings.Split(window, " — ")
-
-		// Find the part that looks like a path (starts with ~/ or /)
-		var pathOrName string
-		for _, part := range parts {
-			part = strings.TrimSpace(part)
-			if strings.HasPrefix(part, "~/") || strings.HasPrefix(part, "/") {
-				pathOrName = part
-				break
-			}
-		}
-
-		if pathOrName != "" {
-			// Expand ~ to home directory
-			if strings.HasPrefix(pathOrName, "~/") && homeDir != "" {
-				pathOrName = filepath.Join(homeDir, pathOrName[2:])
-			}
-
-			openProjects[pathOrName] = true
+		if path := windowTitlePath(window); path != "" {
+			openProjects[path] = true
 		}
 	}
 
 	return openProjects
 }
 
-func displayCursorWindows() {
+// loadCursorWorkspaces returns the open Cursor workspaces (or the 10 most recently
+// active ones when open windows can't be listed), least recently active first.
+// openOnly reports whether the list was narrowed down to open windows.
+func loadCursorWorkspaces() (workspaces []CursorWorkspace, openOnly bool, err error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		fmt.Printf("Error getting home directory: %v\n", err)
-		return
+		return nil, false, err
 	}
 
 	workspaceStoragePath := filepath.Join(homeDir, "Library", "Application Support", "Cursor", "User", "workspaceStorage")
 
-	// Check if directory exists
-	if _, err := os.Stat(workspaceStoragePath); os.IsNotExist(err) {
-		fmt.Printf("\n%s%sNo Cursor workspace storage found%s\n\n", ColorBold, ColorYellow, ColorReset)
-		return
-	}
-
-	// Get list of actually open windows
-	openProjects := getOpenCursorWindows()
-
 	// Read workspace directories
 	entries, err := os.ReadDir(workspaceStoragePath)
 	if err != nil {
-		fmt.Printf("Error reading workspace storage: %v\n", err)
-		return
+		return nil, false, err
 	}
 
-	var workspaces []CursorWorkspace
+	// Get list of actually open windows
+	openProjects := getOpenCursorWindows()
+	openOnly = len(openProjects) > 0
 
 	for _, entry := range entries {
 		if !entry.IsDir() {
@@ -935,10 +1749,13 @@ func displayCursorWindows() {
 		}
 
 		// If we have a list of open projects, filter by it
-		if openProjects != nil && len(openProjects) > 0 {
-			if !openProjects[folderPath] {
-				continue // Skip workspaces that are not open
-			}
+		if openOnly && !openProjects[folderPath] {
+			continue // Skip workspaces that are not open
+		}
+
+		// Scope to --path if given
+		if !isUnderPathFilter(folderPath) {
+			continue
 		}
 
 		workspaces = append(workspaces, CursorWorkspace{
@@ -947,14 +1764,7 @@ func displayCursorWindows() {
 		})
 	}
 
-	if len(workspaces) == 0 {
-		if openProjects != nil && len(openProjects) > 0 {
-			fmt.Printf("\n%s%sNo open Cursor windows found%s\n\n", ColorBold, ColorYellow, ColorReset)
-		} else {
-			fmt.Printf("\n%s%sNo Cursor workspaces found%s\n\n", ColorBold, ColorYellow, ColorReset)
-		}
-		return
-	}
+	applyRecordedActivity(workspaces)
 
 	// Deduplicate by path, keeping the most recent modification time for each path
 	workspaceMap := make(map[string]CursorWorkspace)
@@ -981,10 +1791,31 @@ func displayCursorWindows() {
 	})
 
 	// Take top 10 only if we're not filtering by open windows
-	if openProjects == nil || len(openProjects) == 0 {
-		if len(workspaces) > 10 {
-			workspaces = workspaces[:10]
+	if !openOnly && len(workspaces) > 10 {
+		workspaces = workspaces[:10]
+	}
+
+	return workspaces, openOnly, nil
+}
+
+func displayCursorWindows() {
+	workspaces, openOnly, err := loadCursorWorkspaces()
+	if os.IsNotExist(err) {
+		fmt.Printf("\n%s%sNo Cursor workspace storage found%s\n\n", ColorBold, ColorYellow, ColorReset)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error reading workspace storage: %v\n", err)
+		return
+	}
+
+	if len(workspaces) == 0 {
+		if openOnly {
+			fmt.Printf("\n%s%sNo open Cursor windows found%s\n\n", ColorBold, ColorYellow, ColorReset)
+		} else {
+			fmt.Printf("\n%s%sNo Cursor workspaces found%s\n\n", ColorBold, ColorYellow, ColorReset)
 		}
+		return
 	}
 
 	now := time.Now()
@@ -1009,35 +1840,20 @@ func displayCursorWindows() {
 			})
 		}
 
-		encoder := json.NewEncoder(os.Stdout)
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(jsonWorkspaces); err != nil {
-			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-		}
+		writeJSON("workspaces", jsonWorkspaces)
 		return
 	}
 
 	// Display table
 	fmt.Printf("\n%s%sCURSOR - Active Windows%s\n\n", ColorBold, ColorCyan, ColorReset)
 
-	t := table.NewWriter()
-	t.SetStyle(table.StyleRounded)
+	t := newTable(table.StyleRounded)
 	t.AppendHeader(table.Row{"#", "LAST ACTIVE", "PROJECT"})
 
 	for i, ws := range workspaces {
 		duration := now.Sub(ws.LastModified)
 
-		var timeStr string
-		if duration < time.Minute {
-			timeStr = "just now"
-		} else if duration < time.Hour {
-			timeStr = fmt.Sprintf("%dm ago", int(duration.Minutes()))
-		} else if duration < 24*time.Hour {
-			timeStr = fmt.Sprintf("%dh ago", int(duration.Hours()))
-		} else {
-			days := int(duration.Hours() / 24)
-			timeStr = fmt.Sprintf("%dd ago", days)
-		}
+		timeStr := durations.Recency.Ago(duration)
 
 		pathDisplay := shortenPath(ws.Path)
 		t.AppendRow(table.Row{i + 1, timeStr, pathDisplay})
@@ -1048,9 +1864,14 @@ func displayCursorWindows() {
 }
 
 func displayUnified() {
+	writeJSON("unified", collectUnified())
+}
+
+// collectUnified matches listening ports to the open Cursor workspaces they run under.
+// Ports outside every workspace end up in a trailing "orphaned" item.
+func collectUnified() []UnifiedItem {
 	// Get all ports
-	cmd := exec.Command("lsof", "-i", "-P", "-n")
-	output, err := cmd.Output()
+	output, err := commandOutput("lsof", "-i", "-P", "-n")
 	if err != nil {
 		fmt.Printf("Error executing lsof: %v\n", err)
 		os.Exit(1)
@@ -1058,26 +1879,15 @@ func displayUnified() {
 
 	ports := parseOutput(string(output))
 
-	// Get working directory and uptime for each port
-	pathCache := make(map[string]string)
-	uptimeCache := make(map[string]string)
-	for i := range ports {
-		if cachedPath, exists := pathCache[ports[i].PID]; exists {
-			ports[i].Path = cachedPath
-			ports[i].Uptime = uptimeCache[ports[i].PID]
-		} else {
-			ports[i].Path = getWorkingDirectory(ports[i].PID)
-			uptimeStr, _ := getProcessUptime(ports[i].PID)
-			ports[i].Uptime = uptimeStr
-			pathCache[ports[i].PID] = ports[i].Path
-			uptimeCache[ports[i].PID] = uptimeStr
-		}
-	}
+	// Get working directory, uptime and command line for each port
+	enrichPorts(ports, nil)
+
+	annotateSSHTunnels(ports)
 
 	// Filter to user ports only
 	var userPorts []PortInfo
 	for _, port := range ports {
-		if isUserPort(port) {
+		if isUserPort(port) && isUnderPathFilter(port.Path) {
 			userPorts = append(userPorts, port)
 		}
 	}
@@ -1086,7 +1896,7 @@ func displayUnified() {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		fmt.Printf("Error getting home directory: %v\n", err)
-		return
+		return nil
 	}
 
 	workspaceStoragePath := filepath.Join(homeDir, "Library", "Application Support", "Cursor", "User", "workspaceStorage")
@@ -1139,6 +1949,11 @@ func displayUnified() {
 				}
 			}
 
+			// Scope to --path if given
+			if !isUnderPathFilter(folderPath) {
+				continue
+			}
+
 			workspaces = append(workspaces, CursorWorkspace{
 				Path:         folderPath,
 				LastModified: statInfo.ModTime(),
@@ -1146,9 +1961,12 @@ func displayUnified() {
 		}
 	}
 
+	applyRecordedActivity(workspaces)
+
 	// Match ports to workspaces
 	workspaceMap := make(map[string]*UnifiedItem)
 	now := time.Now()
+	config := loadConfig()
 
 	// Create workspace items
 	for _, ws := range workspaces {
@@ -1158,6 +1976,7 @@ func displayUnified() {
 			WorkspacePath: ws.Path,
 			WorkspaceName: filepath.Base(ws.Path),
 			LastActive:    secondsSinceActive,
+			Pinned:        config.isPinned(ws.Path),
 			Ports:         []PortJSON{},
 		}
 	}
@@ -1170,38 +1989,29 @@ func displayUnified() {
 		// Try to match port to workspace by checking if port's path is under workspace path
 		for wsPath, item := range workspaceMap {
 			if strings.HasPrefix(port.Path, wsPath) {
-				item.Ports = append(item.Ports, PortJSON{
-					Port:    port.Port,
-					Command: port.Command,
-					PID:     port.PID,
-					Uptime:  port.Uptime,
-					WorkDir: port.Path,
-				})
+				item.Ports = append(item.Ports, newPortJSON(port))
 				matched = true
 				break
 			}
 		}
 
 		if !matched {
-			orphanedPorts = append(orphanedPorts, PortJSON{
-				Port:    port.Port,
-				Command: port.Command,
-				PID:     port.PID,
-				Uptime:  port.Uptime,
-				WorkDir: port.Path,
-			})
+			orphanedPorts = append(orphanedPorts, newPortJSON(port))
 		}
 	}
 
 	// Build result list
 	var result []UnifiedItem
 
-	// Add workspaces (sorted by last active)
+	// Add workspaces (pinned first, then by last active)
 	var workspaceItems []UnifiedItem
 	for _, item := range workspaceMap {
 		workspaceItems = append(workspaceItems, *item)
 	}
 	sort.Slice(workspaceItems, func(i, j int) bool {
+		if workspaceItems[i].Pinned != workspaceItems[j].Pinned {
+			return workspaceItems[i].Pinned
+		}
 		return workspaceItems[i].LastActive < workspaceItems[j].LastActive
 	})
 	result = append(result, workspaceItems...)
@@ -1214,12 +2024,7 @@ func displayUnified() {
 		})
 	}
 
-	// Output JSON
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(result); err != nil {
-		fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-	}
+	return result
 }
 
 type CursorHistoryEntry struct {
@@ -1230,90 +2035,137 @@ type CursorHistory struct {
 	Entries []CursorHistoryEntry `json:"entries"`
 }
 
-type RecentlyClosedWorkspace struct {
-	Path string `json:"path"`
-}
-
-// WorkspaceEvent represents a workspace event (open or close)
-type WorkspaceEvent struct {
-	Path      string
-	Event     string // "open" or "close"
-	Timestamp int64  // Unix timestamp
-}
-
-// getWorkspaceLogPath returns the path to the workspace log file
-func getWorkspaceLogPath() (string, error) {
+// cursorGlobalStatePath returns the path to Cursor's global state database
+func cursorGlobalStatePath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(homeDir, ".portage-workspace.log"), nil
+	return filepath.Join(homeDir, "Library/Application Support/Cursor/User/globalStorage/state.vscdb"), nil
 }
 
-// readWorkspaceLog reads the workspace log from disk
-// Log format: one line per event: "timestamp,event,path"
-func readWorkspaceLog() ([]WorkspaceEvent, error) {
-	logPath, err := getWorkspaceLogPath()
+// loadCursorRecentHistory reads the recently opened paths list from Cursor's global state.
+// The database is opened read-only, WAL included, so recently opened paths Cursor hasn't
+// checkpointed yet show up; if a running Cursor's lock gets in the way, a snapshot copy
+// of the database and its -wal/-shm files is read instead.
+// Returns an error satisfying os.IsNotExist when Cursor isn't installed.
+func loadCursorRecentHistory() (*CursorHistory, error) {
+	dbPath, err := cursorGlobalStatePath()
 	if err != nil {
 		return nil, err
 	}
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, err
+	}
+
+	history, err := queryCursorHistory(dbPath)
+	if err == nil {
+		return history, nil
+	}
+
+	// Copy-on-read fallback: Cursor may hold an exclusive lock or have uncheckpointed WAL data
+	snapshotDir, copyErr := snapshotSQLite(dbPath)
+	if copyErr != nil {
+		return nil, describeCursorDBError(err)
+	}
+	defer os.RemoveAll(snapshotDir)
 
-	// If file doesn't exist, return empty log
-	if _, err := os.Stat(logPath); os.IsNotExist(err) {
-		return []WorkspaceEvent{}, nil
+	history, snapErr := queryCursorHistory(filepath.Join(snapshotDir, filepath.Base(dbPath)))
+	if snapErr != nil {
+		return nil, describeCursorDBError(err)
 	}
+	return history, nil
+}
 
-	data, err := os.ReadFile(logPath)
+// queryCursorHistory opens state.vscdb read-only and parses history.recentlyOpenedPathsList
+func queryCursorHistory(dbPath string) (*CursorHistory, error) {
+	// Not immutable=1: that skips the -wal file, where Cursor's latest writes are
+	dsn := (&url.URL{Scheme: "file", Path: dbPath, RawQuery: "mode=ro&_pragma=busy_timeout(2000)"}).String()
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, err
 	}
+	defer db.Close()
 
-	var events []WorkspaceEvent
-	lines := strings.Split(string(data), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+	var historyJSON string
+	err = db.QueryRow("SELECT value FROM ItemTable WHERE key='history.recentlyOpenedPathsList'").Scan(&historyJSON)
+	if err != nil {
+		return nil, err
+	}
 
-		parts := strings.SplitN(line, ",", 3)
-		if len(parts) != 3 {
-			continue // Skip malformed lines
-		}
+	var history CursorHistory
+	if err := json.Unmarshal([]byte(historyJSON), &history); err != nil {
+		return nil, fmt.Errorf("failed to parse recently opened paths: %w", err)
+	}
+	return &history, nil
+}
+
+// snapshotSQLite copies a database and its -wal/-shm companions into a temp directory
+func snapshotSQLite(dbPath string) (string, error) {
+	dir, err := os.MkdirTemp("", "portage-vscdb-")
+	if err != nil {
+		return "", err
+	}
 
-		timestamp, err := strconv.ParseInt(parts[0], 10, 64)
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		data, err := os.ReadFile(dbPath + suffix)
 		if err != nil {
-			continue // Skip malformed lines
+			if suffix != "" && os.IsNotExist(err) {
+				continue
+			}
+			os.RemoveAll(dir)
+			return "", err
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(dbPath)+suffix), data, 0600); err != nil {
+			os.RemoveAll(dir)
+			return "", err
 		}
-
-		events = append(events, WorkspaceEvent{
-			Timestamp: timestamp,
-			Event:     parts[1],
-			Path:      parts[2],
-		})
 	}
 
-	return events, nil
+	return dir, nil
 }
 
-// appendWorkspaceEvent appends an event to the log file
-func appendWorkspaceEvent(event, path string) error {
-	logPath, err := getWorkspaceLogPath()
-	if err != nil {
-		return err
-	}
+// describeCursorDBError turns low-level sqlite errors into something actionable
+func describeCursorDBError(err error) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "SQLITE_BUSY") || strings.Contains(msg, "database is locked"):
+		return fmt.Errorf("Cursor's state.vscdb is locked by a running Cursor process; try again in a moment (%w)", err)
+	case strings.Contains(msg, "no such table"):
+		return fmt.Errorf("Cursor's state.vscdb has an unexpected layout; Cursor may have changed its storage format (%w)", err)
+	case err == sql.ErrNoRows:
+		return fmt.Errorf("Cursor's state.vscdb has no recently opened paths yet")
+	}
+	return fmt.Errorf("failed to read Cursor's state.vscdb: %w", err)
+}
 
-	// Open file in append mode
-	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
+// warnCursorHistoryUnavailable reports on stderr that Cursor history was left out of the results
+func warnCursorHistoryUnavailable(err error) {
+	if os.IsNotExist(err) {
+		return // Cursor isn't installed, nothing to warn about
 	}
-	defer f.Close()
+	fmt.Fprintf(os.Stderr, "%sWarning: Cursor history could not be included: %v%s\n", ColorYellow, err, ColorReset)
+}
+
+type RecentlyClosedWorkspace struct {
+	Path string `json:"path"`
+}
+
+// WorkspaceEvent represents a workspace event (open or close)
+type WorkspaceEvent struct {
+	ID        int64 // row in history.db, for deleting it
+	Path      string
+	Event     string // "open" or "close"
+	Timestamp int64  // Unix timestamp
+}
 
-	// Write event: timestamp,event,path
-	line := fmt.Sprintf("%d,%s,%s\n", time.Now().Unix(), event, path)
-	_, err = f.WriteString(line)
-	return err
+// getWorkspaceLogPath returns [logs] workspaces in config.toml: the comma-separated log
+// older versions kept, imported into history.db once (historydb.go)
+func getWorkspaceLogPath() (string, error) {
+	if _, err := os.UserHomeDir(); err != nil {
+		return "", err
+	}
+	return expandHome(loadSettings().Logs.Workspaces), nil
 }
 
 // addWorkspaceCloseEvent adds a workspace closure event to the log
@@ -1330,22 +2182,14 @@ func displayCursorHistory() {
 	// Get currently open Cursor windows (map of path -> bool)
 	openWindows := getOpenCursorWindows()
 
-	// Read our workspace event log
-	events, err := readWorkspaceLog()
+	// Read our workspace events
+	events, err := queryEvents(eventQuery{Types: []string{EventWorkspaceOpen, EventWorkspaceClose}})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading workspace log: %v\n", err)
-		events = []WorkspaceEvent{} // Continue with empty log
+		fmt.Fprintf(os.Stderr, "Error reading workspace log: %v\n", err) // Continue with what was read
 	}
 
-	// Build a map of path -> last event
-	// We iterate through events and keep the latest event for each path
-	lastEvents := make(map[string]WorkspaceEvent)
-	for _, event := range events {
-		// Keep the latest event for this path
-		if existing, ok := lastEvents[event.Path]; !ok || event.Timestamp > existing.Timestamp {
-			lastEvents[event.Path] = event
-		}
-	}
+	// The latest event of each path says whether it's closed
+	lastEvents := lastWorkspaceEvents(events)
 
 	// Extract paths where last event is "close"
 	type closedWorkspace struct {
@@ -1395,76 +2239,56 @@ func displayCursorHistory() {
 
 	// If we haven't reached the limit, supplement with Cursor history
 	if len(recentlyClosed) < cursorHistoryLimit {
-		homeDir, err := os.UserHomeDir()
-		if err == nil {
-			dbPath := filepath.Join(homeDir, "Library/Application Support/Cursor/User/globalStorage/state.vscdb")
+		history, err := loadCursorRecentHistory()
+		if err != nil {
+			warnCursorHistoryUnavailable(err)
+		} else {
+			// Add workspaces from Cursor history that aren't already in our list
+			for _, entry := range history.Entries {
+				// Convert file:///path to /path
+				path := strings.TrimPrefix(entry.FolderURI, "file://")
+
+				// URL decode the path (fixes Cyrillic and special characters)
+				decodedPath, err := url.PathUnescape(path)
+				if err != nil {
+					decodedPath = path // Fallback to original if decode fails
+				}
+
+				// Skip if already seen (from our log)
+				if seenPaths[decodedPath] {
+					continue
+				}
 
-			// Open database
-			db, err := sql.Open("sqlite", dbPath)
-			if err == nil {
-				defer db.Close()
+				// Skip if currently open
+				if openWindows[decodedPath] {
+					continue
+				}
 
-				// Query history
-				var historyJSON string
-				err = db.QueryRow("SELECT value FROM ItemTable WHERE key='history.recentlyOpenedPathsList'").Scan(&historyJSON)
-				if err == nil {
-					// Parse JSON
-					var history CursorHistory
-					if err := json.Unmarshal([]byte(historyJSON), &history); err == nil {
-						// Add workspaces from Cursor history that aren't already in our list
-						for _, entry := range history.Entries {
-							// Convert file:///path to /path
-							path := strings.TrimPrefix(entry.FolderURI, "file://")
-
-							// URL decode the path (fixes Cyrillic and special characters)
-							decodedPath, err := url.PathUnescape(path)
-							if err != nil {
-								decodedPath = path // Fallback to original if decode fails
-							}
-
-							// Skip if already seen (from our log)
-							if seenPaths[decodedPath] {
-								continue
-							}
-
-							// Skip if currently open
-							if openWindows[decodedPath] {
-								continue
-							}
-
-							// Skip if path doesn't exist on disk
-							if _, err := os.Stat(decodedPath); os.IsNotExist(err) {
-								continue
-							}
-
-							recentlyClosed = append(recentlyClosed, RecentlyClosedWorkspace{
-								Path: decodedPath,
-							})
-							seenPaths[decodedPath] = true
-
-							// Stop when we reach the limit
-							if len(recentlyClosed) >= cursorHistoryLimit {
-								break
-							}
-						}
-					}
+				// Skip if path doesn't exist on disk
+				if _, err := os.Stat(decodedPath); os.IsNotExist(err) {
+					continue
+				}
+
+				recentlyClosed = append(recentlyClosed, RecentlyClosedWorkspace{
+					Path: decodedPath,
+				})
+				seenPaths[decodedPath] = true
+
+				// Stop when we reach the limit
+				if len(recentlyClosed) >= cursorHistoryLimit {
+					break
 				}
 			}
 		}
 	}
 
 	// Output JSON
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(recentlyClosed); err != nil {
-		fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-	}
+	writeJSON("closed_workspaces", recentlyClosed)
 }
 
 func getClaudeSessions() []ClaudeSession {
 	// Run ps aux and grep for claude processes
-	cmd := exec.Command("sh", "-c", "ps aux | grep -i claude | grep -v grep | grep -v portage | grep -v lsof")
-	output, err := cmd.Output()
+	output, err := commandOutput("sh", "-c", "ps aux | grep -i claude | grep -v grep | grep -v portage | grep -v lsof")
 	if err != nil {
 		// No claude processes found
 		return []ClaudeSession{}
@@ -1496,8 +2320,7 @@ func getClaudeSessions() []ClaudeSession {
 
 		// Get working directory using lsof
 		workingDir := ""
-		lsofCmd := exec.Command("lsof", "-p", pid)
-		lsofOut, err := lsofCmd.Output()
+		lsofOut, err := commandOutput("lsof", "-p", pid)
 		if err == nil {
 			for _, lsofLine := range strings.Split(string(lsofOut), "\n") {
 				if strings.Contains(lsofLine, "cwd") && strings.Contains(lsofLine, "DIR") {
@@ -1533,11 +2356,16 @@ func getClaudeSessions() []ClaudeSession {
 }
 
 func displayClaudeSessions() {
-	sessions := getClaudeSessions()
+	var sessions []ClaudeSession
+	for _, session := range getClaudeSessions() {
+		if isUnderPathFilter(session.WorkingDir) {
+			sessions = append(sessions, session)
+		}
+	}
 
 	if len(sessions) == 0 {
 		if jsonOutput {
-			fmt.Println("[]")
+			writeJSON("claude_sessions", []ClaudeSession{})
 		} else {
 			fmt.Println("No active Claude Code sessions found")
 		}
@@ -1565,16 +2393,12 @@ func displayClaudeSessions() {
 	}
 
 	if jsonOutput {
-		encoder := json.NewEncoder(os.Stdout)
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(sessions); err != nil {
-			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-		}
+		writeJSON("claude_sessions", sessions)
 		return
 	}
 
 	// Table output
-	t := table.NewWriter()
+	t := newTable(table.StyleRounded)
 	t.SetOutputMirror(os.Stdout)
 	t.AppendHeader(table.Row{"PROJECT", "PID", "SESSION", "MESSAGES", "LAST ACTIVE", "PATH"})
 
@@ -1594,7 +2418,6 @@ func displayClaudeSessions() {
 		})
 	}
 
-	t.SetStyle(table.StyleRounded)
 	t.Render()
 }
 
@@ -1716,11 +2539,7 @@ func displayClaudeHistory() {
 	sessions := groupHistoryBySessions(entries)
 
 	if jsonOutput {
-		encoder := json.NewEncoder(os.Stdout)
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(sessions); err != nil {
-			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-		}
+		writeJSON("claude_history", sessions)
 		return
 	}
 
@@ -1730,27 +2549,13 @@ func displayClaudeHistory() {
 	}
 
 	// Table output
-	t := table.NewWriter()
+	t := newTable(table.StyleRounded)
 	t.SetOutputMirror(os.Stdout)
 	t.AppendHeader(table.Row{"PROJECT", "PID", "SESSION", "MESSAGES", "LAST ACTIVE", "PATH"})
 
 	for _, session := range sessions {
 		lastActive := time.Unix(session.LastTimestamp/1000, 0)
-		timeSince := time.Since(lastActive)
-
-		var timeStr string
-		if timeSince < time.Hour {
-			timeStr = fmt.Sprintf("%dm ago", int(timeSince.Minutes()))
-		} else if timeSince < 24*time.Hour {
-			timeStr = fmt.Sprintf("%dh ago", int(timeSince.Hours()))
-		} else {
-			days := int(timeSince.Hours() / 24)
-			if days == 1 {
-				timeStr = "1d ago"
-			} else {
-				timeStr = fmt.Sprintf("%dd ago", days)
-			}
-		}
+		timeStr := durations.Recency.Ago(time.Since(lastActive))
 
 		// Truncate session ID
 		sessionID := session.ID
@@ -1768,161 +2573,144 @@ func displayClaudeHistory() {
 		})
 	}
 
-	t.SetStyle(table.StyleRounded)
 	t.Render()
 }
 
-func displayWorkspaceHistory() {
+// collectWorkspaceHistory merges Claude sessions, closed Cursor workspaces and Cursor's
+// recently opened list, most recent first (limit <= 0 means no limit)
+func collectWorkspaceHistory(limit int) []WorkspaceHistoryEntry {
 	var history []WorkspaceHistoryEntry
 
-	// Get Claude history
-	claudeEntries, err := loadClaudeHistory()
-	if err == nil && len(claudeEntries) > 0 {
-		sessions := groupHistoryBySessions(claudeEntries)
-		for _, session := range sessions {
-			// Extract project name from path
-			pathParts := strings.Split(session.Project, "/")
-			name := pathParts[len(pathParts)-1]
-
-			history = append(history, WorkspaceHistoryEntry{
-				Type:      "claude",
-				Path:      session.Project,
-				Name:      name,
-				Timestamp: session.LastTimestamp,
-				SessionID: session.ID,
-				Messages:  session.MessageCount,
-			})
+	// Claude sessions and our workspace events
+	events, _ := queryEvents(eventQuery{Types: []string{EventClaudeSession, EventWorkspaceOpen, EventWorkspaceClose}})
+	for _, event := range events {
+		if event.Session == nil {
+			continue
 		}
+		// Extract project name from path
+		pathParts := strings.Split(event.Path, "/")
+		name := pathParts[len(pathParts)-1]
+
+		history = append(history, WorkspaceHistoryEntry{
+			Type:      "claude",
+			Path:      event.Path,
+			Name:      name,
+			Timestamp: event.Session.LastTimestamp,
+			SessionID: event.Session.ID,
+			Messages:  event.Session.MessageCount,
+		})
 	}
 
 	// Get Cursor history
 	// Get currently open Cursor windows (to filter them out)
 	openWindows := getOpenCursorWindows()
 
-	// Read workspace event log
-	events, err := readWorkspaceLog()
-	if err == nil {
-		// Build a map of path -> last event
-		lastEvents := make(map[string]WorkspaceEvent)
-		for _, event := range events {
-			if existing, ok := lastEvents[event.Path]; !ok || event.Timestamp > existing.Timestamp {
-				lastEvents[event.Path] = event
+	// Extract paths whose last event is "close"
+	for path, event := range lastWorkspaceEvents(events) {
+		if event.Event == "close" {
+			// Skip if currently open (already added above)
+			if openWindows[path] {
+				continue
+			}
+
+			// Skip if path doesn't exist on disk
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				continue
 			}
+
+			// Extract project name from path
+			pathParts := strings.Split(path, "/")
+			name := pathParts[len(pathParts)-1]
+
+			history = append(history, WorkspaceHistoryEntry{
+				Type:      "cursor",
+				Path:      path,
+				Name:      name,
+				Timestamp: event.Timestamp * 1000, // Convert to milliseconds
+			})
 		}
+	}
 
-		// Extract paths where last event is "close"
-		for path, event := range lastEvents {
-			if event.Event == "close" {
-				// Skip if currently open (already added above)
-				if openWindows[path] {
+	// If we haven't reached the limit, supplement with Cursor DB history
+	if limit <= 0 || len(history) < limit {
+		cursorHist, err := loadCursorRecentHistory()
+		if err != nil {
+			warnCursorHistoryUnavailable(err)
+		} else {
+			// Add workspaces from Cursor history
+			for _, entry := range cursorHist.Entries {
+				// Convert file:///path to /path
+				path := strings.TrimPrefix(entry.FolderURI, "file://")
+
+				// URL decode the path (fixes Cyrillic and special characters)
+				decodedPath, err := url.PathUnescape(path)
+				if err != nil {
+					decodedPath = path // Fallback to original if decode fails
+				}
+
+				// Skip if currently open
+				if openWindows[decodedPath] {
 					continue
 				}
 
-				// Skip if path doesn't exist on disk
-				if _, err := os.Stat(path); os.IsNotExist(err) {
+				// Get directory modification time as timestamp
+				stat, err := os.Stat(decodedPath)
+				if os.IsNotExist(err) {
 					continue
 				}
 
+				var timestamp int64
+				if err == nil {
+					// Use directory modification time in milliseconds
+					timestamp = stat.ModTime().UnixMilli()
+				} else {
+					timestamp = 0 // Fallback if stat fails
+				}
+
 				// Extract project name from path
-				pathParts := strings.Split(path, "/")
+				pathParts := strings.Split(decodedPath, "/")
 				name := pathParts[len(pathParts)-1]
 
 				history = append(history, WorkspaceHistoryEntry{
 					Type:      "cursor",
-					Path:      path,
+					Path:      decodedPath,
 					Name:      name,
-					Timestamp: event.Timestamp * 1000, // Convert to milliseconds
+					Timestamp: timestamp,
 				})
-			}
-		}
-	}
-
-	// If we haven't reached the limit, supplement with Cursor DB history
-	if cursorHistoryLimit <= 0 || len(history) < cursorHistoryLimit {
-		homeDir, err := os.UserHomeDir()
-		if err == nil {
-			dbPath := filepath.Join(homeDir, "Library/Application Support/Cursor/User/globalStorage/state.vscdb")
 
-			// Open database
-			db, err := sql.Open("sqlite", dbPath)
-			if err == nil {
-				defer db.Close()
-
-				// Query history
-				var historyJSON string
-				err = db.QueryRow("SELECT value FROM ItemTable WHERE key='history.recentlyOpenedPathsList'").Scan(&historyJSON)
-				if err == nil {
-					// Parse JSON
-					var cursorHist CursorHistory
-					if err := json.Unmarshal([]byte(historyJSON), &cursorHist); err == nil {
-						// Add workspaces from Cursor history
-						for _, entry := range cursorHist.Entries {
-							// Convert file:///path to /path
-							path := strings.TrimPrefix(entry.FolderURI, "file://")
-
-							// URL decode the path (fixes Cyrillic and special characters)
-							decodedPath, err := url.PathUnescape(path)
-							if err != nil {
-								decodedPath = path // Fallback to original if decode fails
-							}
-
-							// Skip if currently open
-							if openWindows[decodedPath] {
-								continue
-							}
-
-							// Get directory modification time as timestamp
-							stat, err := os.Stat(decodedPath)
-							if os.IsNotExist(err) {
-								continue
-							}
-
-							var timestamp int64
-							if err == nil {
-								// Use directory modification time in milliseconds
-								timestamp = stat.ModTime().UnixMilli()
-							} else {
-								timestamp = 0 // Fallback if stat fails
-							}
-
-							// Extract project name from path
-							pathParts := strings.Split(decodedPath, "/")
-							name := pathParts[len(pathParts)-1]
-
-							history = append(history, WorkspaceHistoryEntry{
-								Type:      "cursor",
-								Path:      decodedPath,
-								Name:      name,
-								Timestamp: timestamp,
-							})
-
-							// Stop when we reach the limit
-							if cursorHistoryLimit > 0 && len(history) >= cursorHistoryLimit {
-								break
-							}
-						}
-					}
+				// Stop when we reach the limit
+				if limit > 0 && len(history) >= limit {
+					break
 				}
 			}
 		}
 	}
 
-	// Sort by timestamp (most recent first)
+	// Sort pinned projects first, then by timestamp (most recent first)
+	config := loadConfig()
+	for i := range history {
+		history[i].Pinned = config.isPinned(history[i].Path)
+	}
 	sort.Slice(history, func(i, j int) bool {
+		if history[i].Pinned != history[j].Pinned {
+			return history[i].Pinned
+		}
 		return history[i].Timestamp > history[j].Timestamp
 	})
 
 	// Apply limit after adding Cursor DB entries
-	if cursorHistoryLimit > 0 && len(history) > cursorHistoryLimit {
-		history = history[:cursorHistoryLimit]
+	if limit > 0 && len(history) > limit {
+		history = history[:limit]
 	}
 
+	return history
+}
+
+func displayWorkspaceHistory() {
+	history := collectWorkspaceHistory(cursorHistoryLimit)
+
 	if jsonOutput {
-		encoder := json.NewEncoder(os.Stdout)
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(history); err != nil {
-			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-		}
+		writeJSON("history", history)
 		return
 	}
 
@@ -1932,7 +2720,7 @@ func displayWorkspaceHistory() {
 	}
 
 	// Table output
-	t := table.NewWriter()
+	t := newTable(table.StyleRounded)
 	t.SetOutputMirror(os.Stdout)
 	t.AppendHeader(table.Row{"TYPE", "NAME", "SESSION", "LAST ACTIVE", "PATH"})
 
@@ -1942,20 +2730,7 @@ func displayWorkspaceHistory() {
 			timeStr = "unknown"
 		} else {
 			lastActive := time.Unix(entry.Timestamp/1000, 0)
-			timeSince := time.Since(lastActive)
-
-			if timeSince < time.Hour {
-				timeStr = fmt.Sprintf("%dm ago", int(timeSince.Minutes()))
-			} else if timeSince < 24*time.Hour {
-				timeStr = fmt.Sprintf("%dh ago", int(timeSince.Hours()))
-			} else {
-				days := int(timeSince.Hours() / 24)
-				if days == 1 {
-					timeStr = "1d ago"
-				} else {
-					timeStr = fmt.Sprintf("%dd ago", days)
-				}
-			}
+			timeStr = durations.Recency.Ago(time.Since(lastActive))
 		}
 
 		// Format session info for Claude entries
@@ -1969,15 +2744,19 @@ func displayWorkspaceHistory() {
 			sessionInfo = sessionID
 		}
 
+		name := entry.Name
+		if entry.Pinned {
+			name = pinnedMarker + name
+		}
+
 		t.AppendRow(table.Row{
 			entry.Type,
-			entry.Name,
+			name,
 			sessionInfo,
 			timeStr,
 			entry.Path,
 		})
 	}
 
-	t.SetStyle(table.StyleRounded)
 	t.Render()
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metricsLabelEscaper escapes Prometheus label values: backslash, quote and newline
+var metricsLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// portMetricLabels identifies a listener in every per-port series, so they can be joined
+func portMetricLabels(port PortInfo) string {
+	labels := [][2]string{
+		{"port", strconv.Itoa(port.Port)},
+		{"pid", port.PID},
+		{"command", port.Command},
+		{"path", port.Path},
+		{"address", port.Address},
+	}
+	parts := make([]string, len(labels))
+	for i, label := range labels {
+		parts[i] = fmt.Sprintf(`%s="%s"`, label[0], metricsLabelEscaper.Replace(label[1]))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// displayMetrics prints the scan in the Prometheus text exposition format for --metrics,
+// e.g. for node_exporter's textfile collector
+func displayMetrics(portsByRange map[int][]PortInfo, scanDuration time.Duration) {
+	ports := sortedPortList(portsByRange, "port")
+
+	var b strings.Builder
+	metric := func(name, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+
+	metric("portage_listen_port", "A listening port, always 1, labeled with the process behind it.")
+	for _, port := range ports {
+		fmt.Fprintf(&b, "portage_listen_port%s 1\n", portMetricLabels(port))
+	}
+	metric("portage_listen_port_uptime_seconds", "How long the process behind a listening port has been running.")
+	for _, port := range ports {
+		fmt.Fprintf(&b, "portage_listen_port_uptime_seconds%s %d\n", portMetricLabels(port), port.UptimeSeconds)
+	}
+
+	pids := make(map[string]bool)
+	orphaned := 0
+	for _, port := range ports {
+		pids[port.PID] = true
+		if port.Orphaned {
+			orphaned++
+		}
+	}
+	metric("portage_listen_ports", "Number of listening ports after portage's filters.")
+	fmt.Fprintf(&b, "portage_listen_ports %d\n", len(ports))
+	metric("portage_processes", "Number of processes behind the listening ports.")
+	fmt.Fprintf(&b, "portage_processes %d\n", len(pids))
+	metric("portage_orphaned_ports", "Listening ports whose working directory no longer exists.")
+	fmt.Fprintf(&b, "portage_orphaned_ports %d\n", orphaned)
+	metric("portage_scan_duration_seconds", "How long the scan took.")
+	fmt.Fprintf(&b, "portage_scan_duration_seconds %.3f\n", scanDuration.Seconds())
+
+	fmt.Print(b.String())
+}
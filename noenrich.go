@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// unenrichedPort is the --no-enrich JSON shape: the PortInfo keys scripts rely on,
+// with everything that needs per-PID work left as null instead of "N/A"
+type unenrichedPort struct {
+	Port          int     `json:"port"`
+	PID           string  `json:"pid"`
+	Command       string  `json:"command"`
+	Address       string  `json:"address"`
+	User          string  `json:"user"`
+	Path          *string `json:"path"`
+	Uptime        *string `json:"uptime"`
+	UptimeSeconds *int    `json:"uptime_seconds"`
+}
+
+// runNoEnrich lists listeners straight from a single lsof call, without looking up
+// working directories, uptimes or command lines. Without a working directory there is
+// no way to tell user ports from system daemons, so every listener is included.
+func runNoEnrich() {
+	if quietOutput && printField == "path" {
+		fmt.Fprintf(os.Stderr, "Error: --no-enrich doesn't look up paths, so -q can't --print path\n")
+		os.Exit(1)
+	}
+	output, err := lsofListing()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing lsof: %v\n", err)
+		os.Exit(1)
+	}
+
+	config := loadConfig()
+	var ports []PortInfo
+	for _, port := range parseOutput(string(output)) {
+		if !matchesUserFilter(port) || !matchesRegexFilter(port) || !matchesPortFilter(port) {
+			continue
+		}
+		ports = append(ports, port)
+	}
+	ports = filterHiddenPorts(map[int][]PortInfo{0: ports}, config)[0]
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i].Port < ports[j].Port
+	})
+
+	if quietOutput {
+		displayQuiet(map[int][]PortInfo{0: ports}, "port") // no uptimes to sort by
+		return
+	}
+	if jsonOutput {
+		result := make([]unenrichedPort, 0, len(ports))
+		for _, port := range ports {
+			result = append(result, unenrichedPort{
+				Port:    port.Port,
+				PID:     port.PID,
+				Command: port.Command,
+				Address: port.Address,
+				User:    port.User,
+			})
+		}
+		writeJSON("ports", result)
+		return
+	}
+
+	t := newTable(table.StyleDefault)
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"PORT", "COMMAND", "PID", "ADDRESS"})
+	for _, port := range ports {
+		t.AppendRow(table.Row{port.Port, port.Command, port.PID, port.Address})
+	}
+	t.Render()
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// noteKey returns the Notes key of the deepest directory containing path, if any
+func (c *Config) noteKey(path string) string {
+	best := ""
+	bestLen := 0
+	for key := range c.Notes {
+		dir := strings.TrimSuffix(expandHome(key), "/")
+		if (path == dir || strings.HasPrefix(path, dir+"/")) && len(dir) > bestLen {
+			best = key
+			bestLen = len(dir)
+		}
+	}
+	return best
+}
+
+// noteFor returns the note of the project containing path
+func (c *Config) noteFor(path string) string {
+	return c.Notes[c.noteKey(path)]
+}
+
+// noteDir is the directory n attaches a note to: the one already carrying the port's
+// note, so editing doesn't create a second one, or else the port's project (not ~)
+func (c *Config) noteDir(path string) string {
+	if key := c.noteKey(path); key != "" {
+		return key
+	}
+	return pinnableProject(PortInfo{Path: path})
+}
+
+// resolvePortNotes fills in the Note field for every port in place
+func resolvePortNotes(portsByRange map[int][]PortInfo, config *Config) {
+	for _, ports := range portsByRange {
+		for i := range ports {
+			ports[i].Note = config.noteFor(ports[i].Path)
+		}
+	}
+}
+
+// noteEditor is the open text input of n
+type noteEditor struct {
+	dir  string
+	text []rune
+}
+
+// openNoteEditor starts editing the note of the selected port's project
+func (m model) openNoteEditor() model {
+	visiblePorts := m.getVisiblePorts()
+	if m.cursor >= len(visiblePorts) {
+		return m
+	}
+	dir := m.config.noteDir(visiblePorts[m.cursor].Path)
+	if dir == "" {
+		m.message = "No project directory to attach a note to"
+		return m
+	}
+	m.note = &noteEditor{dir: dir, text: []rune(m.config.Notes[dir])}
+	return m
+}
+
+// updateNoteEditor handles keys while the note input is open
+func (m model) updateNoteEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		m.note = nil
+
+	case tea.KeyEnter:
+		dir, text := m.note.dir, strings.TrimSpace(string(m.note.text))
+		m.note = nil
+		if m.config.Notes == nil {
+			m.config.Notes = make(map[string]string)
+		}
+		if text == "" {
+			delete(m.config.Notes, dir)
+			m.message = "Removed the note of " + shortenPath(dir)
+		} else {
+			m.config.Notes[dir] = text
+			m.message = "Saved the note of " + shortenPath(dir)
+		}
+		m.config.save()
+		if readOnly {
+			m.message += " for this session (read-only)"
+		}
+
+	case tea.KeyBackspace:
+		if len(m.note.text) > 0 {
+			m.note.text = m.note.text[:len(m.note.text)-1]
+		}
+
+	case tea.KeyCtrlU:
+		m.note.text = nil
+
+	case tea.KeyRunes, tea.KeySpace:
+		m.note.text = append(m.note.text, msg.Runes...)
+	}
+	return m, nil
+}
+
+// viewNoteEditor renders the note input in place of the help line
+func (m model) viewNoteEditor() string {
+	promptStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Accent)
+	helpStyle := lipgloss.NewStyle().Foreground(ui.Muted)
+	return promptStyle.Render(fmt.Sprintf("Note for %s: ", shortenPath(m.note.dir))) + string(m.note.text) + "█\n" +
+		helpStyle.Render("enter: save (empty removes it) • ctrl+u: clear • esc: cancel")
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// NotificationConfig is the "notifications" section of portage.json: where --watch
+// alerts are sent besides the terminal. Every configured channel gets every alert.
+type NotificationConfig struct {
+	MacOS    bool            `json:"macos,omitempty"`   // Notification Center via osascript
+	Webhook  string          `json:"webhook,omitempty"` // POSTed {"title", "message", "time"} as JSON
+	Ntfy     *NtfyConfig     `json:"ntfy,omitempty"`
+	Telegram *TelegramConfig `json:"telegram,omitempty"`
+	Pushover *PushoverConfig `json:"pushover,omitempty"`
+}
+
+type NtfyConfig struct {
+	Server string `json:"server,omitempty"` // default https://ntfy.sh
+	Topic  string `json:"topic"`
+	Token  string `json:"token,omitempty"` // access token for protected topics
+}
+
+type TelegramConfig struct {
+	BotToken string `json:"bot_token"`
+	ChatID   string `json:"chat_id"`
+}
+
+type PushoverConfig struct {
+	Token string `json:"token"` // application token
+	User  string `json:"user"`  // user or group key
+}
+
+// notifier is one notification channel
+type notifier interface {
+	name() string
+	send(title, message string) error
+}
+
+var notifyClient = &http.Client{Timeout: 10 * time.Second}
+
+// notifiers returns the channels configured in the notifications section
+func (n *NotificationConfig) notifiers() []notifier {
+	if n == nil {
+		return nil
+	}
+	var channels []notifier
+	if n.MacOS {
+		channels = append(channels, macOSNotifier{})
+	}
+	if n.Webhook != "" {
+		channels = append(channels, webhookNotifier{url: n.Webhook})
+	}
+	if n.Ntfy != nil && n.Ntfy.Topic != "" {
+		channels = append(channels, ntfyNotifier{*n.Ntfy})
+	}
+	if n.Telegram != nil && n.Telegram.BotToken != "" && n.Telegram.ChatID != "" {
+		channels = append(channels, telegramNotifier{*n.Telegram})
+	}
+	if n.Pushover != nil && n.Pushover.Token != "" && n.Pushover.User != "" {
+		channels = append(channels, pushoverNotifier{*n.Pushover})
+	}
+	return channels
+}
+
+// sendNotifications delivers an alert on every channel in parallel and reports failures
+// on stderr; it returns once all channels have answered or timed out
+func sendNotifications(channels []notifier, title, message string) {
+	done := make(chan struct{}, len(channels))
+	for _, channel := range channels {
+		go func(channel notifier) {
+			if err := channel.send(title, message); err != nil {
+				fmt.Fprintf(os.Stderr, "%sNotification via %s failed: %v%s\n", ColorRed, channel.name(), err, ColorReset)
+			}
+			done <- struct{}{}
+		}(channel)
+	}
+	for range channels {
+		<-done
+	}
+}
+
+// checkResponse turns a non-2xx reply into an error
+func checkResponse(resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %s", resp.Status)
+	}
+	return nil
+}
+
+type macOSNotifier struct{}
+
+func (macOSNotifier) name() string { return "macOS" }
+
+func (macOSNotifier) send(title, message string) error {
+	script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+	_, err := commandOutput("osascript", "-e", script)
+	return err
+}
+
+type webhookNotifier struct{ url string }
+
+func (webhookNotifier) name() string { return "webhook" }
+
+func (w webhookNotifier) send(title, message string) error {
+	body, _ := json.Marshal(map[string]string{
+		"title":   title,
+		"message": message,
+		"time":    time.Now().Format(time.RFC3339),
+	})
+	return checkResponse(notifyClient.Post(w.url, "application/json", strings.NewReader(string(body))))
+}
+
+type ntfyNotifier struct{ NtfyConfig }
+
+func (ntfyNotifier) name() string { return "ntfy" }
+
+func (n ntfyNotifier) send(title, message string) error {
+	server := n.Server
+	if server == "" {
+		server = "https://ntfy.sh"
+	}
+	req, err := http.NewRequest("POST", strings.TrimSuffix(server, "/")+"/"+url.PathEscape(n.Topic), strings.NewReader(message))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", title)
+	req.Header.Set("Tags", "warning")
+	if n.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.Token)
+	}
+	return checkResponse(notifyClient.Do(req))
+}
+
+type telegramNotifier struct{ TelegramConfig }
+
+func (telegramNotifier) name() string { return "Telegram" }
+
+func (t telegramNotifier) send(title, message string) error {
+	return checkResponse(notifyClient.PostForm("https://api.telegram.org/bot"+t.BotToken+"/sendMessage", url.Values{
+		"chat_id": {t.ChatID},
+		"text":    {title + ": " + message},
+	}))
+}
+
+type pushoverNotifier struct{ PushoverConfig }
+
+func (pushoverNotifier) name() string { return "Pushover" }
+
+func (p pushoverNotifier) send(title, message string) error {
+	return checkResponse(notifyClient.PostForm("https://api.pushover.net/1/messages.json", url.Values{
+		"token":   {p.Token},
+		"user":    {p.User},
+		"title":   {title},
+		"message": {message},
+	}))
+}
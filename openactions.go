@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// OpenAction customizes what the TUI "open" action does for a range of ports
+type OpenAction struct {
+	Ports string `json:"ports"`         // "5432", "9200-9299" or "3000,3001"
+	URL   string `json:"url"`           // template with {host}, {port} and {path}
+	App   string `json:"app,omitempty"` // application to open the URL with (open -a), default handler if empty
+}
+
+// defaultOpenActions give non-HTTP services a sensible default; user config takes precedence
+var defaultOpenActions = []OpenAction{
+	{Ports: "5432", URL: "postgresql://{host}:{port}"},
+	{Ports: "3306", URL: "mysql://{host}:{port}"},
+	{Ports: "6379", URL: "redis://{host}:{port}"},
+	{Ports: "27017", URL: "mongodb://{host}:{port}"},
+	{Ports: "9200-9299", URL: "http://{host}:{port}/_cat/indices?v"},
+	{Ports: "5601", URL: "http://{host}:{port}/app/home"},
+}
+
+// matchesPort reports whether the action's port spec covers port
+func (a OpenAction) matchesPort(port int) bool {
+	for _, part := range strings.Split(a.Ports, ",") {
+		part = strings.TrimSpace(part)
+		if lo, hi, isRange := strings.Cut(part, "-"); isRange {
+			start, err1 := strconv.Atoi(strings.TrimSpace(lo))
+			end, err2 := strconv.Atoi(strings.TrimSpace(hi))
+			if err1 == nil && err2 == nil && port >= start && port <= end {
+				return true
+			}
+			continue
+		}
+		if p, err := strconv.Atoi(part); err == nil && p == port {
+			return true
+		}
+	}
+	return false
+}
+
+// portHost returns the host to connect to for a listener, mapping wildcard binds to localhost
+func portHost(port PortInfo) string {
+	idx := strings.LastIndex(port.Address, ":")
+	if idx <= 0 || isWildcardBind(port.Address) {
+		return "localhost"
+	}
+	return port.Address[:idx]
+}
+
+// resolveOpenTarget returns the URL (and optional app) the "open" action should use for a port
+func resolveOpenTarget(port PortInfo, config *Config) (url string, app string) {
+	actions := append(append([]OpenAction{}, config.OpenActions...), defaultOpenActions...)
+	for _, action := range actions {
+		if !action.matchesPort(port.Port) {
+			continue
+		}
+		replacer := strings.NewReplacer(
+			"{host}", portHost(port),
+			"{port}", strconv.Itoa(port.Port),
+			"{path}", port.Path,
+		)
+		return replacer.Replace(action.URL), action.App
+	}
+
+	return fmt.Sprintf("http://%s:%d", portHost(port), port.Port), ""
+}
+
+// openPort opens a listener's URL (in the configured app, if any) and returns the status message
+func openPort(port PortInfo, config *Config) string {
+	url, app := resolveOpenTarget(port, config)
+	args := []string{url}
+	if app != "" {
+		args = []string{"-a", app, url}
+	}
+
+	// Use 'open' command on macOS
+	if err := exec.Command("open", args...).Run(); err != nil {
+		return fmt.Sprintf("Failed to open %s: %v", url, err)
+	}
+	if app != "" {
+		return fmt.Sprintf("Opened %s in %s", url, app)
+	}
+	return fmt.Sprintf("Opened %s", url)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// openTerminalAt opens a new terminal cd'd into path: the configured terminal_command,
+// a tmux window when running inside tmux, an iTerm2 tab from iTerm2, else Terminal.app
+func openTerminalAt(path, command string) error {
+	if command != "" {
+		args := strings.Fields(command)
+		for i := range args {
+			args[i] = strings.ReplaceAll(args[i], "{path}", path)
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = path // for commands without {path}
+		return cmd.Start()
+	}
+
+	if os.Getenv("TMUX") != "" {
+		return exec.Command("tmux", "new-window", "-c", path).Run()
+	}
+	if runtime.GOOS != "darwin" {
+		return fmt.Errorf(`no terminal configured, set "terminal" under [editor] in %s (e.g. "kitty --directory {path}")`, shortenPath(getSettingsPath()))
+	}
+
+	script := fmt.Sprintf(`tell application "Terminal"
+	do script "cd " & quoted form of %s
+	activate
+end tell`, appleScriptString(path))
+	if os.Getenv("TERM_PROGRAM") == "iTerm.app" {
+		script = fmt.Sprintf(`tell application "iTerm2"
+	if (count of windows) = 0 then
+		create window with default profile
+	else
+		tell current window to create tab with default profile
+	end if
+	tell current session of current window to write text "cd " & quoted form of %s
+	activate
+end tell`, appleScriptString(path))
+	}
+	return exec.Command("osascript", "-e", script).Run()
+}
+
+// openInTerminal opens a terminal in a directory and returns the status message
+func openInTerminal(path, command string) string {
+	if path == "" || path == "N/A" || path == "/" {
+		return "No path available to open"
+	}
+	if err := openTerminalAt(path, command); err != nil {
+		return fmt.Sprintf("Failed to open terminal: %v", err)
+	}
+	return fmt.Sprintf("Opened terminal in %s", shortenPath(path))
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/itchyny/gojq"
+)
+
+// jsonSchemaVersion is the version of every JSON document portage prints (see "JSON
+// schema" in the README). It goes up only when a field is removed, renamed or changes
+// meaning; new fields and new kinds don't change it.
+const jsonSchemaVersion = 1
+
+// jsonEnvelope wraps every JSON output, so scripts can check what they got and from
+// which version before reading data
+type jsonEnvelope struct {
+	SchemaVersion int          `json:"schema_version"`
+	GeneratedAt   string       `json:"generated_at"` // RFC 3339
+	Kind          string       `json:"kind"`         // ports, workspaces, unified, claude_sessions, ...
+	Data          interface{}  `json:"data"`
+	Timings       *timingsJSON `json:"timings,omitempty"` // --timings
+}
+
+// newJSONEnvelope wraps data; a nil list becomes [] so data is never null for lists
+func newJSONEnvelope(kind string, data interface{}) jsonEnvelope {
+	if value := reflect.ValueOf(data); value.Kind() == reflect.Slice && value.IsNil() {
+		data = reflect.MakeSlice(value.Type(), 0, 0).Interface()
+	}
+	return jsonEnvelope{
+		SchemaVersion: jsonSchemaVersion,
+		GeneratedAt:   time.Now().Format(time.RFC3339),
+		Kind:          kind,
+		Data:          data,
+	}
+}
+
+// writeJSON prints data of the given kind in the JSON envelope
+func writeJSON(kind string, data interface{}) {
+	writeEnvelope(newJSONEnvelope(kind, data))
+}
+
+// writeEnvelope prints an envelope as indented JSON (YAML with --yaml) or the results
+// of --jq applied to it. --format templates get the data alone, as Go structs.
+func writeEnvelope(envelope jsonEnvelope) {
+	if formatTemplate != nil {
+		writeFormatted(envelope.Data)
+		return
+	}
+	writeDocument(envelope)
+}
+
+// writeDocument prints v as indented JSON, YAML with --yaml, or through --jq
+func writeDocument(v interface{}) {
+	if jqQuery != "" {
+		if err := writeJQ(v, jqQuery); err != nil {
+			fmt.Fprintf(os.Stderr, "Error evaluating --jq: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if yamlOutput {
+		if err := writeYAML(os.Stdout, v); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding YAML: %v\n", err)
+		}
+		return
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+	}
+}
+
+// writeJQ evaluates a jq expression against v and prints every result.
+// Like `gh --jq`, string results are printed raw; everything else as indented JSON.
+func writeJQ(v interface{}, expression string) error {
+	query, err := gojq.Parse(expression)
+	if err != nil {
+		return err
+	}
+
+	// gojq works on plain maps/slices, so round-trip the Go structs through JSON first
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var input interface{}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	documents := 0
+	iter := query.Run(input)
+	for {
+		result, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, isErr := result.(error); isErr {
+			return err
+		}
+
+		if str, isString := result.(string); isString {
+			fmt.Println(str)
+			continue
+		}
+		if yamlOutput {
+			// One YAML document per result
+			if documents > 0 {
+				fmt.Println("---")
+			}
+			documents++
+			if err := writeYAML(os.Stdout, result); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pinnedMarker prefixes pinned projects in tables
+const pinnedMarker = "★ "
+
+// isPinned reports whether path is a pinned project or inside one
+func (c *Config) isPinned(path string) bool {
+	for _, pinned := range c.Pinned {
+		dir := strings.TrimSuffix(pinned, "/")
+		if path == dir || strings.HasPrefix(path, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isPinnedPort reports whether a listener is pinned, by its project or its port number
+func (c *Config) isPinnedPort(port PortInfo) bool {
+	return c.isPinned(port.Path) || containsInt(c.PinnedPorts, port.Port)
+}
+
+// togglePin pins a listener's project, or its port number when there's no project to pin.
+// If it's already pinned, every pin covering it is removed. Returns whether it's pinned now.
+func (c *Config) togglePin(port PortInfo) bool {
+	if c.isPinnedPort(port) {
+		var kept []string
+		for _, pinned := range c.Pinned {
+			if !(&Config{Pinned: []string{pinned}}).isPinned(port.Path) {
+				kept = append(kept, pinned)
+			}
+		}
+		c.Pinned = kept
+		var keptPorts []int
+		for _, pinned := range c.PinnedPorts {
+			if pinned != port.Port {
+				keptPorts = append(keptPorts, pinned)
+			}
+		}
+		c.PinnedPorts = keptPorts
+		return false
+	}
+
+	if root := pinnableProject(port); root != "" {
+		c.Pinned = append(c.Pinned, root)
+		sort.Strings(c.Pinned)
+	} else {
+		c.PinnedPorts = append(c.PinnedPorts, port.Port)
+		sort.Ints(c.PinnedPorts)
+	}
+	return true
+}
+
+// pinnableProject is the project p pins (or n notes) for a listener: its git root or
+// directory, but not the home directory, which would cover nearly everything
+func pinnableProject(port PortInfo) string {
+	root := projectRoot(port.Path)
+	if home, _ := os.UserHomeDir(); root == home {
+		return ""
+	}
+	return root
+}
+
+// sortPinnedFirst moves pinned listeners to the top, keeping the order otherwise
+func sortPinnedFirst(ports []PortInfo, config *Config) {
+	sort.SliceStable(ports, func(i, j int) bool {
+		return config.isPinnedPort(ports[i]) && !config.isPinnedPort(ports[j])
+	})
+}
+
+// runPin implements `portage pin [path|port...]`: pins projects (the current directory if
+// none is given) or port numbers, or lists the pins with --list
+func runPin(args []string) {
+	fs := flag.NewFlagSet("pin", flag.ExitOnError)
+	list := fs.Bool("list", false, "List pinned projects")
+	asJSON := fs.Bool("json", false, "Output the list as JSON")
+	fs.Parse(args)
+
+	config := loadConfig()
+	if *list {
+		listPins(config, *asJSON)
+		return
+	}
+	args = fs.Args()
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	for _, arg := range args {
+		path := resolvePathFilter(arg)
+		if port, err := strconv.Atoi(arg); err == nil && !isDirectory(path) {
+			if containsInt(config.PinnedPorts, port) {
+				fmt.Printf("Port %d is already pinned\n", port)
+				continue
+			}
+			config.PinnedPorts = append(config.PinnedPorts, port)
+			fmt.Printf("%sPinned port %d%s\n", ColorGreen, port, ColorReset)
+			continue
+		}
+		if !isDirectory(path) {
+			fmt.Fprintf(os.Stderr, "Error: %s is not a directory\n", arg)
+			os.Exit(1)
+		}
+		if containsString(config.Pinned, path) {
+			fmt.Printf("%s is already pinned\n", shortenPath(path))
+			continue
+		}
+		config.Pinned = append(config.Pinned, path)
+		fmt.Printf("%sPinned %s%s\n", ColorGreen, shortenPath(path), ColorReset)
+	}
+	sort.Strings(config.Pinned)
+	sort.Ints(config.PinnedPorts)
+
+	if err := config.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runUnpin implements `portage unpin <path|port...>`
+func runUnpin(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: portage unpin <path|port>...\n")
+		os.Exit(1)
+	}
+
+	config := loadConfig()
+	for _, arg := range args {
+		if port, err := strconv.Atoi(arg); err == nil && containsInt(config.PinnedPorts, port) {
+			var kept []int
+			for _, pinned := range config.PinnedPorts {
+				if pinned != port {
+					kept = append(kept, pinned)
+				}
+			}
+			config.PinnedPorts = kept
+			fmt.Printf("Unpinned port %d\n", port)
+			continue
+		}
+
+		path := resolvePathFilter(arg)
+		if !containsString(config.Pinned, path) {
+			// The directory may be gone, so also try the argument as typed
+			path = strings.TrimSuffix(filepath.Clean(expandHome(arg)), "/")
+		}
+		if !containsString(config.Pinned, path) {
+			fmt.Fprintf(os.Stderr, "Error: %s is not pinned\n", arg)
+			os.Exit(1)
+		}
+		var kept []string
+		for _, pinned := range config.Pinned {
+			if pinned != path {
+				kept = append(kept, pinned)
+			}
+		}
+		config.Pinned = kept
+		fmt.Printf("Unpinned %s\n", shortenPath(path))
+	}
+
+	if err := config.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func listPins(config *Config, asJSON bool) {
+	if asJSON {
+		writeJSON("pins", map[string]interface{}{
+			"projects": append([]string{}, config.Pinned...),
+			"ports":    append([]int{}, config.PinnedPorts...),
+		})
+		return
+	}
+	if len(config.Pinned) == 0 && len(config.PinnedPorts) == 0 {
+		fmt.Println("No pinned projects or ports (pin one with `portage pin <path|port>` or p in interactive mode)")
+		return
+	}
+	for _, path := range config.Pinned {
+		fmt.Println(pinnedMarker + shortenPath(path))
+	}
+	for _, port := range config.PinnedPorts {
+		fmt.Printf("%sport %d\n", pinnedMarker, port)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(list []int, n int) bool {
+	for _, item := range list {
+		if item == n {
+			return true
+		}
+	}
+	return false
+}
+
+func isDirectory(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// otherPortRange is the section of ports outside every range
+const otherPortRange = -1
+
+// portRanges returns the range starts from [ranges] in config.toml (3000, 4000 and 8000
+// by default) in ascending order; each covers 1000 ports
+func portRanges() []int {
+	ranges := append([]int{}, loadSettings().Ranges.Starts...)
+	sort.Ints(ranges)
+	return ranges
+}
+
+// inPortRanges reports whether port falls in one of the ranges
+func inPortRanges(port int, ranges []int) bool {
+	for _, start := range ranges {
+		if port >= start && port < start+1000 {
+			return true
+		}
+	}
+	return false
+}
+
+// portRangeSections maps each port ("port-pid") to the start of its range, using the
+// first range when they overlap, or to otherPortRange
+func portRangeSections(ports []PortInfo, ranges []int) map[string]int {
+	sections := make(map[string]int)
+	byRange := filterPorts(ports, ranges)
+	for _, start := range ranges {
+		for _, port := range byRange[start] {
+			key := fmt.Sprintf("%d-%s", port.Port, port.PID)
+			if _, ok := sections[key]; !ok {
+				sections[key] = start
+			}
+		}
+	}
+	for _, port := range ports {
+		key := fmt.Sprintf("%d-%s", port.Port, port.PID)
+		if _, ok := sections[key]; !ok {
+			sections[key] = otherPortRange
+		}
+	}
+	return sections
+}
+
+// portRangeTitle names a section: "3000s", or "other"
+func portRangeTitle(start int) string {
+	if start == otherPortRange {
+		return "other"
+	}
+	return fmt.Sprintf("%ds", start)
+}
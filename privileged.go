@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// elevated reports whether --sudo should re-run lsof through sudo (not needed when already root)
+func elevated() bool {
+	return useSudo && os.Geteuid() != 0
+}
+
+// lsofListing runs the listener scan, through sudo with --sudo so other users'
+// processes are included. sudo prompts on the terminal if it has no cached credentials.
+func lsofListing() ([]byte, error) {
+	if elevated() {
+		output, err := commandOutput("sudo", "lsof", "-i", "-P", "-n")
+		if err != nil {
+			return nil, fmt.Errorf("sudo lsof failed: %w", err)
+		}
+		return output, nil
+	}
+	return commandOutput("lsof", "-i", "-P", "-n")
+}
+
+// markElevatedPorts flags listeners that an unprivileged lsof can't see, so it's clear
+// which rows only exist because of --sudo
+func markElevatedPorts(ports []PortInfo) {
+	if !elevated() {
+		return
+	}
+
+	output, _ := commandOutput("lsof", "-i", "-P", "-n") // Exits non-zero on partial results
+	visible := make(map[string]bool)
+	for _, port := range parseOutput(string(output)) {
+		visible[fmt.Sprintf("%d-%s", port.Port, port.PID)] = true
+	}
+
+	for i := range ports {
+		ports[i].Elevated = !visible[fmt.Sprintf("%d-%s", ports[i].Port, ports[i].PID)]
+	}
+}
+
+// privilegedWorkingDirectory looks up the cwd of another user's process via sudo
+func privilegedWorkingDirectory(pid string) ([]byte, error) {
+	return commandOutput("sudo", "lsof", "-a", "-p", pid, "-d", "cwd", "-Fn")
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ProfileSettings is a [profiles.<name>] table of config.toml. The keys it sets replace
+// the top-level ones while the profile is in use (--profile or PORTAGE_PROFILE); the
+// keys it leaves out keep their top-level value.
+type ProfileSettings struct {
+	Filters FilterSettings `toml:"filters"`
+	Ranges  RangeSettings  `toml:"ranges"`
+}
+
+// ProfileConfig is what portage saves for a profile in portage.json
+type ProfileConfig struct {
+	HideRules []HideRule `json:"hide_rules,omitempty"` // used instead of the top-level hide_rules
+}
+
+// activeProfile is the profile in use, "" for none: --profile, else PORTAGE_PROFILE
+var activeProfile = os.Getenv("PORTAGE_PROFILE")
+
+// withProfile returns the settings with a profile's keys in place of the top-level ones.
+// Lists a profile doesn't set are nil after decoding, unlike empty ones.
+func (s *Settings) withProfile(name string) (*Settings, error) {
+	if name == "" {
+		return s, nil
+	}
+	profile, ok := s.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q (%s)", name, s.describeProfiles())
+	}
+	merged := *s
+	for _, list := range []struct {
+		target *[]string
+		value  []string
+	}{
+		{&merged.Filters.IncludePaths, profile.Filters.IncludePaths},
+		{&merged.Filters.ExcludePaths, profile.Filters.ExcludePaths},
+		{&merged.Filters.ExcludeCommands, profile.Filters.ExcludeCommands},
+		{&merged.Filters.ExcludeUsers, profile.Filters.ExcludeUsers},
+	} {
+		if list.value != nil {
+			*list.target = list.value
+		}
+	}
+	if profile.Filters.ExcludePorts != nil {
+		merged.Filters.ExcludePorts = profile.Filters.ExcludePorts
+	}
+	if profile.Ranges.Starts != nil {
+		merged.Ranges.Starts = profile.Ranges.Starts
+	}
+	return &merged, nil
+}
+
+// profileNames lists the profiles of config.toml in order
+func (s *Settings) profileNames() []string {
+	var names []string
+	for name := range s.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// describeProfiles says which profiles there are, for errors about a missing one
+func (s *Settings) describeProfiles() string {
+	if len(s.Profiles) == 0 {
+		return "config.toml has no [profiles.<name>] tables"
+	}
+	return "available: " + strings.Join(s.profileNames(), ", ")
+}
+
+// useProfile switches to a profile after config.toml was loaded, for --profile
+func useProfile(name string) error {
+	settings, err := fileSettings().withProfile(name)
+	if err != nil {
+		return err
+	}
+	activeProfile = name
+	loadedSettings = settings
+	return nil
+}
+
+// hideRules returns the hide rules in use: the active profile's, else hide_rules
+func (c *Config) hideRules() *[]HideRule {
+	if activeProfile == "" {
+		return &c.HideRules
+	}
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]*ProfileConfig)
+	}
+	if c.Profiles[activeProfile] == nil {
+		c.Profiles[activeProfile] = &ProfileConfig{}
+	}
+	return &c.Profiles[activeProfile].HideRules
+}
+
+// listProfiles prints each profile of config.toml with the keys it sets, marking the
+// one in use
+func listProfiles(settings *Settings) {
+	if len(settings.Profiles) == 0 {
+		fmt.Println("No profiles; add a [profiles.<name>] table to config.toml (portage config edit)")
+		return
+	}
+	for _, name := range settings.profileNames() {
+		marker := "  "
+		if name == activeProfile {
+			marker = "* "
+		}
+		var keys []string
+		for _, entry := range settingsEntries(reflect.ValueOf(settings.Profiles[name]), nil) {
+			if !entry.value.IsNil() { // keys the profile leaves out
+				keys = append(keys, entry.key())
+			}
+		}
+		if len(keys) == 0 {
+			keys = []string{"(sets nothing, only keeps its own hides)"}
+		}
+		fmt.Printf("%s%-12s %s\n", marker, name, strings.Join(keys, ", "))
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// projectFileName is the per-project file portage looks for in a listener's working
+// directory and its parents
+const projectFileName = ".portage.yml"
+
+// ProjectFile is a .portage.yml kept in a project, usually committed with it. For the
+// listeners under its directory it overrides portage.json.
+type ProjectFile struct {
+	Name    string         `yaml:"name"`    // NAME of the project's listeners
+	Launch  string         `yaml:"launch"`  // started by x (launch.go)
+	Ports   []int          `yaml:"ports"`   // ports it's expected to listen on; others are flagged
+	Aliases map[int]string `yaml:"aliases"` // port -> NAME, for projects with several servers
+
+	dir string // where the file was found
+	err error  // why it couldn't be read, reported instead of its contents
+}
+
+// projectFiles caches parsed project files by path until they change on disk
+var projectFiles = struct {
+	sync.Mutex
+	byPath map[string]projectFileEntry
+}{byPath: make(map[string]projectFileEntry)}
+
+type projectFileEntry struct {
+	modTime time.Time
+	file    *ProjectFile
+}
+
+// findProjectFile returns the .portage.yml of the closest directory containing path,
+// stopping below the home directory so a stray ~/.portage.yml doesn't apply everywhere.
+// Without one it returns nil; a file that doesn't parse comes back with err set.
+func findProjectFile(path string) *ProjectFile {
+	if path == "" || path == "N/A" || path == "/" {
+		return nil
+	}
+	home, _ := os.UserHomeDir()
+	for dir := path; dir != home && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if file := readProjectFile(filepath.Join(dir, projectFileName)); file != nil {
+			return file
+		}
+	}
+	return nil
+}
+
+// readProjectFile parses a project file, or returns the cached copy if it hasn't changed
+func readProjectFile(path string) *ProjectFile {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return nil
+	}
+
+	projectFiles.Lock()
+	defer projectFiles.Unlock()
+	if entry, ok := projectFiles.byPath[path]; ok && entry.modTime.Equal(info.ModTime()) {
+		return entry.file
+	}
+
+	file := &ProjectFile{dir: filepath.Dir(path)}
+	if data, err := os.ReadFile(path); err != nil {
+		file.err = err
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true) // typos are reported, not silently ignored
+		if err := decoder.Decode(file); err != nil && !errors.Is(err, io.EOF) {
+			*file = ProjectFile{dir: file.dir, err: projectFileError(err)}
+		}
+	}
+	projectFiles.byPath[path] = projectFileEntry{modTime: info.ModTime(), file: file}
+	return file
+}
+
+// projectFileError rewords yaml's errors for people: "line 2: unknown key nme" rather
+// than "field nme not found in type main.ProjectFile"
+func projectFileError(err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return errors.New(strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	problems := make([]string, len(typeErr.Errors))
+	for i, problem := range typeErr.Errors {
+		problem = strings.Replace(problem, "field ", "unknown key ", 1)
+		problems[i] = strings.TrimSuffix(problem, " not found in type main.ProjectFile")
+	}
+	return errors.New(strings.Join(problems, "; "))
+}
+
+// expects reports whether port is one of the project's declared ports; a project that
+// declares none expects any port
+func (p *ProjectFile) expects(port int) bool {
+	if len(p.Ports) == 0 {
+		return true
+	}
+	for _, expected := range p.Ports {
+		if expected == port {
+			return true
+		}
+	}
+	return false
+}
+
+// describePorts lists the declared ports with their aliases: "3000, 6006 (storybook)"
+func (p *ProjectFile) describePorts() string {
+	ports := append([]int{}, p.Ports...)
+	sort.Ints(ports)
+	var described []string
+	for _, port := range ports {
+		described = append(described, p.describePort(port))
+	}
+	return strings.Join(described, ", ")
+}
+
+// describePort is a port with its alias, if it has one: "6006 (storybook)"
+func (p *ProjectFile) describePort(port int) string {
+	if name := p.Aliases[port]; name != "" {
+		return fmt.Sprintf("%d (%s)", port, name)
+	}
+	return strconv.Itoa(port)
+}
+
+// resolveProjectFiles flags listeners on ports their project file doesn't declare;
+// tooling daemons pick their own ports and aren't flagged
+func resolveProjectFiles(portsByRange map[int][]PortInfo) {
+	for _, ports := range portsByRange {
+		for i := range ports {
+			if project := findProjectFile(ports[i].Path); project != nil && project.err == nil {
+				ports[i].Unexpected = !project.expects(ports[i].Port) && ports[i].Daemon == ""
+			}
+		}
+	}
+}
+
+// projectFileProblems compares every project file found among the listeners with what
+// is actually listening: declared ports that are free or taken by something else, and
+// ports the project listens on without declaring them. Files that don't parse are
+// reported too.
+func projectFileProblems(ports []PortInfo) []string {
+	var projects []*ProjectFile
+	seen := make(map[string]bool)
+	for _, port := range ports {
+		if project := findProjectFile(port.Path); project != nil && !seen[project.dir] {
+			seen[project.dir] = true
+			projects = append(projects, project)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].dir < projects[j].dir })
+
+	var problems []string
+	for _, project := range projects {
+		problems = append(problems, project.problems(ports)...)
+	}
+	return problems
+}
+
+// problems lists where a project's listeners differ from its project file
+func (p *ProjectFile) problems(ports []PortInfo) []string {
+	dir := shortenPath(p.dir)
+	if p.err != nil {
+		return []string{fmt.Sprintf("%s/%s: %v", dir, projectFileName, p.err)}
+	}
+
+	var problems []string
+	var own []PortInfo // a subproject with its own file isn't this project's
+	for _, port := range ports {
+		if project := findProjectFile(port.Path); project != nil && project.dir == p.dir {
+			own = append(own, port)
+		}
+	}
+	for _, expected := range p.Ports {
+		listening, takenBy := false, ""
+		for _, port := range own {
+			listening = listening || port.Port == expected
+		}
+		for _, port := range ports {
+			if port.Port == expected && !listening {
+				takenBy = fmt.Sprintf("%s in %s", port.Command, shortenPath(port.Path))
+			}
+		}
+		switch {
+		case listening:
+		case takenBy != "":
+			problems = append(problems, fmt.Sprintf("%s expects port %s, taken by %s", dir, p.describePort(expected), takenBy))
+		default:
+			problems = append(problems, fmt.Sprintf("%s expects port %s, nothing is listening", dir, p.describePort(expected)))
+		}
+	}
+	reported := make(map[int]bool)
+	for _, port := range own {
+		if !p.expects(port.Port) && !reported[port.Port] && port.Daemon == "" {
+			reported[port.Port] = true
+			problems = append(problems, fmt.Sprintf("%s listens on %d, not one of its ports (%s)", dir, port.Port, p.describePorts()))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// parsePortFilter reads --port: one port or a comma-separated list
+func parsePortFilter(value string) ([]int, error) {
+	var ports []int
+	for _, field := range strings.Split(value, ",") {
+		port, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid port %q", field)
+		}
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+// matchesPortFilter reports whether a port is one of --port, if given
+func matchesPortFilter(port PortInfo) bool {
+	if len(portFilter) == 0 {
+		return true
+	}
+	for _, p := range portFilter {
+		if port.Port == p {
+			return true
+		}
+	}
+	return false
+}
+
+// quietField returns what -q prints for a port: its number, or the field picked by --print
+func quietField(port PortInfo) string {
+	switch printField {
+	case "pid":
+		return port.PID
+	case "path":
+		return port.Path
+	}
+	return strconv.Itoa(port.Port)
+}
+
+// displayQuiet prints one value per line for -q, in the --sort order, each value once
+// (a process on several ports is one PID). It exits 1 when nothing matched, so scripts
+// can test `portage -q --port 3000` like grep -q.
+func displayQuiet(portsByRange map[int][]PortInfo, sortOrder string) {
+	seen := make(map[string]bool)
+	for _, port := range sortedPortList(portsByRange, sortOrder) {
+		value := quietField(port)
+		if !seen[value] {
+			seen[value] = true
+			fmt.Println(value)
+		}
+	}
+	if len(seen) == 0 {
+		os.Exit(1)
+	}
+}
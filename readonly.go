@@ -0,0 +1,10 @@
+package main
+
+import "errors"
+
+// readOnly disables everything that changes the machine or portage's own files:
+// killing processes, saving hidden ports and appending to the port, workspace and
+// activity logs. Set with --read-only or "read_only": true in portage.json.
+var readOnly bool
+
+var errReadOnly = errors.New("disabled in read-only mode (--read-only or \"read_only\" in portage.json)")
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// restartProcess stops a listener and starts its command line again in the same
+// working directory. The new process is detached from portage (its own session) and
+// its output goes to a log file in the temp directory, whose path is returned.
+//
+// The command line comes from ps, which doesn't keep the original quoting, so
+// arguments containing spaces won't survive a restart.
+func restartProcess(port PortInfo) (string, error) {
+	if err := checkRelaunchable(port); err != nil {
+		return "", err
+	}
+	if demoMode {
+		return "", nil
+	}
+
+	if err := killProcess(port.PID); err != nil {
+		return "", fmt.Errorf("failed to stop PID %s: %w", port.PID, err)
+	}
+
+	// Wait for the port to be released before starting the replacement
+	if !waitForExit(port.PID, 5*time.Second) {
+		return "", fmt.Errorf("PID %s didn't exit within 5s", port.PID)
+	}
+	return startCommandLine(port)
+}
+
+// checkRelaunchable reports why a process can't be started again from its command line
+// and working directory, if it can't
+func checkRelaunchable(port PortInfo) error {
+	if port.CommandLine == "" {
+		return fmt.Errorf("command line of PID %s is unknown", port.PID)
+	}
+	if port.Path == "N/A" || port.Path == "/" || port.Orphaned {
+		return fmt.Errorf("working directory of PID %s is unknown or gone", port.PID)
+	}
+	if readOnly {
+		return errReadOnly
+	}
+	return nil
+}
+
+// startCommandLine runs a process's command line again in its working directory, detached
+// from portage, and returns the path of the log its output goes to
+func startCommandLine(port PortInfo) (string, error) {
+	if demoMode {
+		return "", nil
+	}
+
+	logPath := filepath.Join(os.TempDir(), fmt.Sprintf("portage-restart-%d.log", port.Port))
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return "", err
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command("sh", "-c", port.CommandLine)
+	cmd.Dir = port.Path
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+	return logPath, cmd.Process.Release()
+}
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Settings is config.toml, the configuration you write by hand. What portage saves by
+// itself (hides, pins, notes, columns, ...) stays in portage.json next to it; where
+// both set something, config.toml wins.
+type Settings struct {
+	Defaults DefaultSettings `toml:"defaults"`
+	Filters  FilterSettings  `toml:"filters"`
+	Ranges   RangeSettings   `toml:"ranges"`
+	Editor   EditorSettings  `toml:"editor"`
+	Theme    *ThemeConfig    `toml:"theme"`
+	Logs     LogSettings     `toml:"logs"`
+
+	Profiles map[string]ProfileSettings `toml:"profiles"` // [profiles.<name>] tables, picked with --profile (profiles.go)
+}
+
+// DefaultSettings are what portage does without flags; the flags still override them
+// (flagdefaults.go)
+type DefaultSettings struct {
+	Sort        string `toml:"sort"`        // --sort
+	Limit       int    `toml:"limit"`       // --limit, for the history modes
+	All         bool   `toml:"all"`         // --all
+	JSON        bool   `toml:"json"`        // --json, unless the command line picks another output
+	Interactive bool   `toml:"interactive"` // -i, likewise
+}
+
+// FilterSettings decide which listeners are dev servers when not showing all ports;
+// --include-system ignores them for a run
+type FilterSettings struct {
+	IncludePaths    []string `toml:"include_paths"`    // if set, listeners elsewhere are folded as "other" (includepaths.go), globs allowed
+	ExcludePaths    []string `toml:"exclude_paths"`    // working directories (and everything under them) to leave out, globs allowed
+	ExcludeCommands []string `toml:"exclude_commands"` // process names to leave out
+	ExcludeUsers    []string `toml:"exclude_users"`    // process owners to leave out
+	ExcludePorts    []int    `toml:"exclude_ports"`    // port numbers to leave out
+}
+
+type RangeSettings struct {
+	Starts []int `toml:"starts"` // --by-range sections, 1000 ports from each start (portranges.go)
+}
+
+type EditorSettings struct {
+	Command  string            `toml:"command"`  // opens projects, e.g. "code -n {path}"; PORTAGE_EDITOR still wins over it, EDITOR doesn't (editor.go)
+	Terminal string            `toml:"terminal"` // run by t, e.g. "kitty --directory {path}" (openterminal.go)
+	Projects map[string]string `toml:"projects"` // project directory -> editor command, winning over everything else
+}
+
+type LogSettings struct {
+	History    string `toml:"history"`    // SQLite database of ports as they're first seen, workspace events and scans (historydb.go)
+	Ports      string `toml:"ports"`      // ports log of older versions, imported into history once
+	Workspaces string `toml:"workspaces"` // workspaces log of older versions, imported into history once
+	Activity   string `toml:"activity"`   // --record-activity samples
+
+	// Rotation of history and activity (logrotate.go); 0 turns each off
+	MaxSizeMB  int `toml:"max_size_mb"`  // past this, the older half of a log is rotated out
+	MaxAgeDays int `toml:"max_age_days"` // entries older than this are rotated out
+	Archives   int `toml:"archives"`     // rotated-out archives kept next to each log
+}
+
+// defaultSettings are used for everything config.toml leaves out
+func defaultSettings() *Settings {
+	return &Settings{
+		Defaults: DefaultSettings{Sort: "uptime", Limit: 10},
+		Filters: FilterSettings{
+			ExcludePaths:    []string{"/opt", "/usr", "/System", "/Library", "~/Library"},
+			ExcludeCommands: []string{"redis-server"},
+		},
+		Ranges: RangeSettings{Starts: []int{3000, 4000, 8000}},
+		Logs: LogSettings{
+			History:    shortenPath(filepath.Join(stateDir(), "history.db")),
+			Ports:      shortenPath(filepath.Join(stateDir(), "ports.log")),
+			Workspaces: shortenPath(filepath.Join(stateDir(), "workspaces.log")),
+			Activity:   shortenPath(filepath.Join(stateDir(), "activity.log")),
+			MaxSizeMB:  50,
+			Archives:   3,
+		},
+	}
+}
+
+// getSettingsPath returns config.toml in the config directory (xdg.go)
+func getSettingsPath() string {
+	return filepath.Join(configDir(), "config.toml")
+}
+
+var (
+	settingsOnce   sync.Once
+	loadedSettings *Settings // with the active profile applied
+	savedSettings  *Settings // as config.toml has them
+)
+
+// loadSettings reads config.toml once. A file that doesn't parse or validate stops
+// portage with every problem listed, rather than running with half a configuration.
+// The active profile's keys replace the top-level ones.
+func loadSettings() *Settings {
+	settingsOnce.Do(func() {
+		settings, err := readSettings(getSettingsPath())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error in %s:\n%v\n", shortenPath(getSettingsPath()), err)
+			os.Exit(1)
+		}
+		savedSettings = settings
+		if loadedSettings, err = settings.withProfile(activeProfile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error in PORTAGE_PROFILE: %v\n", err)
+			os.Exit(1)
+		}
+	})
+	return loadedSettings
+}
+
+// fileSettings is config.toml without a profile applied
+func fileSettings() *Settings {
+	loadSettings()
+	return savedSettings
+}
+
+// readSettings parses and validates a config file on top of the defaults; a missing
+// file is all defaults
+func readSettings(path string) (*Settings, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return defaultSettings(), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("  %v", err)
+	}
+	settings, _, err := decodeSettings(string(data))
+	return settings, err
+}
+
+// decodeSettings parses and validates the content of a config file on top of the
+// defaults; meta tells which keys the content sets
+func decodeSettings(content string) (settings *Settings, meta toml.MetaData, err error) {
+	settings = defaultSettings()
+	meta, err = toml.Decode(content, settings)
+	if err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, meta, errors.New(indentLines(strings.TrimPrefix(parseErr.ErrorWithPosition(), "toml: error: ")))
+		}
+		return nil, meta, fmt.Errorf("  %s", strings.TrimPrefix(err.Error(), "toml: "))
+	}
+
+	var problems []string
+	unknown := make(map[string]bool)
+	for _, key := range meta.Undecoded() {
+		// A misspelled table is reported once, not once more for each of its keys
+		if len(key) > 1 && unknown[key[:len(key)-1].String()] {
+			continue
+		}
+		unknown[key.String()] = true
+		problems = append(problems, unknownSettingsKey(key.String()))
+	}
+	problems = append(problems, settings.validate()...)
+	if len(problems) > 0 {
+		return nil, meta, errors.New("  " + strings.Join(problems, "\n  "))
+	}
+	return settings, meta, nil
+}
+
+// validate reports values that parse but can't work
+func (s *Settings) validate() []string {
+	problems := s.Defaults.validate()
+	problems = append(problems, s.Filters.validate("filters")...)
+	problems = append(problems, s.Ranges.validate("ranges")...)
+	for _, name := range s.profileNames() {
+		table := "profiles." + tomlKey(name)
+		problems = append(problems, s.Profiles[name].Filters.validate(table+".filters")...)
+		problems = append(problems, s.Profiles[name].Ranges.validate(table+".ranges")...)
+	}
+	for _, log := range []struct{ key, path string }{
+		{"history", s.Logs.History}, {"ports", s.Logs.Ports}, {"workspaces", s.Logs.Workspaces}, {"activity", s.Logs.Activity},
+	} {
+		if !filepath.IsAbs(log.path) && !strings.HasPrefix(log.path, "~/") {
+			problems = append(problems, fmt.Sprintf("logs.%s: %q must be an absolute path or start with ~/", log.key, log.path))
+		}
+	}
+	for _, limit := range []struct {
+		key   string
+		value int
+	}{{"max_size_mb", s.Logs.MaxSizeMB}, {"max_age_days", s.Logs.MaxAgeDays}, {"archives", s.Logs.Archives}} {
+		if limit.value < 0 {
+			problems = append(problems, fmt.Sprintf("logs.%s: %d can't be negative", limit.key, limit.value))
+		}
+	}
+	if s.Editor.Command != "" {
+		if err := validateEditorTemplate(s.Editor.Command); err != nil {
+			problems = append(problems, "editor.command: "+err.Error())
+		}
+	}
+	for dir, command := range s.Editor.Projects {
+		if !filepath.IsAbs(dir) && !strings.HasPrefix(dir, "~/") {
+			problems = append(problems, fmt.Sprintf("editor.projects: %q must be an absolute path or start with ~/", dir))
+		}
+		if err := validateEditorTemplate(command); err != nil {
+			problems = append(problems, fmt.Sprintf("editor.projects.%q: %v", dir, err))
+		}
+	}
+	if s.Theme != nil {
+		if _, err := resolveTheme(s.Theme, ""); err != nil {
+			problems = append(problems, "theme: "+err.Error())
+		}
+	}
+	return problems
+}
+
+// validate reports filters that can't work; table is where they are in config.toml
+func (f FilterSettings) validate(table string) []string {
+	var problems []string
+	for _, paths := range []struct {
+		key      string
+		patterns []string
+	}{{"include_paths", f.IncludePaths}, {"exclude_paths", f.ExcludePaths}} {
+		for _, path := range paths.patterns {
+			if !filepath.IsAbs(path) && !strings.HasPrefix(path, "~/") {
+				problems = append(problems, fmt.Sprintf("%s.%s: %q must be an absolute path or start with ~/", table, paths.key, path))
+			} else if _, err := filepath.Match(path, ""); err != nil {
+				problems = append(problems, fmt.Sprintf("%s.%s: %q is not a valid glob", table, paths.key, path))
+			}
+		}
+	}
+	for _, port := range f.ExcludePorts {
+		if port < 1 || port > 65535 {
+			problems = append(problems, fmt.Sprintf("%s.exclude_ports: %d is not a port (1-65535)", table, port))
+		}
+	}
+	return problems
+}
+
+// validate reports range starts that aren't ports; table is where they are in config.toml
+func (r RangeSettings) validate(table string) []string {
+	var problems []string
+	for _, start := range r.Starts {
+		if start < 1 || start > 65535 {
+			problems = append(problems, fmt.Sprintf("%s.starts: %d is not a port (1-65535)", table, start))
+		}
+	}
+	return problems
+}
+
+// unknownSettingsKey describes a key config.toml doesn't have, suggesting the closest
+// one for typos
+func unknownSettingsKey(key string) string {
+	best, bestDistance := "", 4
+	for _, known := range settingsKeys(reflect.TypeOf(Settings{}), "") {
+		if d := editDistance(key, known); d < bestDistance {
+			best, bestDistance = known, d
+		}
+	}
+	if best != "" {
+		return fmt.Sprintf("%s: unknown key (did you mean %s?)", key, best)
+	}
+	return fmt.Sprintf("%s: unknown key (see `portage config init` for every key)", key)
+}
+
+// settingsKeys lists every dotted key of a settings struct from its toml tags, tables
+// included
+func settingsKeys(t reflect.Type, prefix string) []string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("toml")
+		if name == "" {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		keys = append(keys, prefix+name)
+		if fieldType.Kind() == reflect.Struct {
+			keys = append(keys, settingsKeys(fieldType, prefix+name+".")...)
+		}
+	}
+	return keys
+}
+
+// editDistance is the Levenshtein distance between two keys
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func indentLines(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	return "  " + strings.Join(lines, "\n  ")
+}
+
+// excludes reports whether one of the filters leaves a listener out
+func (f FilterSettings) excludes(port PortInfo) bool {
+	// Commands by full executable name when the command line is known, otherwise by
+	// lsof's 9-character prefix of it
+	name := processName(port)
+	for _, cmd := range f.ExcludeCommands {
+		if name == cmd || (port.CommandLine == "" && len(name) >= 9 && strings.HasPrefix(cmd, name)) {
+			return true
+		}
+	}
+	for _, user := range f.ExcludeUsers {
+		if port.User != "" && port.User == user {
+			return true
+		}
+	}
+	for _, excluded := range f.ExcludePorts {
+		if port.Port == excluded {
+			return true
+		}
+	}
+	return f.excludesPath(port.Path)
+}
+
+// excludesPath reports whether a working directory is in one of filters.exclude_paths
+func (f FilterSettings) excludesPath(path string) bool {
+	return matchesPathPatterns(f.ExcludePaths, path)
+}
+
+// includesPath reports whether a working directory is in one of filters.include_paths;
+// without any, every directory is
+func (f FilterSettings) includesPath(path string) bool {
+	return len(f.IncludePaths) == 0 || matchesPathPatterns(f.IncludePaths, path)
+}
+
+// matchesPathPatterns reports whether a path, or one of its parents, matches one of the
+// patterns: "/opt" matches everything under /opt, "~/Library/*/Caches" the caches of
+// every app
+func matchesPathPatterns(patterns []string, path string) bool {
+	path = expandHome(path)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(expandHome(pattern), "/")
+		for dir := path; ; dir = filepath.Dir(dir) {
+			if matched, _ := filepath.Match(pattern, dir); matched {
+				return true
+			}
+			if dir == filepath.Dir(dir) {
+				break
+			}
+		}
+	}
+	return false
+}
+
+// settingsTemplate is what `portage config init` writes: every key with its default
+func settingsTemplate() string {
+	logs := defaultSettings().Logs
+	return fmt.Sprintf(`# portage configuration (https://github.com/inem/portage#config-file)
+# Every value below is the default; delete what you don't change.
+# Hides, pins, notes and columns are saved by portage in portage.json next to this file.
+
+[defaults]
+# What portage does without flags; flags still override them (--json=false, -i=false).
+sort = "uptime"      # uptime, port, command, path or cpu
+limit = 10           # entries of --history and --cursor-history
+all = false          # list every port, not just the dev ranges
+# Print JSON or start interactive mode, unless the command line picks an output itself
+# (-q, --json, --format, --watch, --history, ...).
+json = false
+interactive = false
+
+[filters]
+# Only list listeners under these directories, e.g. ["~/dev", "~/work"]; the rest is
+# folded into "other" (a in interactive mode shows it). Empty lists every directory.
+include_paths = []
+# Listeners that aren't dev servers. --include-system shows them anyway for a run,
+# --all shows every port.
+# Working directories, and everything below them; globs like "~/src/*/vendor" work.
+exclude_paths = ["/opt", "/usr", "/System", "/Library", "~/Library"]
+# Process names, wherever they run from. Remove redis-server to see your redis.
+exclude_commands = ["redis-server"]
+# Process owners, e.g. ["postgres", "_mysql"].
+exclude_users = []
+# Port numbers, e.g. [5432, 6379].
+exclude_ports = []
+
+[ranges]
+# Sections of --by-range, 1000 ports from each start; the rest is "other".
+starts = [3000, 4000, 8000]
+
+[editor]
+# Opens projects (Enter/e). Without it: $EDITOR, then cursor. $PORTAGE_EDITOR wins over it.
+# {path} is the project directory (appended when left out), {port} the selected port.
+# command = "code -n {path}"
+# Run by t instead of tmux/iTerm2/Terminal.app; {path} is the project directory.
+# terminal = "kitty --directory {path}"
+
+# [editor.projects]
+# Editors for some projects, winning over everything above.
+# "~/work/android-app" = "studio {path}"
+
+[theme]
+# name = "dark"        # dark, light, solarized or monochrome
+# accent = "6"         # ANSI numbers like "33" or hex like "#268bd2"
+# muted = "244"
+# warning = "3"
+# success = "2"
+# danger = "1"
+# selected_bg = "240"
+# selected_fg = "15"
+# borders = "plain"    # plain, rounded, light, double or bold
+
+# [profiles.work.filters]
+# Profiles swap in their own filters and ranges with --profile work (or PORTAGE_PROFILE),
+# keeping the keys above that they leave out. Hides made in a profile stay in it.
+# include_paths = ["~/clients/acme"]
+# [profiles.work.ranges]
+# starts = [3000, 9000]
+
+[logs]
+# Ports as they're first seen, workspace events and scan snapshots (SQLite).
+history = %q
+# Text logs of older versions, imported into history once and renamed to *.imported.
+ports = %q
+workspaces = %q
+# --record-activity samples.
+activity = %q
+# History and activity are rotated when they pass max_size_mb (their older half) or
+# hold entries older than max_age_days; what's rotated out goes to an archive next
+# to them, of which the newest archives are kept. 0 turns each off.
+max_size_mb = %d
+max_age_days = %d
+archives = %d
+`, logs.History, logs.Ports, logs.Workspaces, logs.Activity, logs.MaxSizeMB, logs.MaxAgeDays, logs.Archives)
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// shellCommand is one entry of shell history with the directory it most likely ran in
+type shellCommand struct {
+	Time    time.Time
+	Dir     string // "" when the directory couldn't be worked out
+	Command string
+}
+
+// cdTracker follows `cd` commands through a history file. Relative cds are resolved
+// against the previous directory, so interleaved history from several shells is
+// only approximate.
+type cdTracker struct {
+	home string
+	cwd  string
+}
+
+func (t *cdTracker) apply(command string) string {
+	if dir, ok := parseCdTarget(command, t.cwd, t.home); ok {
+		t.cwd = dir
+	}
+	return t.cwd
+}
+
+var zshHistoryLine = regexp.MustCompile(`^: (\d+):\d+;(.*)$`)
+
+// readZshHistory reads an extended zsh history ($HISTFILE or ~/.zsh_history by default)
+func readZshHistory(file string) ([]shellCommand, error) {
+	if file == "" {
+		file = os.Getenv("HISTFILE")
+	}
+	if file == "" {
+		file = expandHome("~/.zsh_history")
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	home, _ := os.UserHomeDir()
+	tracker := &cdTracker{home: home}
+	timestamped := 0
+
+	var commands []shellCommand
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		match := zshHistoryLine.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue // Continuation line of a multi-line command
+		}
+		timestamped++
+		seconds, _ := strconv.ParseInt(match[1], 10, 64)
+		dir := tracker.apply(match[2])
+		commands = append(commands, shellCommand{Time: time.Unix(seconds, 0), Dir: dir, Command: match[2]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if timestamped == 0 {
+		return nil, fmt.Errorf("%s has no timestamps; enable setopt EXTENDED_HISTORY", file)
+	}
+	return commands, nil
+}
+
+// readFishHistory reads fish's YAML-like history (~/.local/share/fish/fish_history)
+func readFishHistory(file string) ([]shellCommand, error) {
+	if file == "" {
+		file = expandHome("~/.local/share/fish/fish_history")
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	home, _ := os.UserHomeDir()
+	tracker := &cdTracker{home: home}
+
+	var commands []shellCommand
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if cmd, ok := strings.CutPrefix(line, "- cmd: "); ok {
+			cmd = strings.ReplaceAll(cmd, `\n`, "\n")
+			commands = append(commands, shellCommand{Dir: tracker.apply(cmd), Command: cmd})
+			continue
+		}
+		if when, ok := strings.CutPrefix(line, "  when: "); ok && len(commands) > 0 {
+			seconds, _ := strconv.ParseInt(strings.TrimSpace(when), 10, 64)
+			commands[len(commands)-1].Time = time.Unix(seconds, 0)
+		}
+	}
+	return commands, scanner.Err()
+}
+
+// parseCdTarget returns the directory a command changes into, if it starts with cd
+func parseCdTarget(command, cwd, home string) (string, bool) {
+	command = strings.TrimSpace(command)
+	if command != "cd" && !strings.HasPrefix(command, "cd ") {
+		return "", false
+	}
+
+	target := strings.TrimSpace(strings.TrimPrefix(command, "cd"))
+	if idx := strings.IndexAny(target, ";&|"); idx >= 0 {
+		target = strings.TrimSpace(target[:idx])
+	}
+	target = strings.Trim(target, `"'`)
+
+	switch {
+	case target == "" || target == "~":
+		return home, true
+	case target == "-":
+		return "", true // Previous directory is unknown; stop attributing until the next absolute cd
+	case strings.HasPrefix(target, "~/"):
+		return filepath.Join(home, target[2:]), true
+	case filepath.IsAbs(target):
+		return filepath.Clean(target), true
+	case cwd != "":
+		return filepath.Join(cwd, target), true
+	}
+	return "", true
+}
+
+// trivialCommands never start a server, so they're skipped when looking for one
+var trivialCommands = map[string]bool{
+	"cd": true, "ls": true, "ll": true, "la": true, "pwd": true, "clear": true,
+	"exit": true, "history": true, "git": true, "cat": true, "less": true, "vim": true, "nvim": true,
+}
+
+// startingCommand strips a leading `cd dir &&` and returns "" for commands in trivialCommands
+func startingCommand(command string) string {
+	command = strings.TrimSpace(command)
+	if strings.HasPrefix(command, "cd ") {
+		_, rest, ok := strings.Cut(command, "&&")
+		if !ok {
+			return ""
+		}
+		command = strings.TrimSpace(rest)
+	}
+	fields := strings.Fields(command)
+	if len(fields) == 0 || trivialCommands[fields[0]] {
+		return ""
+	}
+	return command
+}
+
+// loadShellHistory merges zsh and fish history, oldest first. Missing files are skipped.
+func loadShellHistory() []shellCommand {
+	var commands []shellCommand
+	if zsh, err := readZshHistory(""); err == nil {
+		commands = append(commands, zsh...)
+	}
+	if fish, err := readFishHistory(""); err == nil {
+		commands = append(commands, fish...)
+	}
+	sort.SliceStable(commands, func(i, j int) bool {
+		return commands[i].Time.Before(commands[j].Time)
+	})
+	return commands
+}
+
+// resolveLastCommands fills in LastCommand with the last non-trivial command run in
+// each port's directory before the process started: most likely how it was launched.
+// Falls back to any command in the same project when nothing ran in that exact directory.
+func resolveLastCommands(ports []PortInfo, history []shellCommand) {
+	now := time.Now()
+	for i := range ports {
+		if ports[i].Path == "" || ports[i].Path == "N/A" {
+			continue
+		}
+		started := now.Add(-time.Duration(ports[i].UptimeSeconds)*time.Second + time.Minute)
+		root := projectRoot(ports[i].Path)
+
+		exact, sameProject := "", ""
+		for _, command := range history {
+			if command.Time.After(started) {
+				break
+			}
+			cmd := startingCommand(command.Command)
+			if cmd == "" || command.Dir == "" {
+				continue
+			}
+			if command.Dir == ports[i].Path {
+				exact = cmd
+			} else if root != "" && (command.Dir == root || strings.HasPrefix(command.Dir, root+"/")) {
+				sameProject = cmd
+			}
+		}
+
+		if exact != "" {
+			ports[i].LastCommand = exact
+		} else {
+			ports[i].LastCommand = sameProject
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultKillGrace is how long "TERM, then KILL" waits unless kill_grace_seconds is set
+const defaultKillGrace = 5 * time.Second
+
+// killChoice is one entry of the signal picker opened with K
+type killChoice struct {
+	label    string
+	signal   string
+	escalate bool // TERM first, KILL if still running after the grace period
+}
+
+var killChoices = []killChoice{
+	{label: "TERM - ask to exit (plain kill)", signal: "TERM"},
+	{label: "KILL - force, can't be trapped", signal: "KILL"},
+	{label: "HUP - reload or hang up", signal: "HUP"},
+	{label: "USR2 - app-defined (e.g. graceful reload)", signal: "USR2"},
+	{label: "TERM, then KILL after %ds", signal: "TERM", escalate: true},
+}
+
+// killPicker is the open signal picker and the processes it applies to
+type killPicker struct {
+	targets []PortInfo
+	cursor  int
+}
+
+// escalatedMsg reports the outcome of "TERM, then KILL" once every process has exited
+// or failed to
+type escalatedMsg struct {
+	target                 string // from describeTargets
+	exited, forced, failed int
+	stopped                []PortInfo // the targets whose process is confirmed gone
+}
+
+// signalProcess sends a signal by name (TERM, KILL, HUP, ...); a no-op in demo mode
+func signalProcess(pid, signal string) error {
+	if readOnly {
+		return errReadOnly
+	}
+	if demoMode {
+		return nil
+	}
+	return exec.Command("kill", "-s", signal, pid).Run()
+}
+
+// waitForExit polls until pid is gone and reports whether it exited within timeout
+func waitForExit(pid string, timeout time.Duration) bool {
+	if demoMode {
+		return true
+	}
+	deadline := time.Now().Add(timeout)
+	for exec.Command("kill", "-0", pid).Run() == nil {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return true
+}
+
+// terminateProcess sends TERM and, if the process traps it or hangs, KILL after grace.
+// It reports whether KILL was needed; err is set unless the process is gone.
+func terminateProcess(pid string, grace time.Duration) (forced bool, err error) {
+	if err := signalProcess(pid, "TERM"); err != nil {
+		return false, err
+	}
+	if waitForExit(pid, grace) {
+		return false, nil
+	}
+	if err := signalProcess(pid, "KILL"); err != nil {
+		return true, err
+	}
+	if !waitForExit(pid, time.Second) {
+		return true, fmt.Errorf("PID %s is still running after KILL", pid)
+	}
+	return true, nil
+}
+
+// killGrace is the escalation delay from the config
+func (c *Config) killGrace() time.Duration {
+	if c.KillGraceSeconds > 0 {
+		return time.Duration(c.KillGraceSeconds) * time.Second
+	}
+	return defaultKillGrace
+}
+
+// uniquePIDs returns the PIDs of ports once each, in order, since one process can listen
+// on several marked ports
+func uniquePIDs(ports []PortInfo) []string {
+	seen := make(map[string]bool)
+	var pids []string
+	for _, port := range ports {
+		if !seen[port.PID] {
+			seen[port.PID] = true
+			pids = append(pids, port.PID)
+		}
+	}
+	return pids
+}
+
+// updateKillPicker handles keys while the signal picker is open
+func (m model) updateKillPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.killPick = nil
+	case "up", "k":
+		if m.killPick.cursor > 0 {
+			m.killPick.cursor--
+		}
+	case "down", "j":
+		if m.killPick.cursor < len(killChoices)-1 {
+			m.killPick.cursor++
+		}
+	case "1", "2", "3", "4", "5":
+		m.killPick.cursor = int(msg.String()[0] - '1')
+		return m.pickKillChoice()
+	case "enter":
+		return m.pickKillChoice()
+	}
+	return m, nil
+}
+
+// pickKillChoice closes the picker and, once confirmed, sends the picked signal
+func (m model) pickKillChoice() (tea.Model, tea.Cmd) {
+	choice := killChoices[m.killPick.cursor]
+	targets := m.killPick.targets
+	m.killPick = nil
+
+	question := fmt.Sprintf("Send %s to %s?", choice.signal, describeTargets(targets))
+	if choice.escalate {
+		question = fmt.Sprintf("Send TERM, then KILL if needed, to %s?", describeTargets(targets))
+	}
+	if len(targets) == 1 {
+		question = strings.TrimSuffix(question, "?") + fmt.Sprintf(" on port %d?", targets[0].Port)
+	}
+	return m.confirmThen(question, func(m model) (tea.Model, tea.Cmd) {
+		return m.applyKillChoice(choice, targets)
+	})
+}
+
+// applyKillChoice sends a signal to targets
+func (m model) applyKillChoice(choice killChoice, targets []PortInfo) (tea.Model, tea.Cmd) {
+	m.marked = make(map[string]bool)
+
+	if choice.escalate {
+		grace := m.config.killGrace()
+		pids := uniquePIDs(targets)
+		m.message = fmt.Sprintf("Sent TERM to %s, KILL follows in %ds if needed…", describeTargets(targets), int(grace.Seconds()))
+		return m, func() tea.Msg {
+			type result struct {
+				pid    string
+				forced bool
+				err    error
+			}
+			results := make(chan result, len(pids))
+			for _, pid := range pids {
+				go func(pid string) {
+					forced, err := terminateProcess(pid, grace)
+					results <- result{pid, forced, err}
+				}(pid)
+			}
+			msg := escalatedMsg{target: describeTargets(targets)}
+			gone := make(map[string]bool)
+			for range pids {
+				switch r := <-results; {
+				case r.err != nil:
+					msg.failed++
+				case r.forced:
+					msg.forced++
+					gone[r.pid] = true
+				default:
+					msg.exited++
+					gone[r.pid] = true
+				}
+			}
+			// Only what is gone can be undone or restarted
+			for _, port := range targets {
+				if gone[port.PID] {
+					msg.stopped = append(msg.stopped, port)
+				}
+			}
+			return msg
+		}
+	}
+
+	// A process listening on several marked ports is only signalled once
+	done := make(map[string]error)
+	var sent, failed int
+	var lastErr error
+	for _, pid := range uniquePIDs(targets) {
+		err := signalProcess(pid, choice.signal)
+		done[pid] = err
+		if err != nil {
+			failed++
+			lastErr = err
+		} else {
+			sent++
+		}
+	}
+
+	// TERM and KILL end the process; HUP and USR2 usually leave it listening
+	ends := choice.signal == "TERM" || choice.signal == "KILL"
+	if ends {
+		var killed []PortInfo
+		for _, port := range targets {
+			if done[port.PID] == nil {
+				m.ports = removePort(m.ports, port)
+				killed = append(killed, port)
+			}
+		}
+		m = m.recordKills(killed)
+		if visible := len(m.getVisiblePorts()); m.cursor >= visible {
+			m.cursor = max(visible-1, 0)
+		}
+	}
+
+	switch {
+	case sent == 0 && failed == 1:
+		m.message = fmt.Sprintf("Failed to send %s to PID %s: %v", choice.signal, targets[0].PID, lastErr)
+	case failed > 0:
+		m.message = fmt.Sprintf("Sent %s to %d processes, %d failed", choice.signal, sent, failed)
+	case ends:
+		m.message = fmt.Sprintf("Killed %s with %s (U: start again)", describeTargets(targets), choice.signal)
+	default:
+		m.message = fmt.Sprintf("Sent %s to %s", choice.signal, describeTargets(targets))
+	}
+	return m, nil
+}
+
+// describeTargets names a single process, or counts several
+func describeTargets(targets []PortInfo) string {
+	if pids := uniquePIDs(targets); len(pids) > 1 {
+		return fmt.Sprintf("%d processes", len(pids))
+	}
+	return fmt.Sprintf("%s (PID %s)", targets[0].Command, targets[0].PID)
+}
+
+// viewKillPicker renders the signal picker in place of the help line
+func (m model) viewKillPicker() string {
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Accent)
+	selectedStyle := ui.selectedStyle()
+	helpStyle := lipgloss.NewStyle().Foreground(ui.Muted)
+
+	var s strings.Builder
+	s.WriteString(headerStyle.Render("Send signal to " + describeTargets(m.killPick.targets)))
+	s.WriteString("\n")
+	for i, choice := range killChoices {
+		label := choice.label
+		if choice.escalate {
+			label = fmt.Sprintf(label, int(m.config.killGrace().Seconds()))
+		}
+		line := fmt.Sprintf("  %d  %s", i+1, label)
+		if i == m.killPick.cursor {
+			line = selectedStyle.Render(line)
+		}
+		s.WriteString(line)
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render("↑/↓ or 1-5: choose • enter: send • esc: cancel"))
+	return s.String()
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Snooze hides a server until a point in time, matching it like a command-and-directory
+// hide rule so a restart doesn't bring it back early
+type Snooze struct {
+	HideRule
+	Key   string    `json:"key,omitempty"` // "port-pid", saved by older versions for listeners without a known path
+	Until time.Time `json:"until"`
+}
+
+func (s Snooze) matches(port PortInfo, now time.Time) bool {
+	if !now.Before(s.Until) {
+		return false
+	}
+	if s.Key != "" {
+		return s.Key == fmt.Sprintf("%d-%s", port.Port, port.PID)
+	}
+	return s.HideRule.matches(port)
+}
+
+// isSnoozed reports whether a listener is hidden by a snooze that hasn't expired
+func (c *Config) isSnoozed(port PortInfo) bool {
+	now := time.Now()
+	for _, snooze := range c.Snoozed {
+		if snooze.matches(port, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// activeSnoozes returns the snoozes that haven't expired
+func (c *Config) activeSnoozes() []Snooze {
+	now := time.Now()
+	var active []Snooze
+	for _, snooze := range c.Snoozed {
+		if now.Before(snooze.Until) {
+			active = append(active, snooze)
+		}
+	}
+	return active
+}
+
+// snoozeChoices are the durations offered by H
+var snoozeChoices = []struct {
+	label string
+	until func(now time.Time) time.Time
+}{
+	{"1 hour", func(now time.Time) time.Time { return now.Add(time.Hour) }},
+	{"8 hours", func(now time.Time) time.Time { return now.Add(8 * time.Hour) }},
+	{"until tomorrow", func(now time.Time) time.Time {
+		return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	}},
+}
+
+// snoozePicker is the open duration menu and the ports it applies to
+type snoozePicker struct {
+	targets []PortInfo
+	cursor  int
+}
+
+// updateSnoozePicker handles keys while the snooze menu is open
+func (m model) updateSnoozePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.snoozePick = nil
+	case "up", "k":
+		if m.snoozePick.cursor > 0 {
+			m.snoozePick.cursor--
+		}
+	case "down", "j":
+		if m.snoozePick.cursor < len(snoozeChoices)-1 {
+			m.snoozePick.cursor++
+		}
+	case "1", "2", "3":
+		m.snoozePick.cursor = int(msg.String()[0] - '1')
+		return m.snoozePorts(), nil
+	case "enter", "H":
+		return m.snoozePorts(), nil
+	}
+	return m, nil
+}
+
+// snoozePorts hides the picked ports until the chosen time and clears the marks
+func (m model) snoozePorts() model {
+	choice := snoozeChoices[m.snoozePick.cursor]
+	targets := m.snoozePick.targets
+	m.snoozePick = nil
+
+	until := choice.until(time.Now())
+	m.config.Snoozed = m.config.activeSnoozes() // forget expired ones while saving anyway
+	for _, port := range targets {
+		rule, _ := hideRuleFor(hideServer, port) // command and port without a known path
+		m.config.Snoozed = append(m.config.Snoozed, Snooze{HideRule: rule, Until: until})
+	}
+	m.config.save()
+	m.marked = make(map[string]bool)
+
+	what := fmt.Sprintf("%d ports", len(targets))
+	if len(targets) == 1 {
+		what = fmt.Sprintf("port %d", targets[0].Port)
+	}
+	m.message = fmt.Sprintf("Snoozed %s until %s", what, formatSnoozeUntil(until))
+	if readOnly {
+		m.message += " for this session (read-only)"
+	}
+	if visible := len(m.getVisiblePorts()); m.cursor >= visible {
+		m.cursor = max(visible-1, 0)
+	}
+	return m
+}
+
+// formatSnoozeUntil shows the time of day, with the weekday when it's not today
+func formatSnoozeUntil(until time.Time) string {
+	now := time.Now()
+	if until.YearDay() == now.YearDay() && until.Year() == now.Year() {
+		return until.Format("15:04")
+	}
+	return until.Format("Mon 15:04")
+}
+
+// viewSnoozePicker renders the duration menu in place of the help line
+func (m model) viewSnoozePicker() string {
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Accent)
+	selectedStyle := ui.selectedStyle()
+	helpStyle := lipgloss.NewStyle().Foreground(ui.Muted)
+
+	var s strings.Builder
+	s.WriteString(headerStyle.Render("Snooze " + describeTargets(m.snoozePick.targets)))
+	s.WriteString("\n")
+	now := time.Now()
+	for i, choice := range snoozeChoices {
+		line := fmt.Sprintf("  %d  %s (%s)", i+1, choice.label, formatSnoozeUntil(choice.until(now)))
+		if i == m.snoozePick.cursor {
+			line = selectedStyle.Render(line)
+		}
+		s.WriteString(line)
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render("↑/↓ or 1-3: choose • enter: snooze • esc: cancel"))
+	return s.String()
+}
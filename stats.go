@@ -0,0 +1,393 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"portage/durations"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// serverSession is one dev server: a process listening on a port in a project, from
+// the scan snapshots or, for older history, a port event. End is zero when it isn't
+// known how long it ran.
+type serverSession struct {
+	Port    int
+	PID     string
+	Command string
+	Project string
+	Started time.Time
+	End     time.Time
+	Running bool // still listening, so End is now
+}
+
+type portStat struct {
+	Port       int    `json:"port"`
+	Sessions   int    `json:"sessions"`
+	Projects   int    `json:"projects"`
+	TopProject string `json:"top_project"`
+}
+
+type serverStat struct {
+	Port            int    `json:"port"`
+	PID             string `json:"pid"`
+	Command         string `json:"command"`
+	Project         string `json:"project"`
+	StartedAt       string `json:"started_at"` // RFC 3339
+	LifetimeSeconds int    `json:"lifetime_seconds"`
+	Running         bool   `json:"running"`
+}
+
+type projectWeekStat struct {
+	Week     string `json:"week"` // the Monday it starts on, 2006-01-02
+	Project  string `json:"project"`
+	Sessions int    `json:"sessions"`
+	Ports    []int  `json:"ports"`
+}
+
+// lifetimeStat covers the servers whose end is known, running ones included
+type lifetimeStat struct {
+	Servers        int `json:"servers"`
+	AverageSeconds int `json:"average_seconds"`
+	MedianSeconds  int `json:"median_seconds"`
+}
+
+type portStats struct {
+	Since           string            `json:"since"` // RFC 3339
+	Sessions        int               `json:"sessions"`
+	Ports           []portStat        `json:"ports"`
+	LongestRunning  []serverStat      `json:"longest_running"`
+	BusiestProjects []projectWeekStat `json:"busiest_projects"` // one per week, newest first
+	Lifetime        lifetimeStat      `json:"lifetime"`
+}
+
+// lifetime returns how long the session ran, or false when its end isn't known
+func (s serverSession) lifetime() (time.Duration, bool) {
+	if s.End.IsZero() {
+		return 0, false
+	}
+	return s.End.Sub(s.Started), true
+}
+
+// loadServerSessions rebuilds the dev servers started since a time. A server runs from
+// the first snapshot it's in (or the start time recorded with it) to the first one it's
+// missing from; port events add the servers the snapshots don't know about, from
+// before they were kept. Listeners without a project (daemons, N/A paths) are left out.
+func loadServerSessions(since, now time.Time) ([]serverSession, error) {
+	db, err := openHistory()
+	if err != nil {
+		return nil, err
+	}
+	startedAt := "p.started_at"
+	if version, err := historyVersion(db); err != nil {
+		return nil, err
+	} else if version < 2 { // opened read-only, before scan_ports had it
+		startedAt = "0"
+	}
+	rows, err := db.Query(`SELECT s.id, s.at, p.port, p.pid, p.command, p.path, ` + startedAt + `
+		FROM scans s LEFT JOIN scan_ports p ON p.scan_id = s.id ORDER BY s.at, s.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roots := make(map[string]string)
+	project := func(path string) string {
+		root, ok := roots[path]
+		if !ok {
+			root = projectRoot(path)
+			roots[path] = root
+		}
+		return root
+	}
+
+	var sessions []*serverSession
+	open := make(map[string]*serverSession)
+	seen := make(map[string]bool)
+	// closeMissing ends the servers the scan taken at at didn't see
+	closeMissing := func(at int64) {
+		for key, session := range open {
+			if !seen[key] {
+				session.End = time.Unix(at, 0)
+				delete(open, key)
+			}
+		}
+		seen = make(map[string]bool)
+	}
+	lastScan, lastAt := int64(-1), int64(0)
+	for rows.Next() {
+		var scanID, at int64
+		var port sql.NullInt64
+		var pid, command, path sql.NullString
+		var started sql.NullInt64
+		if err := rows.Scan(&scanID, &at, &port, &pid, &command, &path, &started); err != nil {
+			return nil, err
+		}
+		if scanID != lastScan {
+			if lastScan >= 0 {
+				closeMissing(lastAt)
+			}
+			lastScan, lastAt = scanID, at
+		}
+		if !port.Valid || project(path.String) == "" {
+			continue
+		}
+		key := snapshotKey(int(port.Int64), pid.String)
+		seen[key] = true
+		if open[key] != nil {
+			continue
+		}
+		session := &serverSession{
+			Port:    int(port.Int64),
+			PID:     pid.String,
+			Command: command.String,
+			Project: project(path.String),
+			Started: time.Unix(at, 0),
+		}
+		if started.Int64 > 0 && started.Int64 < at {
+			session.Started = time.Unix(started.Int64, 0)
+		}
+		open[key] = session
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	closeMissing(lastAt)
+	// Listeners of the last snapshot ran until now if they still do; otherwise they
+	// stopped while portage wasn't looking
+	for _, session := range open {
+		if processExists(session.PID) {
+			session.End, session.Running = now, true
+		}
+	}
+
+	known := make(listenerSet)
+	for _, session := range sessions {
+		known.add(session.Port, session.PID, session.Started)
+	}
+	events, err := loadPortEvents(since)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		if known.has(event.Port, event.PID, event.Started) || project(event.Path) == "" {
+			continue
+		}
+		sessions = append(sessions, &serverSession{
+			Port:    event.Port,
+			PID:     event.PID,
+			Command: event.Command,
+			Project: project(event.Path),
+			Started: event.Started,
+		})
+	}
+
+	var result []serverSession
+	for _, session := range sessions {
+		if !session.Started.Before(since) {
+			result = append(result, *session)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Started.Before(result[j].Started) })
+	return result, nil
+}
+
+// weekStart returns midnight of the Monday of t's week
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// buildStats summarizes the sessions: the ports used most, the servers that ran the
+// longest, the busiest project of each week and how long servers live
+func buildStats(sessions []serverSession, since time.Time, limit int) portStats {
+	stats := portStats{
+		Since:           since.Format(time.RFC3339),
+		Sessions:        len(sessions),
+		Ports:           []portStat{},
+		LongestRunning:  []serverStat{},
+		BusiestProjects: []projectWeekStat{},
+	}
+
+	byPort := make(map[int]map[string]int)
+	for _, session := range sessions {
+		if byPort[session.Port] == nil {
+			byPort[session.Port] = make(map[string]int)
+		}
+		byPort[session.Port][session.Project]++
+	}
+	for port, projects := range byPort {
+		stat := portStat{Port: port, Projects: len(projects)}
+		top := 0
+		for project, n := range projects {
+			stat.Sessions += n
+			if n > top || (n == top && project < stat.TopProject) {
+				stat.TopProject, top = project, n
+			}
+		}
+		stats.Ports = append(stats.Ports, stat)
+	}
+	sort.Slice(stats.Ports, func(i, j int) bool {
+		if stats.Ports[i].Sessions != stats.Ports[j].Sessions {
+			return stats.Ports[i].Sessions > stats.Ports[j].Sessions
+		}
+		return stats.Ports[i].Port < stats.Ports[j].Port
+	})
+	if len(stats.Ports) > limit {
+		stats.Ports = stats.Ports[:limit]
+	}
+
+	var lifetimes []time.Duration
+	for _, session := range sessions {
+		lifetime, ok := session.lifetime()
+		if !ok {
+			continue
+		}
+		lifetimes = append(lifetimes, lifetime)
+		stats.LongestRunning = append(stats.LongestRunning, serverStat{
+			Port:            session.Port,
+			PID:             session.PID,
+			Command:         session.Command,
+			Project:         session.Project,
+			StartedAt:       session.Started.Format(time.RFC3339),
+			LifetimeSeconds: int(lifetime.Seconds()),
+			Running:         session.Running,
+		})
+	}
+	sort.SliceStable(stats.LongestRunning, func(i, j int) bool {
+		return stats.LongestRunning[i].LifetimeSeconds > stats.LongestRunning[j].LifetimeSeconds
+	})
+	if len(stats.LongestRunning) > limit {
+		stats.LongestRunning = stats.LongestRunning[:limit]
+	}
+	if len(lifetimes) > 0 {
+		sort.Slice(lifetimes, func(i, j int) bool { return lifetimes[i] < lifetimes[j] })
+		var total time.Duration
+		for _, lifetime := range lifetimes {
+			total += lifetime
+		}
+		median := lifetimes[len(lifetimes)/2]
+		if len(lifetimes)%2 == 0 {
+			median = (lifetimes[len(lifetimes)/2-1] + median) / 2
+		}
+		stats.Lifetime = lifetimeStat{
+			Servers:        len(lifetimes),
+			AverageSeconds: int((total / time.Duration(len(lifetimes))).Seconds()),
+			MedianSeconds:  int(median.Seconds()),
+		}
+	}
+
+	weeks := make(map[string]map[string][]serverSession)
+	for _, session := range sessions {
+		week := weekStart(session.Started).Format("2006-01-02")
+		if weeks[week] == nil {
+			weeks[week] = make(map[string][]serverSession)
+		}
+		weeks[week][session.Project] = append(weeks[week][session.Project], session)
+	}
+	for week, projects := range weeks {
+		busiest := projectWeekStat{Week: week}
+		for project, projectSessions := range projects {
+			if len(projectSessions) > busiest.Sessions || (len(projectSessions) == busiest.Sessions && project < busiest.Project) {
+				busiest.Project, busiest.Sessions = project, len(projectSessions)
+			}
+		}
+		ports := make(map[int]bool)
+		busiest.Ports = []int{}
+		for _, session := range projects[busiest.Project] {
+			if !ports[session.Port] {
+				ports[session.Port] = true
+				busiest.Ports = append(busiest.Ports, session.Port)
+			}
+		}
+		sort.Ints(busiest.Ports)
+		stats.BusiestProjects = append(stats.BusiestProjects, busiest)
+	}
+	sort.Slice(stats.BusiestProjects, func(i, j int) bool { return stats.BusiestProjects[i].Week > stats.BusiestProjects[j].Week })
+	return stats
+}
+
+// runStats implements `portage stats`
+func runStats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Output as JSON")
+	limit := fs.Int("limit", 5, "Rows in the ports and longest-running tables")
+	days := fs.Int("days", 30, "How many days of history to summarize")
+	fs.Parse(args)
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -*days)
+	sessions, err := loadServerSessions(since, now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", shortenPath(getHistoryPath()), err)
+		os.Exit(1)
+	}
+	stats := buildStats(sessions, since, *limit)
+	if *asJSON {
+		writeJSON("stats", stats)
+		return
+	}
+
+	fmt.Printf("\n%s%sPORTAGE - Stats since %s%s (%d dev servers)\n\n", ColorBold, ColorCyan, since.Format("2006-01-02"), ColorReset, stats.Sessions)
+	if stats.Sessions == 0 {
+		fmt.Printf("%sNo history yet - run portage for a while or `portage history import`%s\n\n", ColorYellow, ColorReset)
+		return
+	}
+
+	fmt.Printf("%sMost-used ports%s\n", ColorBold, ColorReset)
+	t := newTable(table.StyleDefault)
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"PORT", "SESSIONS", "PROJECTS", "TOP PROJECT"})
+	for _, port := range stats.Ports {
+		t.AppendRow(table.Row{port.Port, port.Sessions, port.Projects, shortenPath(port.TopProject)})
+	}
+	t.Render()
+
+	fmt.Printf("\n%sLongest-running servers%s\n", ColorBold, ColorReset)
+	if len(stats.LongestRunning) == 0 {
+		fmt.Printf("%sNo lifetimes yet - they come from the scans portage keeps from now on%s\n", ColorYellow, ColorReset)
+	} else {
+		t = newTable(table.StyleDefault)
+		t.SetOutputMirror(os.Stdout)
+		t.AppendHeader(table.Row{"PORT", "PID", "COMMAND", "PROJECT", "STARTED", "LIFETIME"})
+		for _, server := range stats.LongestRunning {
+			started, _ := time.Parse(time.RFC3339, server.StartedAt)
+			lifetime := durations.Uptime.Format(time.Duration(server.LifetimeSeconds) * time.Second)
+			if server.Running {
+				lifetime += " (running)"
+			}
+			t.AppendRow(table.Row{server.Port, server.PID, server.Command, shortenPath(server.Project), started.Format("2006-01-02 15:04"), lifetime})
+		}
+		t.Render()
+	}
+
+	fmt.Printf("\n%sBusiest project per week%s\n", ColorBold, ColorReset)
+	t = newTable(table.StyleDefault)
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"WEEK OF", "PROJECT", "SESSIONS", "PORTS"})
+	for _, week := range stats.BusiestProjects {
+		var ports []string
+		for _, port := range week.Ports {
+			ports = append(ports, strconv.Itoa(port))
+		}
+		t.AppendRow(table.Row{week.Week, shortenPath(week.Project), week.Sessions, strings.Join(ports, ", ")})
+	}
+	t.Render()
+
+	if stats.Lifetime.Servers > 0 {
+		fmt.Printf("\n%sDev-server lifetime:%s average %s, median %s (%d servers)\n",
+			ColorBold, ColorReset,
+			durations.Uptime.Format(time.Duration(stats.Lifetime.AverageSeconds)*time.Second),
+			durations.Uptime.Format(time.Duration(stats.Lifetime.MedianSeconds)*time.Second),
+			stats.Lifetime.Servers)
+	}
+	fmt.Println()
+}
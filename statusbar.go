@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// viewStatusBar renders the footer of the ports view: how many ports are shown out of
+// how many were found, the filters and sort deciding that, how long the last scan took,
+// and then anything pending (marks, folded daemons, snoozes)
+func (m model) viewStatusBar(visiblePorts []PortInfo, width int) string {
+	statusStyle := lipgloss.NewStyle().Foreground(ui.Muted).Width(width)
+
+	hidden := 0
+	for _, port := range m.ports {
+		if m.config.isHidden(port) {
+			hidden++
+		}
+	}
+	state := []string{fmt.Sprintf("%d of %d ports", len(visiblePorts), len(m.ports))}
+	if hidden > 0 {
+		state = append(state, fmt.Sprintf("%d hidden", hidden))
+	}
+
+	filters := []string{"dev ranges (a: all)"}
+	if m.showAll {
+		filters = []string{"all ports"}
+	}
+	if m.orphansOnly {
+		filters = append(filters, "orphans only")
+	}
+	if m.family != "" {
+		filters = append(filters, m.family+" only (F: next)")
+	}
+	if pathFilter != "" {
+		filters = append(filters, "path "+shortenPath(pathFilter))
+	}
+	if userFilter != "" {
+		filters = append(filters, "user "+userFilter)
+	}
+	if matchPattern != "" {
+		filters = append(filters, "match /"+matchPattern+"/")
+	}
+	if portFilterFlag != "" {
+		filters = append(filters, "port "+portFilterFlag)
+	}
+	state = append(state, "filter: "+strings.Join(filters, ", "))
+	state = append(state, fmt.Sprintf("sort: %s %s", m.sortBy, sortIndicator(m.sortBy)))
+
+	scan := fmt.Sprintf("scan %v", formatScanTime(m.scanTime))
+	if m.refreshing {
+		scan += ", refreshing…"
+	} else if refreshInterval > 0 {
+		scan += fmt.Sprintf(", refreshed %ds ago (every %v)", int(time.Since(m.lastRefresh).Seconds()), refreshInterval)
+	}
+	state = append(state, scan)
+
+	var pending []string
+	markedVisible := 0
+	for _, port := range visiblePorts {
+		if m.marked[fmt.Sprintf("%d-%s", port.Port, port.PID)] {
+			markedVisible++
+		}
+	}
+	if markedVisible > 0 {
+		pending = append(pending, fmt.Sprintf("%d marked (h/w/K act on all of them)", markedVisible))
+	}
+	if !m.showDaemons {
+		unfolded := m
+		unfolded.showDaemons = true
+		if summary := foldedDaemonsSummary(unfolded.getVisiblePorts()); summary != "" {
+			pending = append(pending, summary+" folded (d: show)")
+		}
+	}
+	if !m.showAll && foldsOtherPorts() {
+		unfolded := m
+		unfolded.showAll = true
+		if summary := otherPortsSummary(unfolded.getVisiblePorts()); summary != "" {
+			pending = append(pending, summary+" folded (a: show)")
+		}
+	}
+	if snoozed := len(m.config.activeSnoozes()); snoozed > 0 {
+		pending = append(pending, fmt.Sprintf("%d snoozed (u: unhide all)", snoozed))
+	}
+
+	bar := statusStyle.Render(strings.Join(state, " • "))
+	if len(pending) > 0 {
+		bar += "\n" + statusStyle.Render(strings.Join(pending, " • "))
+	}
+	return bar
+}
+
+// formatScanTime shows milliseconds below a second and tenths above
+func formatScanTime(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
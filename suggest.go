@@ -0,0 +1,173 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// suggestion is a ranked guess for a launcher: a project to open or a port to check
+type suggestion struct {
+	Project  string  `json:"project"`
+	Port     int     `json:"port,omitempty"`
+	Score    float64 `json:"score"`
+	Sessions int     `json:"sessions"` // distinct hours with activity in the lookback window
+	LastSeen string  `json:"last_seen"`
+}
+
+type suggestions struct {
+	Projects []suggestion `json:"projects"`
+	Ports    []suggestion `json:"ports"`
+}
+
+// suggestHalfLifeDays is how quickly old habits stop counting
+const suggestHalfLifeDays = 21.0
+
+// habitWeight scores one past session for "now": sessions at the same time of day and on
+// the same kind of day (weekday vs weekend) count the most, and older ones fade out
+func habitWeight(at, now time.Time) float64 {
+	hours := math.Abs(float64(at.Hour()*60+at.Minute()-now.Hour()*60-now.Minute())) / 60
+	if hours > 12 {
+		hours = 24 - hours
+	}
+	timeOfDay := math.Exp(-hours * hours / (2 * 1.5 * 1.5))
+
+	dayKind := 0.5
+	if isWeekend(at) == isWeekend(now) {
+		dayKind = 1
+	}
+
+	ageDays := now.Sub(at).Hours() / 24
+	recency := math.Pow(0.5, ageDays/suggestHalfLifeDays)
+
+	return timeOfDay*dayKind*recency + 0.05*recency // a small floor so plain recency breaks ties
+}
+
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// rankHabits scores keys by their sessions, counting each key at most once per hour so
+// a chatty source (like a watch loop) doesn't outweigh the others
+func rankHabits(samples map[string][]time.Time, now time.Time, limit int) []suggestion {
+	ranked := []suggestion{}
+	for key, times := range samples {
+		hours := make(map[string]bool)
+		var score float64
+		var last time.Time
+		for _, at := range times {
+			hour := at.Format("2006-01-02 15")
+			if hours[hour] {
+				continue
+			}
+			hours[hour] = true
+			score += habitWeight(at, now)
+			if at.After(last) {
+				last = at
+			}
+		}
+		ranked = append(ranked, suggestion{
+			Project:  key,
+			Score:    math.Round(score*1000) / 1000,
+			Sessions: len(hours),
+			LastSeen: last.Format(time.RFC3339),
+		})
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].LastSeen > ranked[j].LastSeen
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// loadPortHabits reads port discoveries from history.db, keyed by "port\tproject"
+func loadPortHabits(since time.Time) map[string][]time.Time {
+	habits := make(map[string][]time.Time)
+	events, err := loadPortEvents(since)
+	if err != nil {
+		return habits
+	}
+	for _, event := range events {
+		key := fmt.Sprintf("%d\t%s", event.Port, projectRoot(event.Path))
+		habits[key] = append(habits[key], event.Started)
+	}
+	return habits
+}
+
+// buildSuggestions ranks projects and ports for the current moment
+func buildSuggestions(now time.Time, days, limit int) suggestions {
+	since := now.AddDate(0, 0, -days)
+
+	projects := make(map[string][]time.Time)
+	for _, sample := range collectActivitySamples(since) {
+		if sample.Project == "" {
+			continue
+		}
+		projects[sample.Project] = append(projects[sample.Project], sample.Time)
+	}
+
+	ports := rankHabits(loadPortHabits(since), now, limit)
+	for i := range ports {
+		port, project, _ := strings.Cut(ports[i].Project, "\t")
+		ports[i].Port, _ = strconv.Atoi(port)
+		ports[i].Project = project
+	}
+
+	return suggestions{
+		Projects: rankHabits(projects, now, limit),
+		Ports:    ports,
+	}
+}
+
+// runSuggest implements `portage suggest`
+func runSuggest(args []string) {
+	fs := flag.NewFlagSet("suggest", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Output as JSON")
+	limit := fs.Int("limit", 5, "Number of suggestions of each kind")
+	days := fs.Int("days", 60, "How many days of history to learn from")
+	fs.Parse(args)
+
+	result := buildSuggestions(time.Now(), *days, *limit)
+	if *asJSON {
+		writeJSON("suggestions", result)
+		return
+	}
+
+	fmt.Printf("\n%s%sPORTAGE - Suggestions for %s%s\n\n", ColorBold, ColorCyan, time.Now().Format("Mon 15:04"), ColorReset)
+	if len(result.Projects) == 0 && len(result.Ports) == 0 {
+		fmt.Printf("%sNo history yet - run portage for a while or `portage history import`%s\n\n", ColorYellow, ColorReset)
+		return
+	}
+
+	t := newTable(table.StyleDefault)
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"KIND", "PORT", "PROJECT", "SCORE", "SESSIONS", "LAST SEEN"})
+	for _, kind := range []struct {
+		name  string
+		items []suggestion
+	}{{"project", result.Projects}, {"port", result.Ports}} {
+		for _, s := range kind.items {
+			port := "-"
+			if s.Port != 0 {
+				port = strconv.Itoa(s.Port)
+			}
+			lastSeen, _ := time.Parse(time.RFC3339, s.LastSeen)
+			t.AppendRow(table.Row{kind.name, port, shortenPath(s.Project), fmt.Sprintf("%.2f", s.Score), s.Sessions, lastSeen.Format("2006-01-02 15:04")})
+		}
+	}
+	t.Render()
+	fmt.Println()
+}
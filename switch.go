@@ -0,0 +1,306 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// switchItem is a workspace candidate in the `portage switch` picker
+type switchItem struct {
+	Path   string
+	Kind   string // "open", "running" or "recent"
+	Detail string
+}
+
+type switchModel struct {
+	items    []switchItem
+	matches  []int // indexes into items matching the query, best first
+	query    string
+	cursor   int
+	chosen   *switchItem
+	terminal bool // open a terminal instead of the editor
+	width    int  // terminal width from the last WindowSizeMsg (0 until known)
+}
+
+// collectSwitchItems merges open Cursor windows, projects with listening ports and
+// recent workspace history, deduplicated by path in that order of precedence
+func collectSwitchItems() []switchItem {
+	var items []switchItem
+	seen := make(map[string]bool)
+	add := func(item switchItem) {
+		if item.Path == "" || seen[item.Path] || !isUnderPathFilter(item.Path) {
+			return
+		}
+		seen[item.Path] = true
+		items = append(items, item)
+	}
+
+	var openPaths []string
+	for path := range getOpenCursorWindows() {
+		openPaths = append(openPaths, path)
+	}
+	sort.Strings(openPaths)
+	for _, path := range openPaths {
+		add(switchItem{Path: path, Kind: "open", Detail: "Cursor window"})
+	}
+
+	if ports, err := scanPorts(); err == nil {
+		projectPorts := make(map[string][]string)
+		var projects []string
+		for _, port := range ports {
+			if !isUserPort(port) {
+				continue
+			}
+			root := projectRoot(port.Path)
+			if root == "" {
+				continue
+			}
+			if _, exists := projectPorts[root]; !exists {
+				projects = append(projects, root)
+			}
+			projectPorts[root] = append(projectPorts[root], fmt.Sprintf(":%d", port.Port))
+		}
+		for _, root := range projects {
+			add(switchItem{Path: root, Kind: "running", Detail: strings.Join(projectPorts[root], " ")})
+		}
+	}
+
+	for _, entry := range collectWorkspaceHistory(50) {
+		if _, err := os.Stat(entry.Path); err != nil {
+			continue
+		}
+		add(switchItem{Path: entry.Path, Kind: "recent", Detail: entry.Type})
+	}
+
+	return items
+}
+
+// fuzzyScore matches query as a case-insensitive subsequence of text.
+// Lower scores are better; -1 means no match. Contiguous runs and matches in
+// the last path segment are preferred.
+func fuzzyScore(query, text string) int {
+	if query == "" {
+		return 0
+	}
+	query = strings.ToLower(query)
+	lower := strings.ToLower(text)
+
+	// Exact substring in the basename wins outright
+	base := strings.ToLower(filepath.Base(text))
+	if idx := strings.Index(base, query); idx >= 0 {
+		return idx
+	}
+	if idx := strings.Index(lower, query); idx >= 0 {
+		return 100 + idx
+	}
+
+	score := 200
+	pos := 0
+	last := -1
+	for _, r := range query {
+		idx := strings.IndexRune(lower[pos:], r)
+		if idx < 0 {
+			return -1
+		}
+		idx += pos
+		if last >= 0 {
+			score += idx - last - 1 // Penalize gaps between matched characters
+		}
+		last = idx
+		pos = idx + 1
+	}
+	return score
+}
+
+func (m *switchModel) refilter() {
+	type match struct {
+		index int
+		score int
+	}
+	var matches []match
+	for i, item := range m.items {
+		if score := fuzzyScore(m.query, shortenPath(item.Path)); score >= 0 {
+			matches = append(matches, match{i, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score < matches[j].score
+	})
+
+	m.matches = m.matches[:0]
+	for _, match := range matches {
+		m.matches = append(m.matches, match.index)
+	}
+	if m.cursor >= len(m.matches) {
+		m.cursor = 0
+	}
+}
+
+func (m switchModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m switchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width = size.Width
+		return m, nil
+	}
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		return m, tea.Quit
+
+	case tea.KeyUp, tea.KeyCtrlP:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case tea.KeyDown, tea.KeyCtrlN:
+		if m.cursor < len(m.matches)-1 {
+			m.cursor++
+		}
+
+	case tea.KeyEnter, tea.KeyCtrlT:
+		if len(m.matches) > 0 {
+			item := m.items[m.matches[m.cursor]]
+			m.chosen = &item
+			m.terminal = keyMsg.Type == tea.KeyCtrlT
+		}
+		return m, tea.Quit
+
+	case tea.KeyBackspace:
+		if len(m.query) > 0 {
+			m.query = m.query[:len(m.query)-1]
+			m.refilter()
+		}
+
+	case tea.KeyRunes, tea.KeySpace:
+		m.query += string(keyMsg.Runes)
+		m.refilter()
+	}
+
+	return m, nil
+}
+
+func (m switchModel) View() string {
+	promptStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Accent)
+	selectedStyle := ui.selectedStyle()
+	kindStyles := map[string]lipgloss.Style{
+		"open":    lipgloss.NewStyle().Foreground(ui.Success),
+		"running": lipgloss.NewStyle().Foreground(ui.Accent),
+		"recent":  lipgloss.NewStyle().Foreground(ui.Muted),
+	}
+	helpStyle := lipgloss.NewStyle().Foreground(ui.Muted).MarginTop(1)
+
+	var s strings.Builder
+	s.WriteString(promptStyle.Render("switch to › "))
+	s.WriteString(m.query)
+	s.WriteString("\n\n")
+
+	if len(m.matches) == 0 {
+		s.WriteString("No matching workspaces\n")
+	}
+
+	// The path gets what the kind and the longest detail leave of the window, so
+	// details stay in line
+	pathWidth := 50
+	if m.width > 0 {
+		detailWidth := 0
+		for _, idx := range m.matches {
+			detailWidth = max(detailWidth, len([]rune(m.items[idx].Detail)))
+		}
+		pathWidth = max(min(m.width-9-1-min(detailWidth, 40), 80), 20)
+	}
+
+	// Keep the list to one screen
+	maxRows := 15
+	for i, idx := range m.matches {
+		if i >= maxRows {
+			s.WriteString(fmt.Sprintf("  … %d more\n", len(m.matches)-maxRows))
+			break
+		}
+		item := m.items[idx]
+		line := fmt.Sprintf("%-8s %-*s %s", item.Kind, pathWidth, elidePath(shortenPath(item.Path), pathWidth), item.Detail)
+		if i == m.cursor {
+			line = selectedStyle.Render(line)
+		} else {
+			line = kindStyles[item.Kind].Render(fmt.Sprintf("%-8s", item.Kind)) + line[8:]
+		}
+		s.WriteString(line)
+		s.WriteString("\n")
+	}
+
+	s.WriteString(helpStyle.Render("type to filter • ↑/↓: select • enter: open in editor • ctrl+t: new terminal • esc: cancel"))
+	return s.String()
+}
+
+// runSwitch implements `portage switch`
+func runSwitch(args []string) {
+	items := collectSwitchItems()
+	if len(items) == 0 {
+		fmt.Printf("\n%s%sNo workspaces found%s\n\n", ColorBold, ColorYellow, ColorReset)
+		return
+	}
+
+	m := switchModel{items: items}
+	m.refilter()
+
+	final, err := tea.NewProgram(m).Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error in switcher: %v\n", err)
+		os.Exit(1)
+	}
+
+	result := final.(switchModel)
+	if result.chosen == nil {
+		return
+	}
+	item := result.chosen
+
+	if result.terminal {
+		if err := openTerminalAt(item.Path, loadConfig().terminalCommand()); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open terminal: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Opened terminal in %s\n", shortenPath(item.Path))
+		return
+	}
+
+	// Open windows are just raised; anything else is (re)opened in the editor
+	if item.Kind == "open" && focusCursorWindow(item.Path) == nil {
+		fmt.Printf("Focused %s\n", shortenPath(item.Path))
+		return
+	}
+
+	editor, err := startEditor(item.Path, 0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open in %s: %v\n", editor, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Opened %s in %s\n", shortenPath(item.Path), editor)
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// focusCursorWindow raises the Cursor window whose title mentions the workspace
+func focusCursorWindow(path string) error {
+	script := fmt.Sprintf(`tell application "System Events" to tell process "Cursor"
+	set frontmost to true
+	perform action "AXRaise" of (first window whose name contains %s)
+end tell`, appleScriptString(filepath.Base(path)))
+	return exec.Command("osascript", "-e", script).Run()
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// compactColumns are the only columns --compact shows, besides ones asked for with
+// --check, --grpc-health or --tabs
+var compactColumns = []string{"port", "command", "uptime"}
+
+// applyTableLayout overrides the configured columns (columns.go) for --wide, which shows
+// every column, and --compact
+func applyTableLayout(config *Config) {
+	switch {
+	case wideTable:
+		config.Columns = nil
+		for _, column := range portColumns {
+			config.Columns = append(config.Columns, column.name)
+		}
+	case compactTable:
+		config.Columns = compactColumns
+	}
+}
+
+// compactTableStyle drops the outer border and the rules between columns, so --compact
+// fits a narrow pane
+func compactTableStyle(t table.Writer) {
+	t.Style().Options.DrawBorder = false
+	t.Style().Options.SeparateColumns = false
+}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"portage/durations"
+)
+
+// Interactive mode views, switched with 1-5 or tab
+const (
+	tabPorts = iota
+	tabWorkspaces
+	tabClaude
+	tabHistory
+	tabLog
+)
+
+var tabNames = []string{"Ports", "Workspaces", "Claude", "History", "Log"}
+
+// tabRow is one line of a non-port view; Path drives the shared open/copy actions
+type tabRow struct {
+	Columns []string
+	Path    string
+
+	// Where a Log view entry is in history.db, so D and X can prune it (historylog.go)
+	historyTable string
+	historyID    int64
+
+	// The Claude view's session process, for K, and its last message, shown below the
+	// table while selected (claudemonitor.go)
+	pid    string
+	detail string
+}
+
+// tabData is what a non-port view shows, loaded in the background when it's selected
+type tabData struct {
+	Header []string
+	Rows   []tabRow
+	Empty  string // shown instead of the table when there are no rows
+}
+
+type tabLoadedMsg struct {
+	tab  int
+	data tabData
+}
+
+// loadTab collects a view's rows off the UI goroutine
+func loadTab(tab int) tea.Cmd {
+	return func() tea.Msg {
+		return tabLoadedMsg{tab: tab, data: loadTabData(tab)}
+	}
+}
+
+// loadTabData reuses the data behind --cursor, --claude and --history
+func loadTabData(tab int) tabData {
+	if tab == tabLog {
+		return loadLogTabData()
+	}
+	now := time.Now()
+	switch tab {
+	case tabWorkspaces:
+		data := tabData{Header: []string{"LAST ACTIVE", "PROJECT"}, Empty: "No Cursor workspaces found"}
+		workspaces, _, err := loadCursorWorkspaces()
+		if err != nil {
+			return data
+		}
+		// Most recently active first, unlike the --cursor table
+		for i := len(workspaces) - 1; i >= 0; i-- {
+			ws := workspaces[i]
+			data.Rows = append(data.Rows, tabRow{
+				Columns: []string{durations.Recency.Ago(now.Sub(ws.LastModified)), shortenPath(ws.Path)},
+				Path:    ws.Path,
+			})
+		}
+		return data
+
+	case tabClaude:
+		data := tabData{Header: []string{"PID", "CPU%", "MEM MB", "CPU TIME", "PROJECT"}, Empty: "No active Claude sessions"}
+		for _, session := range getClaudeSessions() {
+			if !isUnderPathFilter(session.WorkingDir) {
+				continue
+			}
+			data.Rows = append(data.Rows, tabRow{
+				Columns: []string{session.PID, session.CPUPercent, session.MemoryMB, session.CPUTime, shortenPath(session.WorkingDir)},
+				Path:    session.WorkingDir,
+				pid:     session.PID,
+				detail:  lastTranscriptMessage(latestTranscript(session.WorkingDir)),
+			})
+		}
+		return data
+
+	case tabHistory:
+		data := tabData{Header: []string{"TYPE", "NAME", "LAST ACTIVE", "PATH"}, Empty: "No workspace history found"}
+		for _, entry := range collectWorkspaceHistory(cursorHistoryLimit) {
+			lastActive := "unknown"
+			if entry.Timestamp != 0 {
+				lastActive = durations.Recency.Ago(now.Sub(time.UnixMilli(entry.Timestamp)))
+			}
+			data.Rows = append(data.Rows, tabRow{
+				Columns: []string{entry.Type, entry.Name, lastActive, shortenPath(entry.Path)},
+				Path:    entry.Path,
+			})
+		}
+		return data
+	}
+	return tabData{}
+}
+
+// tabForKey maps the view-switching keys to the view they select
+func tabForKey(key string, current int) (int, bool) {
+	switch key {
+	case "1", "2", "3", "4", "5":
+		return int(key[0] - '1'), true
+	case "tab":
+		return (current + 1) % len(tabNames), true
+	case "shift+tab":
+		return (current + len(tabNames) - 1) % len(tabNames), true
+	}
+	return 0, false
+}
+
+// switchTab remembers the cursor of the current view and (re)loads the new one
+func (m model) switchTab(tab int) (tea.Model, tea.Cmd) {
+	if tab == m.tab {
+		return m, nil
+	}
+	m.tabCursors[m.tab] = m.cursor
+	m.tab = tab
+	m.cursor = m.tabCursors[tab]
+	m.message = ""
+	m.tabQuery = ""
+	if tab == tabPorts {
+		return m, nil
+	}
+	return m, loadTab(tab)
+}
+
+// tabRows returns the rows of the current view matching the / search, if any
+func (m model) tabRows() []tabRow {
+	rows := m.tabs[m.tab].Rows
+	if m.tabQuery == "" {
+		return rows
+	}
+	query := strings.ToLower(m.tabQuery)
+	var matching []tabRow
+	for _, row := range rows {
+		text := strings.ToLower(strings.Join(row.Columns, " ") + " " + row.Path)
+		if strings.Contains(text, query) {
+			matching = append(matching, row)
+		}
+	}
+	return matching
+}
+
+// updateTabSearch handles keys while the / search input of a view is open; the rows
+// filter as you type
+func (m model) updateTabSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		m.tabSearching = false
+		m.tabQuery = ""
+
+	case tea.KeyEnter:
+		m.tabSearching = false
+
+	case tea.KeyBackspace:
+		if query := []rune(m.tabQuery); len(query) > 0 {
+			m.tabQuery = string(query[:len(query)-1])
+		}
+
+	case tea.KeyCtrlU:
+		m.tabQuery = ""
+
+	case tea.KeyRunes, tea.KeySpace:
+		m.tabQuery += string(msg.Runes)
+	}
+	m.cursor = 0
+	return m, nil
+}
+
+// updateTab handles keys in the non-port views: navigation, search and the actions that
+// only need a path (editor, Finder, terminal, copy, launch)
+func (m model) updateTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	rows := m.tabRows()
+	switch msg.String() {
+	case "/":
+		m.tabSearching = true
+
+	case "esc":
+		m.tabQuery = ""
+		m.cursor = 0
+
+	case "D":
+		if m.tab == tabLog {
+			return m.deleteLogEntry()
+		}
+
+	case "X":
+		if m.tab == tabLog {
+			return m.pruneMissingLogEntries()
+		}
+
+	case "K":
+		switch {
+		case m.tab == tabClaude:
+			return m.killClaudeSession()
+		case m.tab == tabWorkspaces && m.cursor < len(rows):
+			return m.killProjectDir(rows[m.cursor].Path)
+		}
+
+	case "o":
+		if m.tab == tabWorkspaces && m.cursor < len(rows) {
+			m.message = openInCursor(rows[m.cursor].Path)
+		}
+
+	case "m":
+		if m.tab == tabWorkspaces && m.cursor < len(rows) {
+			m.message = markWorkspaceClosed(rows[m.cursor].Path)
+		}
+
+	case "J":
+		if m.tab == tabClaude {
+			return m.jumpToProject()
+		}
+
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down", "j":
+		if m.cursor < len(rows)-1 {
+			m.cursor++
+		}
+
+	case "enter", "e":
+		if m.cursor < len(rows) {
+			m.message = openInEditor(rows[m.cursor].Path, 0)
+		}
+
+	case "f":
+		if m.cursor < len(rows) {
+			m.message = openInFinder(rows[m.cursor].Path)
+		}
+
+	case "x":
+		if m.cursor < len(rows) && rows[m.cursor].Path != "" {
+			return m.launchProject(rows[m.cursor].Path)
+		}
+
+	case "t":
+		if m.cursor < len(rows) {
+			m.message = openInTerminal(rows[m.cursor].Path, m.config.terminalCommand())
+		}
+
+	case "C":
+		if m.cursor < len(rows) && rows[m.cursor].Path != "" {
+			if err := copyToClipboard(rows[m.cursor].Path); err != nil {
+				m.message = fmt.Sprintf("Failed to copy: %v", err)
+			} else {
+				m.message = fmt.Sprintf("Copied %s", shortenPath(rows[m.cursor].Path))
+			}
+		}
+	}
+	return m, nil
+}
+
+// viewTabBar renders "1 Ports  2 Workspaces  3 Claude  4 History  5 Log" with the current view highlighted
+func (m model) viewTabBar() string {
+	activeStyle := lipgloss.NewStyle().Bold(true).Reverse(true)
+	inactiveStyle := lipgloss.NewStyle().Foreground(ui.Muted)
+
+	var tabs []string
+	for i, name := range tabNames {
+		label := fmt.Sprintf(" %d %s ", i+1, name)
+		if i == m.tab {
+			tabs = append(tabs, activeStyle.Render(label))
+		} else {
+			tabs = append(tabs, inactiveStyle.Render(label))
+		}
+	}
+	return strings.Join(tabs, " ")
+}
+
+// viewTab renders a non-port view as a table sized to its contents
+func (m model) viewTab(width int) string {
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Accent)
+	selectedStyle := ui.selectedStyle()
+	messageStyle := lipgloss.NewStyle().Foreground(ui.Warning).MarginTop(1)
+	searchStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Accent)
+	helpStyle := lipgloss.NewStyle().Foreground(ui.Muted).MarginTop(1)
+	detailStyle := lipgloss.NewStyle().Foreground(ui.Muted)
+
+	var s strings.Builder
+	data, loaded := m.tabs[m.tab]
+	rows := m.tabRows()
+	switch {
+	case !loaded:
+		s.WriteString("Loading…\n")
+	case len(data.Rows) == 0:
+		s.WriteString(data.Empty + "\n")
+	case len(rows) == 0:
+		s.WriteString(fmt.Sprintf("Nothing matches \"%s\"\n", m.tabQuery))
+	default:
+		// Every column but the last is as wide as its longest cell; the last gets the rest
+		widths := make([]int, len(data.Header))
+		for i, title := range data.Header {
+			widths[i] = len(title)
+		}
+		for _, row := range rows {
+			for i, cell := range row.Columns {
+				if w := len([]rune(cell)); w > widths[i] {
+					widths[i] = min(w, 30)
+				}
+			}
+		}
+		used := 0
+		for _, w := range widths[:len(widths)-1] {
+			used += w + 1
+		}
+		widths[len(widths)-1] = max(width-used-2, 20)
+
+		// The last column is a path in every view; it loses its middle rather than its end
+		format := func(columns []string) string {
+			cells := make([]string, len(columns))
+			for i, cell := range columns {
+				if i == len(columns)-1 {
+					cells[i] = elidePath(cell, widths[i])
+					continue
+				}
+				cells[i] = fmt.Sprintf("%-*s", widths[i], truncate(cell, widths[i]))
+			}
+			return strings.TrimRight(strings.Join(cells, " "), " ")
+		}
+
+		s.WriteString(headerStyle.Render(format(data.Header)))
+		s.WriteString("\n")
+		s.WriteString(strings.Repeat("─", min(used+widths[len(widths)-1], width)))
+		s.WriteString("\n")
+
+		start, end := m.visibleRange(len(rows))
+		if start > 0 {
+			s.WriteString(helpStyle.UnsetMarginTop().Render(fmt.Sprintf("  ↑ %d more", start)))
+			s.WriteString("\n")
+		}
+		for i := start; i < end; i++ {
+			line := format(rows[i].Columns)
+			if i == m.cursor {
+				line = selectedStyle.Render(line)
+			}
+			s.WriteString(line)
+			s.WriteString("\n")
+		}
+		if end < len(rows) {
+			s.WriteString(helpStyle.UnsetMarginTop().Render(fmt.Sprintf("  ↓ %d more", len(rows)-end)))
+			s.WriteString("\n")
+		}
+		if m.cursor < len(rows) && rows[m.cursor].detail != "" {
+			s.WriteString("\n")
+			s.WriteString(detailStyle.Render(truncate(rows[m.cursor].detail, width)))
+			s.WriteString("\n")
+		}
+	}
+
+	if m.message != "" {
+		s.WriteString("\n")
+		s.WriteString(messageStyle.Render(m.message))
+		s.WriteString("\n")
+	}
+
+	// Help, or the search input or a confirmation in its place
+	s.WriteString("\n")
+	switch {
+	case m.tabSearching:
+		s.WriteString(searchStyle.Render("/") + m.tabQuery + "█\n")
+		s.WriteString(helpStyle.UnsetMarginTop().Render("enter: keep filter • esc: clear"))
+	case m.confirm != nil:
+		s.WriteString(m.viewConfirm())
+	default:
+		if m.tabQuery != "" {
+			s.WriteString(searchStyle.Render(fmt.Sprintf("filter: %s (%d of %d, esc: clear)", m.tabQuery, len(rows), len(data.Rows))))
+			s.WriteString("\n")
+		}
+		help := "1-5/tab: switch view • /: search • enter/e: editor • f: Finder • t: terminal • x: launch • C: copy path • ?: all keys • q: quit"
+		switch m.tab {
+		case tabWorkspaces:
+			help = "1-5/tab: switch view • /: search • enter/e: editor • o: Cursor • f: Finder • m: mark closed • K: kill its ports • t: terminal • x: launch • ?: all keys • q: quit"
+		case tabClaude:
+			help = fmt.Sprintf("1-5/tab: switch view • /: search • enter/e: editor • J: jump to its ports • K: kill session • t: terminal • C: copy path • ?: all keys • q: quit • updates every %ds", int(claudeRefreshInterval.Seconds()))
+		case tabLog:
+			help = "1-5/tab: switch view • /: search • enter/e: editor • D: delete entry • X: prune missing directories • f: Finder • t: terminal • ?: all keys • q: quit"
+		}
+		s.WriteString(helpStyle.Width(width).Render(help))
+	}
+	return s.String()
+}
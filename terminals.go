@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+)
+
+// listTmuxPanes maps each tmux pane's tty (e.g. /dev/ttys004) to "tmux session:window.pane (window name)"
+func listTmuxPanes() map[string]string {
+	output, err := commandOutput("tmux", "list-panes", "-a", "-F",
+		"#{pane_tty}\t#{session_name}:#{window_index}.#{pane_index}\t#{window_name}")
+	if err != nil {
+		return nil // No tmux server running
+	}
+
+	panes := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) < 3 {
+			continue
+		}
+		panes[parts[0]] = "tmux " + parts[1] + " (" + parts[2] + ")"
+	}
+	return panes
+}
+
+// listITermSessions maps each iTerm2 session's tty to "iTerm: session name", without launching iTerm
+func listITermSessions() map[string]string {
+	script := `if application "iTerm2" is running then
+	tell application "iTerm2"
+		set out to ""
+		repeat with w in windows
+			repeat with t in tabs of w
+				repeat with s in sessions of t
+					set out to out & (tty of s) & tab & (name of s) & linefeed
+				end repeat
+			end repeat
+		end repeat
+		return out
+	end tell
+end if`
+	output, err := commandOutput("osascript", "-e", script)
+	if err != nil {
+		return nil
+	}
+
+	sessions := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		tty, name, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		sessions[tty] = "iTerm: " + strings.TrimSpace(name)
+	}
+	return sessions
+}
+
+// processTTYs returns the controlling terminal of each PID, omitting daemons without one
+func processTTYs(pids []string) map[string]string {
+	ttys := make(map[string]string)
+	if len(pids) == 0 {
+		return ttys
+	}
+
+	output, err := commandOutput("ps", "-o", "pid=,tty=", "-p", strings.Join(pids, ","))
+	if err != nil && len(output) == 0 {
+		return ttys
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[1] == "??" || fields[1] == "?" {
+			continue
+		}
+		ttys[fields[0]] = "/dev/" + fields[1]
+	}
+	return ttys
+}
+
+// resolvePortTerminals fills in the Terminal field with the tmux pane, iTerm session
+// or bare tty that each listening process was started from
+func resolvePortTerminals(ports []PortInfo) {
+	seen := make(map[string]bool)
+	var pids []string
+	for _, port := range ports {
+		if !seen[port.PID] {
+			seen[port.PID] = true
+			pids = append(pids, port.PID)
+		}
+	}
+
+	ttys := processTTYs(pids)
+	if len(ttys) == 0 {
+		return
+	}
+	tmuxPanes := listTmuxPanes()
+	itermSessions := listITermSessions()
+
+	for i := range ports {
+		tty, ok := ttys[ports[i].PID]
+		if !ok {
+			continue
+		}
+		switch {
+		case tmuxPanes[tty] != "":
+			ports[i].Terminal = tmuxPanes[tty]
+		case itermSessions[tty] != "":
+			ports[i].Terminal = itermSessions[tty]
+		default:
+			ports[i].Terminal = strings.TrimPrefix(tty, "/dev/")
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// ThemeConfig is the [theme] section of config.toml (settings.go), or "theme" in
+// portage.json. Name picks a built-in theme; the other fields override its colors
+// (ANSI numbers like "33" or hex like "#268bd2").
+type ThemeConfig struct {
+	Name       string `json:"name,omitempty" toml:"name"`               // dark (default), light, solarized or monochrome
+	Accent     string `json:"accent,omitempty" toml:"accent"`           // titles and column headers
+	Muted      string `json:"muted,omitempty" toml:"muted"`             // help lines and secondary text
+	Warning    string `json:"warning,omitempty" toml:"warning"`         // messages and prompts
+	Success    string `json:"success,omitempty" toml:"success"`         // open workspaces in the switcher
+	Danger     string `json:"danger,omitempty" toml:"danger"`           // failing --check results
+	SelectedBg string `json:"selected_bg,omitempty" toml:"selected_bg"` // selected row
+	SelectedFg string `json:"selected_fg,omitempty" toml:"selected_fg"`
+	Borders    string `json:"borders,omitempty" toml:"borders"` // table borders: plain, rounded, light, double or bold
+}
+
+// palette is the resolved theme used by every view
+type palette struct {
+	Accent, Muted, Warning, Success, Danger lipgloss.TerminalColor
+	SelectedBg, SelectedFg                  lipgloss.TerminalColor
+	reverseSelected                         bool         // monochrome: reverse video instead of colors
+	tableStyle                              *table.Style // nil keeps each table's own style
+	plainOutput                             bool         // monochrome: no ANSI colors in CLI output either
+}
+
+var builtinThemes = map[string]palette{
+	"dark": {
+		Accent: lipgloss.Color("6"), Muted: lipgloss.Color("244"), Warning: lipgloss.Color("3"), Success: lipgloss.Color("2"), Danger: lipgloss.Color("1"),
+		SelectedBg: lipgloss.Color("240"), SelectedFg: lipgloss.Color("15"),
+	},
+	"light": {
+		Accent: lipgloss.Color("25"), Muted: lipgloss.Color("242"), Warning: lipgloss.Color("130"), Success: lipgloss.Color("28"), Danger: lipgloss.Color("124"),
+		SelectedBg: lipgloss.Color("153"), SelectedFg: lipgloss.Color("0"),
+	},
+	"solarized": {
+		Accent: lipgloss.Color("#268bd2"), Muted: lipgloss.Color("#839496"), Warning: lipgloss.Color("#b58900"), Success: lipgloss.Color("#859900"), Danger: lipgloss.Color("#dc322f"),
+		SelectedBg: lipgloss.Color("#073642"), SelectedFg: lipgloss.Color("#eee8d5"),
+	},
+	"monochrome": {
+		Accent: lipgloss.NoColor{}, Muted: lipgloss.NoColor{}, Warning: lipgloss.NoColor{}, Success: lipgloss.NoColor{}, Danger: lipgloss.NoColor{},
+		SelectedBg: lipgloss.NoColor{}, SelectedFg: lipgloss.NoColor{},
+		reverseSelected: true, plainOutput: true,
+	},
+}
+
+var tableBorderStyles = map[string]table.Style{
+	"plain":   table.StyleDefault,
+	"rounded": table.StyleRounded,
+	"light":   table.StyleLight,
+	"double":  table.StyleDouble,
+	"bold":    table.StyleBold,
+}
+
+// ui is the active theme
+var ui = builtinThemes["dark"]
+
+// applyTheme makes the configured theme the active one; name (from --theme) wins over
+// the config's
+func applyTheme(config *ThemeConfig, name string) error {
+	theme, err := resolveTheme(config, name)
+	if err != nil {
+		return err
+	}
+	ui = theme
+	if ui.plainOutput {
+		ColorReset, ColorRed, ColorGreen, ColorYellow, ColorBlue = "", "", "", "", ""
+		ColorPurple, ColorCyan, ColorWhite, ColorBold = "", "", "", ""
+	}
+	return nil
+}
+
+// resolveTheme builds the palette of a theme config without applying it
+func resolveTheme(config *ThemeConfig, name string) (palette, error) {
+	if config == nil {
+		config = &ThemeConfig{}
+	}
+	if name == "" {
+		name = config.Name
+	}
+	if name == "" {
+		name = "dark"
+	}
+
+	theme, ok := builtinThemes[name]
+	if !ok {
+		return palette{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(themeNames(), ", "))
+	}
+	for _, override := range []struct {
+		value string
+		color *lipgloss.TerminalColor
+	}{
+		{config.Accent, &theme.Accent},
+		{config.Muted, &theme.Muted},
+		{config.Warning, &theme.Warning},
+		{config.Success, &theme.Success},
+		{config.Danger, &theme.Danger},
+		{config.SelectedBg, &theme.SelectedBg},
+		{config.SelectedFg, &theme.SelectedFg},
+	} {
+		if override.value != "" {
+			*override.color = lipgloss.Color(override.value)
+		}
+	}
+	if config.SelectedBg != "" || config.SelectedFg != "" {
+		theme.reverseSelected = false
+	}
+
+	if config.Borders != "" {
+		style, ok := tableBorderStyles[config.Borders]
+		if !ok {
+			return palette{}, fmt.Errorf("unknown table borders %q (use plain, rounded, light, double or bold)", config.Borders)
+		}
+		theme.tableStyle = &style
+	}
+	return theme, nil
+}
+
+func themeNames() []string {
+	var names []string
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectedStyle highlights the row under the cursor
+func (p palette) selectedStyle() lipgloss.Style {
+	if p.reverseSelected {
+		return lipgloss.NewStyle().Reverse(true)
+	}
+	return lipgloss.NewStyle().Background(p.SelectedBg).Foreground(p.SelectedFg)
+}
+
+// newTable creates a table writer with the theme's borders, or the given style when the
+// theme doesn't set any
+func newTable(fallback table.Style) table.Writer {
+	t := table.NewWriter()
+	if ui.tableStyle != nil {
+		t.SetStyle(*ui.tableStyle)
+	} else {
+		t.SetStyle(fallback)
+	}
+	return t
+}
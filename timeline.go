@@ -0,0 +1,143 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"portage/durations"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// timelineEvent is one moment in a project's life, from any of the history sources
+type timelineEvent struct {
+	At     string `json:"at"`     // RFC 3339
+	Source string `json:"source"` // port, cursor or claude
+	Event  string `json:"event"`  // up, down, open, close or session
+	Detail string `json:"detail"`
+	Path   string `json:"path"` // the project or a directory inside it
+	time   time.Time
+}
+
+// buildTimeline merges what history.db and Claude's history know about a project
+// since a time, oldest first: ports coming up and going down, the editor opening and
+// closing it, and Claude sessions
+func buildTimeline(project string, since, now time.Time) []timelineEvent {
+	var events []timelineEvent
+	add := func(at time.Time, source, event, detail, path string) {
+		if at.Before(since) || !underProject(path, project) {
+			return
+		}
+		events = append(events, timelineEvent{At: at.Format(time.RFC3339), Source: source, Event: event, Detail: detail, Path: path, time: at})
+	}
+
+	// A listener comes up once however many sources know about it
+	up := make(listenerSet)
+	portUp := func(at time.Time, port int, pid, command, path, note string) {
+		if up.has(port, pid, at) {
+			return
+		}
+		up.add(port, pid, at)
+		add(at, "port", "up", fmt.Sprintf("%s on :%d%s", command, port, note), path)
+	}
+	history, _ := queryEvents(eventQuery{Since: since, Under: project})
+	for _, event := range history {
+		if c := event.Close; c != nil {
+			portUp(c.Started, c.Port, c.PID, c.Command, c.Path, "")
+			add(c.Closed, "port", "down", fmt.Sprintf("%s on :%d stopped after %s", c.Command, c.Port, durations.Uptime.Format(c.lifetime())), c.Path)
+		}
+	}
+	if db, err := openHistory(); err == nil {
+		listeners, _ := lastSnapshot(db)
+		for _, listener := range listeners {
+			if processExists(listener.PID) {
+				portUp(listener.Started, listener.Port, listener.PID, listener.Command, listener.Path, ", still running")
+			}
+		}
+	}
+	for _, event := range history {
+		if p := event.Port; p != nil {
+			portUp(p.Started, p.Port, p.PID, p.Command, p.Path, "")
+		}
+	}
+
+	for _, event := range history {
+		switch {
+		case event.Workspace != nil:
+			add(event.At, "cursor", event.Workspace.Event, "workspace "+event.Workspace.Event, event.Path)
+		case event.Session != nil:
+			session := event.Session
+			first, last := time.UnixMilli(session.FirstTimestamp), time.UnixMilli(session.LastTimestamp)
+			prompts := "1 prompt"
+			if session.MessageCount > 1 {
+				prompts = fmt.Sprintf("%d prompts over %s", session.MessageCount, durations.Uptime.Format(last.Sub(first)))
+			}
+			add(first, "claude", "session", fmt.Sprintf("%s: %s", prompts, session.FirstMessage), session.Project)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].time.Before(events[j].time) })
+	if events == nil {
+		events = []timelineEvent{}
+	}
+	return events
+}
+
+// runTimeline implements `portage timeline [path]`
+func runTimeline(args []string) {
+	fs := flag.NewFlagSet("timeline", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Output as JSON")
+	sinceFlag := fs.String("since", "", "Only events since: a duration ago (2h, 7d), a day (yesterday, tuesday) or a date")
+	fs.Parse(args)
+	// The path can come before the flags too
+	path := "."
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+		fs.Parse(fs.Args()[1:])
+	}
+	project := resolvePathFilter(path)
+
+	now := time.Now()
+	var since time.Time
+	if *sinceFlag != "" {
+		var err error
+		if since, err = parseHistoryTime(*sinceFlag, now); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --since: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	events := buildTimeline(project, since, now)
+	if *asJSON {
+		writeJSON("timeline", events)
+		return
+	}
+
+	fmt.Printf("\n%s%sPORTAGE - Timeline of %s%s\n\n", ColorBold, ColorCyan, shortenPath(project), ColorReset)
+	if len(events) == 0 {
+		fmt.Printf("%sNothing recorded for %s yet%s\n\n", ColorYellow, shortenPath(project), ColorReset)
+		return
+	}
+
+	t := newTable(table.StyleRounded)
+	t.AppendHeader(table.Row{"WHEN", "SOURCE", "EVENT", "DETAIL", "WHERE"})
+	day := ""
+	for _, event := range events {
+		when := event.time.Format("15:04")
+		if d := event.time.Format("Mon 2006-01-02"); d != day {
+			day = d
+			when = d + " " + when
+		}
+		where := "."
+		if event.Path != project {
+			where = strings.TrimPrefix(event.Path, strings.TrimSuffix(project, "/")+"/")
+		}
+		t.AppendRow(table.Row{when, event.Source, event.Event, truncate(event.Detail, 60), where})
+	}
+	fmt.Println(t.Render())
+	fmt.Printf("\n%s%sTotal: %d events%s\n\n", ColorBold, ColorCyan, len(events), ColorReset)
+}
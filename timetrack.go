@@ -0,0 +1,273 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"portage/durations"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// activeSpan is a stretch of time something was going on in a project
+type activeSpan struct {
+	From time.Time
+	To   time.Time
+}
+
+// projectTime is the approximate time spent on a project: while it was open in the
+// editor or one of its dev servers ran, each moment counted once
+type projectTime struct {
+	Project       string `json:"project"`
+	ActiveSeconds int    `json:"active_seconds"`
+	EditorSeconds int    `json:"editor_seconds"`
+	ServerSeconds int    `json:"server_seconds"` // servers overlapping each other count once
+	Days          int    `json:"days"`           // days with any active time
+	LastActive    string `json:"last_active"`    // RFC 3339
+}
+
+// projectDay is one day of a project's time, for --project
+type projectDay struct {
+	Day           string `json:"day"` // 2006-01-02
+	ActiveSeconds int    `json:"active_seconds"`
+	EditorSeconds int    `json:"editor_seconds"`
+	ServerSeconds int    `json:"server_seconds"`
+}
+
+// activityProject returns the project a recorded path belongs to: its repository or
+// marker root, else the path itself
+func activityProject(path string) string {
+	if root := projectRoot(path); root != "" {
+		return root
+	}
+	return path
+}
+
+// workspaceSpans returns when each workspace was open in the editor within [since, now],
+// by project: from each open event to the next close of the same workspace, and until
+// now for one that is still open
+func workspaceSpans(since, now time.Time) map[string][]activeSpan {
+	spans := make(map[string][]activeSpan)
+	add := func(path string, from, to time.Time) {
+		if d := overlap(from, to, since, now); d > 0 {
+			project := activityProject(path)
+			spans[project] = append(spans[project], activeSpan{From: maxTime(from, since), To: minTime(to, now)})
+		}
+	}
+	events, _ := queryEvents(eventQuery{Types: []string{EventWorkspaceOpen, EventWorkspaceClose}})
+	opened := make(map[string]time.Time)
+	for _, event := range events {
+		if event.Type == EventWorkspaceOpen {
+			if _, ok := opened[event.Path]; !ok {
+				opened[event.Path] = event.At
+			}
+			continue
+		}
+		if at, ok := opened[event.Path]; ok {
+			add(event.Path, at, event.At)
+			delete(opened, event.Path)
+		}
+	}
+	openWindows := getOpenCursorWindows()
+	for path, at := range opened {
+		if openWindows[path] {
+			add(path, at, now)
+		}
+	}
+	return spans
+}
+
+// serverSpans returns when dev servers ran within [since, now], by project. Servers
+// whose end isn't known are left out.
+func serverSpans(since, now time.Time) map[string][]activeSpan {
+	spans := make(map[string][]activeSpan)
+	sessions, _ := loadServerSessions(time.Time{}, now) // the ones started before since may run into it
+	for _, session := range sessions {
+		if session.End.IsZero() || overlap(session.Started, session.End, since, now) == 0 {
+			continue
+		}
+		spans[session.Project] = append(spans[session.Project], activeSpan{From: maxTime(session.Started, since), To: minTime(session.End, now)})
+	}
+	return spans
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// mergeSpans returns the union of spans as separate spans, oldest first
+func mergeSpans(spans []activeSpan) []activeSpan {
+	sorted := append([]activeSpan(nil), spans...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].From.Before(sorted[j].From) })
+	var merged []activeSpan
+	for _, span := range sorted {
+		if n := len(merged); n > 0 && !span.From.After(merged[n-1].To) {
+			merged[n-1].To = maxTime(merged[n-1].To, span.To)
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
+
+// spansSeconds adds up the union of spans
+func spansSeconds(spans []activeSpan) int {
+	var total time.Duration
+	for _, span := range mergeSpans(spans) {
+		total += span.To.Sub(span.From)
+	}
+	return int(total.Seconds())
+}
+
+// byDay splits spans at midnight into the days they fall on
+func byDay(spans []activeSpan) map[string][]activeSpan {
+	days := make(map[string][]activeSpan)
+	for _, span := range spans {
+		for from := span.From; from.Before(span.To); {
+			midnight := time.Date(from.Year(), from.Month(), from.Day()+1, 0, 0, 0, 0, from.Location())
+			to := minTime(midnight, span.To)
+			day := from.Format("2006-01-02")
+			days[day] = append(days[day], activeSpan{From: from, To: to})
+			from = to
+		}
+	}
+	return days
+}
+
+// buildProjectTimes adds up the time spent on each project since a time, most first
+func buildProjectTimes(since, now time.Time) []projectTime {
+	editor, servers := workspaceSpans(since, now), serverSpans(since, now)
+	projects := make(map[string]bool)
+	for p := range editor {
+		projects[p] = true
+	}
+	for p := range servers {
+		projects[p] = true
+	}
+
+	times := []projectTime{}
+	for p := range projects {
+		all := append(append([]activeSpan(nil), editor[p]...), servers[p]...)
+		merged := mergeSpans(all)
+		times = append(times, projectTime{
+			Project:       p,
+			ActiveSeconds: spansSeconds(all),
+			EditorSeconds: spansSeconds(editor[p]),
+			ServerSeconds: spansSeconds(servers[p]),
+			Days:          len(byDay(merged)),
+			LastActive:    merged[len(merged)-1].To.Format(time.RFC3339),
+		})
+	}
+	sort.Slice(times, func(i, j int) bool {
+		if times[i].ActiveSeconds != times[j].ActiveSeconds {
+			return times[i].ActiveSeconds > times[j].ActiveSeconds
+		}
+		return times[i].Project < times[j].Project
+	})
+	return times
+}
+
+// buildProjectDays splits the time spent on a project (and the projects inside it) by
+// day, newest first
+func buildProjectDays(project string, since, now time.Time) []projectDay {
+	var editor, servers []activeSpan
+	for p, spans := range workspaceSpans(since, now) {
+		if underProject(p, project) {
+			editor = append(editor, spans...)
+		}
+	}
+	for p, spans := range serverSpans(since, now) {
+		if underProject(p, project) {
+			servers = append(servers, spans...)
+		}
+	}
+	editorDays, serverDays := byDay(editor), byDay(servers)
+	allDays := byDay(append(append([]activeSpan(nil), editor...), servers...))
+
+	days := []projectDay{}
+	for day, spans := range allDays {
+		days = append(days, projectDay{
+			Day:           day,
+			ActiveSeconds: spansSeconds(spans),
+			EditorSeconds: spansSeconds(editorDays[day]),
+			ServerSeconds: spansSeconds(serverDays[day]),
+		})
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Day > days[j].Day })
+	return days
+}
+
+// runTime implements `portage time`
+func runTime(args []string) {
+	fs := flag.NewFlagSet("time", flag.ExitOnError)
+	projectFlag := fs.String("project", "", "Only this project (and the ones inside it), split by day")
+	sinceFlag := fs.String("since", "30d", "Count since: a duration ago (2h, 7d), a day (yesterday, monday) or a date")
+	asJSON := fs.Bool("json", false, "Output as JSON")
+	fs.Parse(args)
+
+	now := time.Now()
+	since, err := parseHistoryTime(*sinceFlag, now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --since: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *projectFlag != "" {
+		project := resolvePathFilter(*projectFlag)
+		days := buildProjectDays(project, since, now)
+		if *asJSON {
+			writeJSON("project_time_days", days)
+			return
+		}
+		fmt.Printf("\n%s%sPORTAGE - Time on %s since %s%s\n\n", ColorBold, ColorCyan, shortenPath(project), since.Format("2006-01-02"), ColorReset)
+		if len(days) == 0 {
+			fmt.Printf("%sNo time recorded for %s%s\n\n", ColorYellow, shortenPath(project), ColorReset)
+			return
+		}
+		t := newTable(table.StyleRounded)
+		t.AppendHeader(table.Row{"DAY", "ACTIVE", "EDITOR", "SERVERS"})
+		total := 0
+		for _, day := range days {
+			date, _ := time.ParseInLocation("2006-01-02", day.Day, time.Local)
+			t.AppendRow(table.Row{date.Format("Mon 2006-01-02"), totalDuration(day.ActiveSeconds), totalDuration(day.EditorSeconds), totalDuration(day.ServerSeconds)})
+			total += day.ActiveSeconds
+		}
+		fmt.Println(t.Render())
+		fmt.Printf("\n%s%sTotal: %s over %d days%s\n\n", ColorBold, ColorCyan, totalDuration(total), len(days), ColorReset)
+		return
+	}
+
+	times := buildProjectTimes(since, now)
+	if *asJSON {
+		writeJSON("project_time", times)
+		return
+	}
+	fmt.Printf("\n%s%sPORTAGE - Time per project since %s%s\n\n", ColorBold, ColorCyan, since.Format("2006-01-02"), ColorReset)
+	if len(times) == 0 {
+		fmt.Printf("%sNo time recorded yet - it comes from workspace events and the scans portage keeps%s\n\n", ColorYellow, ColorReset)
+		return
+	}
+	t := newTable(table.StyleRounded)
+	t.AppendHeader(table.Row{"PROJECT", "ACTIVE", "EDITOR", "SERVERS", "DAYS", "LAST ACTIVE"})
+	total := 0
+	for _, p := range times {
+		lastActive, _ := time.Parse(time.RFC3339, p.LastActive)
+		t.AppendRow(table.Row{shortenPath(p.Project), totalDuration(p.ActiveSeconds), totalDuration(p.EditorSeconds), totalDuration(p.ServerSeconds), p.Days, durations.Recency.Ago(now.Sub(lastActive))})
+		total += p.ActiveSeconds
+	}
+	fmt.Println(t.Render())
+	fmt.Printf("\n%s%sTotal: %s over %d projects%s\n\n", ColorBold, ColorCyan, totalDuration(total), len(times), ColorReset)
+}
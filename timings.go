@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// stageTiming is how long one provider or enrichment stage took. Stages that run once per
+// port range (terminals, health checks, ...) add up into one entry.
+type stageTiming struct {
+	Stage        string        `json:"stage"`
+	Duration     time.Duration `json:"-"`
+	Milliseconds float64       `json:"ms"`
+	Detail       string        `json:"detail,omitempty"`
+}
+
+// timingReport collects stage timings for --timings and --debug
+type timingReport struct {
+	start   time.Time
+	total   time.Duration // set by finish
+	stages  []stageTiming
+	slowest []processTiming // per-process enrichment, slowest first
+}
+
+type processTiming struct {
+	PID          string  `json:"pid"`
+	Command      string  `json:"command"`
+	Milliseconds float64 `json:"ms"`
+}
+
+// timingsJSON is the "timings" member of the JSON envelope
+type timingsJSON struct {
+	TotalMilliseconds float64         `json:"total_ms"`
+	Stages            []stageTiming   `json:"stages"`
+	SlowestProcesses  []processTiming `json:"slowest_processes,omitempty"`
+}
+
+// timings is nil unless --timings or --debug is given; every method is a no-op on nil
+var timings *timingReport
+
+func newTimingReport() *timingReport {
+	return &timingReport{start: time.Now()}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// record adds the time since start to stage; a non-empty detail replaces the previous one
+func (r *timingReport) record(stage string, start time.Time, detail string) {
+	if r == nil {
+		return
+	}
+	elapsed := time.Since(start)
+	for i := range r.stages {
+		if r.stages[i].Stage == stage {
+			r.stages[i].Duration += elapsed
+			r.stages[i].Milliseconds = milliseconds(r.stages[i].Duration)
+			if detail != "" {
+				r.stages[i].Detail = detail
+			}
+			return
+		}
+	}
+	r.stages = append(r.stages, stageTiming{Stage: stage, Duration: elapsed, Milliseconds: milliseconds(elapsed), Detail: detail})
+}
+
+// recordProcess notes how long enriching one process took
+func (r *timingReport) recordProcess(port PortInfo, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.slowest = append(r.slowest, processTiming{PID: port.PID, Command: port.Command, Milliseconds: milliseconds(d)})
+	sort.Slice(r.slowest, func(i, j int) bool { return r.slowest[i].Milliseconds > r.slowest[j].Milliseconds })
+	if len(r.slowest) > 5 {
+		r.slowest = r.slowest[:5]
+	}
+}
+
+// finish stops the total clock, e.g. before interactive mode takes over
+func (r *timingReport) finish() {
+	if r != nil && r.total == 0 {
+		r.total = time.Since(r.start)
+	}
+}
+
+// json returns the report for the JSON envelope
+func (r *timingReport) json() *timingsJSON {
+	if r == nil {
+		return nil
+	}
+	r.finish()
+	return &timingsJSON{
+		TotalMilliseconds: milliseconds(r.total),
+		Stages:            r.stages,
+		SlowestProcesses:  r.slowest,
+	}
+}
+
+// print renders the report as a compact table; with debug, the slowest processes follow
+func (r *timingReport) print(debug bool) {
+	if r == nil {
+		return
+	}
+	r.finish()
+	total := r.total
+
+	t := newTable(table.StyleDefault)
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"STAGE", "TIME", "SHARE", "DETAIL"})
+	for _, stage := range r.stages {
+		share := 0.0
+		if total > 0 {
+			share = float64(stage.Duration) / float64(total) * 100
+		}
+		t.AppendRow(table.Row{stage.Stage, formatStageDuration(stage.Duration), fmt.Sprintf("%.0f%%", share), stage.Detail})
+	}
+	t.AppendSeparator()
+	t.AppendRow(table.Row{"total", formatStageDuration(total), "", ""})
+	fmt.Println()
+	t.Render()
+
+	if debug && len(r.slowest) > 0 {
+		fmt.Println("Slowest processes:")
+		for _, p := range r.slowest {
+			fmt.Printf("  PID %s (%s): %.1fms\n", p.PID, p.Command, p.Milliseconds)
+		}
+	}
+}
+
+// formatStageDuration shows sub-second durations in ms and longer ones in seconds
+func formatStageDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%.1fms", milliseconds(d))
+	}
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// interactiveSortOrders are cycled with s in interactive mode
+var interactiveSortOrders = []string{"uptime", "port", "command", "path", "cpu"}
+
+// nextSortOrder returns the sort order after current in the cycle
+func nextSortOrder(current string) string {
+	for i, order := range interactiveSortOrders {
+		if order == current {
+			return interactiveSortOrders[(i+1)%len(interactiveSortOrders)]
+		}
+	}
+	return interactiveSortOrders[0]
+}
+
+// sortInteractivePorts orders ports in place, pinned ones first: uptime and CPU descending,
+// the rest ascending. Ties keep a stable order by port.
+func sortInteractivePorts(ports []PortInfo, order string, config *Config) {
+	sort.SliceStable(ports, func(i, j int) bool {
+		a, b := ports[i], ports[j]
+		if pinnedA, pinnedB := config.isPinnedPort(a), config.isPinnedPort(b); pinnedA != pinnedB {
+			return pinnedA
+		}
+		switch order {
+		case "port":
+			return a.Port < b.Port
+		case "command":
+			if a.Command != b.Command {
+				return strings.ToLower(a.Command) < strings.ToLower(b.Command)
+			}
+		case "path":
+			if a.Path != b.Path {
+				return a.Path < b.Path
+			}
+		case "cpu":
+			if a.CPU != b.CPU {
+				return a.CPU > b.CPU
+			}
+		default:
+			if a.UptimeSeconds != b.UptimeSeconds {
+				return a.UptimeSeconds > b.UptimeSeconds
+			}
+		}
+		return a.Port < b.Port
+	})
+}
+
+// sortIndicator marks the header of the column the list is sorted by
+func sortIndicator(order string) string {
+	if order == "uptime" || order == "cpu" {
+		return "↓"
+	}
+	return "↑"
+}
+
+// resolveCPUUsage fills in CPU for every port with one ps call
+func resolveCPUUsage(ports []PortInfo) {
+	seen := make(map[string]bool)
+	var pids []string
+	for _, port := range ports {
+		if !seen[port.PID] {
+			seen[port.PID] = true
+			pids = append(pids, port.PID)
+		}
+	}
+	if len(pids) == 0 {
+		return
+	}
+
+	// ps exits non-zero if any PID has already gone away, but still prints the rest
+	output, _ := commandOutput("ps", "-o", "pid=,%cpu=", "-p", strings.Join(pids, ","))
+	usage := make(map[string]float64)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if cpu, err := strconv.ParseFloat(strings.ReplaceAll(fields[1], ",", "."), 64); err == nil {
+			usage[fields[0]] = cpu
+		}
+	}
+	for i := range ports {
+		ports[i].CPU = usage[ports[i].PID]
+	}
+}
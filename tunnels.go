@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sshForward is a single -L or -R forwarding spec of a running ssh process
+type sshForward struct {
+	Kind        string // "L" (local port -> remote) or "R" (remote port -> local)
+	BindAddress string
+	ListenPort  int
+	TargetHost  string
+	TargetPort  int
+	Destination string // the ssh destination, e.g. user@bastion
+	PID         string
+}
+
+// sshOptionsWithArgs are the ssh flags that consume an argument (see ssh(1))
+const sshOptionsWithArgs = "BbcDEeFIiJLlmOoPpQRSWw"
+
+// parseSSHArgs extracts the forwards and destination from an ssh command line
+func parseSSHArgs(args []string) (forwards []sshForward, destination string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if destination == "" {
+				destination = arg
+			}
+			continue
+		}
+
+		// Walk a flag cluster like -fNL; an option taking an argument consumes the rest
+		for j := 1; j < len(arg); j++ {
+			opt := arg[j]
+			if !strings.ContainsRune(sshOptionsWithArgs, rune(opt)) {
+				continue
+			}
+
+			value := arg[j+1:]
+			if value == "" && i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+			if opt == 'L' || opt == 'R' {
+				if fwd, ok := parseForwardSpec(string(opt), value); ok {
+					forwards = append(forwards, fwd)
+				}
+			}
+			break
+		}
+	}
+
+	for i := range forwards {
+		forwards[i].Destination = destination
+	}
+	return forwards, destination
+}
+
+// parseForwardSpec parses [bind_address:]port:host:hostport, allowing [ipv6] brackets
+func parseForwardSpec(kind, spec string) (sshForward, bool) {
+	var parts []string
+	for spec != "" {
+		if strings.HasPrefix(spec, "[") {
+			end := strings.Index(spec, "]")
+			if end < 0 {
+				return sshForward{}, false
+			}
+			parts = append(parts, spec[1:end])
+			spec = strings.TrimPrefix(spec[end+1:], ":")
+			continue
+		}
+		idx := strings.Index(spec, ":")
+		if idx < 0 {
+			parts = append(parts, spec)
+			break
+		}
+		parts = append(parts, spec[:idx])
+		spec = spec[idx+1:]
+	}
+
+	fwd := sshForward{Kind: kind}
+	switch len(parts) {
+	case 3:
+		parts = append([]string{""}, parts...)
+	case 4:
+	default:
+		return fwd, false // Unix sockets and dynamic forwards aren't port-to-port tunnels
+	}
+
+	var err error
+	fwd.BindAddress = parts[0]
+	if fwd.ListenPort, err = strconv.Atoi(parts[1]); err != nil {
+		return fwd, false
+	}
+	fwd.TargetHost = parts[2]
+	if fwd.TargetPort, err = strconv.Atoi(parts[3]); err != nil {
+		return fwd, false
+	}
+	return fwd, true
+}
+
+// listSSHForwards finds every running ssh process and its -L/-R forwards
+func listSSHForwards() []sshForward {
+	output, err := commandOutput("ps", "-axo", "pid=,args=")
+	if err != nil {
+		return nil
+	}
+
+	var forwards []sshForward
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if fields[1] != "ssh" && !strings.HasSuffix(fields[1], "/ssh") {
+			continue
+		}
+
+		fwds, _ := parseSSHArgs(fields[2:])
+		for _, fwd := range fwds {
+			fwd.PID = fields[0]
+			forwards = append(forwards, fwd)
+		}
+	}
+	return forwards
+}
+
+// isLoopbackHost reports whether a forward target points back at this machine
+func isLoopbackHost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1" || host == ""
+}
+
+// annotateSSHTunnels marks listeners that are ssh -L tunnels, and local servers
+// that are exposed on a remote machine through ssh -R
+func annotateSSHTunnels(ports []PortInfo) {
+	hasSSH := false
+	for _, port := range ports {
+		if port.Command == "ssh" {
+			hasSSH = true
+			break
+		}
+	}
+
+	forwards := listSSHForwards()
+	if len(forwards) == 0 {
+		return
+	}
+
+	for i := range ports {
+		for _, fwd := range forwards {
+			switch {
+			case hasSSH && fwd.Kind == "L" && fwd.PID == ports[i].PID && fwd.ListenPort == ports[i].Port:
+				ports[i].Type = "ssh-tunnel"
+				ports[i].Tunnel = fmt.Sprintf("→ %s:%d via %s", fwd.TargetHost, fwd.TargetPort, fwd.Destination)
+			case fwd.Kind == "R" && isLoopbackHost(fwd.TargetHost) && fwd.TargetPort == ports[i].Port:
+				ports[i].Tunnel = fmt.Sprintf("exposed as %s:%d", fwd.Destination, fwd.ListenPort)
+			}
+		}
+	}
+}
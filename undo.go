@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// relaunchedMsg reports the outcome of U
+type relaunchedMsg struct {
+	port    PortInfo
+	logPath string
+	err     error
+}
+
+// recordKills adds processes killed from interactive mode to the session's kill log,
+// once per PID, so U can start them again. The log is kept in memory only.
+func (m model) recordKills(killed []PortInfo) model {
+	seen := make(map[string]bool)
+	for _, port := range killed {
+		if !seen[port.PID] {
+			seen[port.PID] = true
+			m.killed = append(m.killed, port)
+		}
+	}
+	return m
+}
+
+// undoKill starts the most recently killed process again, with its command line in its
+// working directory, like a restart without the stop
+func (m model) undoKill() (tea.Model, tea.Cmd) {
+	if len(m.killed) == 0 {
+		m.message = "Nothing to undo: no process was killed in this session"
+		return m, nil
+	}
+	port := m.killed[len(m.killed)-1]
+	if err := checkRelaunchable(port); err != nil {
+		m.message = fmt.Sprintf("Can't start %s again: %v", port.Command, err)
+		return m, nil
+	}
+	for _, running := range m.ports {
+		if running.Port == port.Port {
+			m.message = fmt.Sprintf("Can't start %s again: port %d is taken by %s (PID %s)", port.Command, port.Port, running.Command, running.PID)
+			return m, nil
+		}
+	}
+
+	m.killed = m.killed[:len(m.killed)-1]
+	m.message = fmt.Sprintf("Starting %s again in %s…", port.Command, shortenPath(port.Path))
+	return m, func() tea.Msg {
+		logPath, err := startCommandLine(port)
+		return relaunchedMsg{port: port, logPath: logPath, err: err}
+	}
+}
+
+// handleRelaunched reports the outcome of U; a failed start stays undoable
+func (m model) handleRelaunched(msg relaunchedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.killed = append(m.killed, msg.port)
+		m.message = fmt.Sprintf("Failed to start %s again: %v", msg.port.Command, msg.err)
+		return m, nil
+	}
+	m.message = fmt.Sprintf("Started %s again for port %d", msg.port.Command, msg.port.Port)
+	if msg.logPath != "" {
+		m.message += fmt.Sprintf(" (output in %s)", msg.logPath)
+	}
+	if more := len(m.killed); more > 0 {
+		m.message += fmt.Sprintf(" • U: %d more", more)
+	}
+	// Pick up the new PID
+	if !m.refreshing {
+		m.refreshing = true
+		return m, m.rescanPorts()
+	}
+	return m, nil
+}
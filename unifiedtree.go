@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"portage/durations"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// treeRow is a visible line of the unified tree: a workspace (or the orphaned section)
+// when port is -1, otherwise one of its ports
+type treeRow struct {
+	item int
+	port int
+}
+
+// unifiedTreeModel is `portage --unified -i`: workspaces as expandable nodes with the
+// ports running under them, and the ports outside every workspace in their own section
+type unifiedTreeModel struct {
+	items     []UnifiedItem
+	collapsed map[int]bool // items start expanded
+	cursor    int
+	message   string
+	config    *Config
+}
+
+// rows flattens the tree into its visible lines
+func (m unifiedTreeModel) rows() []treeRow {
+	var rows []treeRow
+	for i, item := range m.items {
+		rows = append(rows, treeRow{item: i, port: -1})
+		if m.collapsed[i] {
+			continue
+		}
+		for j := range item.Ports {
+			rows = append(rows, treeRow{item: i, port: j})
+		}
+	}
+	return rows
+}
+
+// selectedPath is the directory of the selected port, or the selected workspace
+func (m unifiedTreeModel) selectedPath(row treeRow) string {
+	item := m.items[row.item]
+	if row.port >= 0 {
+		return item.Ports[row.port].WorkDir
+	}
+	return item.WorkspacePath
+}
+
+// moveToItem puts the cursor on the node line of item
+func (m *unifiedTreeModel) moveToItem(item int) {
+	for i, row := range m.rows() {
+		if row.item == item && row.port < 0 {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+func (m unifiedTreeModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m unifiedTreeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	rows := m.rows()
+	if len(rows) == 0 {
+		switch keyMsg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+	row := rows[m.cursor]
+	m.message = ""
+
+	switch keyMsg.String() {
+	case "q", "esc", "ctrl+c":
+		return m, tea.Quit
+
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down", "j":
+		if m.cursor < len(rows)-1 {
+			m.cursor++
+		}
+
+	case "right", "l":
+		m.collapsed[row.item] = false
+
+	case "left", "h":
+		// Collapse the node, or jump up to it from one of its ports
+		if row.port >= 0 {
+			m.moveToItem(row.item)
+		} else {
+			m.collapsed[row.item] = true
+		}
+
+	case "+":
+		m.collapsed = make(map[int]bool)
+		m.moveToItem(row.item)
+
+	case "-":
+		for i := range m.items {
+			m.collapsed[i] = true
+		}
+		m.moveToItem(row.item)
+
+	case "enter", " ":
+		if row.port >= 0 {
+			m.message = openPort(m.items[row.item].Ports[row.port].portInfo(), m.config)
+		} else {
+			m.collapsed[row.item] = !m.collapsed[row.item]
+		}
+
+	case "e":
+		port := 0
+		if row.port >= 0 {
+			port = m.items[row.item].Ports[row.port].Port
+		}
+		m.message = openInEditor(m.selectedPath(row), port)
+
+	case "f":
+		m.message = openInFinder(m.selectedPath(row))
+
+	case "t":
+		m.message = openInTerminal(m.selectedPath(row), m.config.terminalCommand())
+	}
+
+	return m, nil
+}
+
+func (m unifiedTreeModel) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Accent).MarginBottom(1)
+	nodeStyle := lipgloss.NewStyle().Bold(true)
+	orphanStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.Warning)
+	pinStyle := lipgloss.NewStyle().Foreground(ui.Warning)
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.Muted)
+	portStyle := lipgloss.NewStyle().Foreground(ui.Success)
+	selectedStyle := ui.selectedStyle()
+	messageStyle := lipgloss.NewStyle().Foreground(ui.Success).MarginTop(1)
+	helpStyle := lipgloss.NewStyle().Foreground(ui.Muted).MarginTop(1)
+
+	var s strings.Builder
+	s.WriteString(titleStyle.Render("Workspaces and ports"))
+	s.WriteString("\n")
+
+	rows := m.rows()
+	if len(rows) == 0 {
+		s.WriteString("No open workspaces or listening ports\n")
+	}
+
+	for i, row := range rows {
+		item := m.items[row.item]
+		selected := i == m.cursor
+
+		var line string
+		if row.port < 0 {
+			arrow := "▾"
+			if m.collapsed[row.item] {
+				arrow = "▸"
+			}
+			count := fmt.Sprintf("%d ports", len(item.Ports))
+			if len(item.Ports) == 1 {
+				count = "1 port"
+			}
+			if item.Type == "orphaned" {
+				line = fmt.Sprintf("%s Orphaned ports  %s", arrow, count)
+				if !selected {
+					line = orphanStyle.Render(fmt.Sprintf("%s Orphaned ports", arrow)) + "  " + mutedStyle.Render(count)
+				}
+			} else {
+				pin := " "
+				if item.Pinned {
+					pin = "★"
+				}
+				name := item.WorkspaceName
+				details := fmt.Sprintf("%s • active %s • %s", shortenPath(item.WorkspacePath),
+					durations.Recency.Ago(time.Duration(item.LastActive)*time.Second), count)
+				line = fmt.Sprintf("%s %s %s  %s", arrow, pin, name, details)
+				if !selected {
+					line = fmt.Sprintf("%s %s %s  %s", arrow, pinStyle.Render(pin), nodeStyle.Render(name), mutedStyle.Render(details))
+				}
+			}
+		} else {
+			port := item.Ports[row.port]
+			where := shortenPath(port.WorkDir)
+			if item.Type != "orphaned" {
+				// Inside a workspace the path relative to it says more
+				if rel, err := filepath.Rel(item.WorkspacePath, port.WorkDir); err == nil && rel != "." {
+					where = rel
+				} else {
+					where = ""
+				}
+			}
+			if port.Tunnel != "" {
+				where = port.Tunnel
+			}
+			info := fmt.Sprintf("%-15s PID %-7s %-8s %s", truncate(port.Command, 15), port.PID, port.Uptime, where)
+			line = fmt.Sprintf("    :%-6d %s", port.Port, info)
+			if !selected {
+				line = "    " + portStyle.Render(fmt.Sprintf(":%-6d", port.Port)) + " " + info
+			}
+		}
+
+		if selected {
+			line = selectedStyle.Render(line)
+		}
+		s.WriteString(line)
+		s.WriteString("\n")
+	}
+
+	if m.message != "" {
+		s.WriteString(messageStyle.Render(m.message))
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render("↑/↓: move • ←/→: collapse/expand • +/-: all • enter: open port or toggle • e: editor • f: Finder • t: terminal • q: quit"))
+	return s.String()
+}
+
+// runUnifiedTree shows the unified list as an interactive tree
+func runUnifiedTree(items []UnifiedItem) error {
+	m := unifiedTreeModel{
+		items:     items,
+		collapsed: make(map[int]bool),
+		config:    loadConfig(),
+	}
+	_, err := tea.NewProgram(m).Run()
+	return err
+}
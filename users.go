@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os/user"
+)
+
+// currentUsername returns the login name of the user running portage, or "" if unknown
+func currentUsername() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// matchesUserFilter reports whether a port belongs to the --user given, if any
+func matchesUserFilter(port PortInfo) bool {
+	return userFilter == "" || port.User == userFilter
+}
+
+// shouldShowUserColumn decides whether the table needs a USER column: always with
+// --user/--system, otherwise only when listeners belong to more than one user
+func shouldShowUserColumn(ports []PortInfo) bool {
+	if userFilter != "" || showSystemPorts {
+		return true
+	}
+	for _, port := range ports {
+		if port.User != ports[0].User {
+			return true
+		}
+	}
+	return false
+}
+
+// formatUser highlights listeners owned by someone else: root in red, other users in yellow
+func formatUser(name, me string) string {
+	switch {
+	case name == me || name == "":
+		return name
+	case name == "root":
+		return ColorRed + name + ColorReset
+	default:
+		return ColorYellow + name + ColorReset
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// flapWindow and flapThreshold decide when a project/port that keeps coming back is a
+// crash loop rather than a normal restart
+const (
+	flapWindow    = 10 * time.Minute
+	flapThreshold = 3
+)
+
+// flapTracker remembers when each project/port last started, keyed by "port\tproject"
+type flapTracker struct {
+	starts   map[string][]time.Time
+	flapping map[string]bool // already alerted, until the restarts calm down
+}
+
+func newFlapTracker() *flapTracker {
+	return &flapTracker{
+		starts:   make(map[string][]time.Time),
+		flapping: make(map[string]bool),
+	}
+}
+
+// recordStart notes that port came up and returns how many times it has restarted
+// within flapWindow, and whether this start makes it newly flapping
+func (f *flapTracker) recordStart(port PortInfo, now time.Time) (restarts int, newlyFlapping bool) {
+	key := fmt.Sprintf("%d\t%s", port.Port, projectRoot(port.Path))
+
+	var recent []time.Time
+	for _, at := range f.starts[key] {
+		if now.Sub(at) <= flapWindow {
+			recent = append(recent, at)
+		}
+	}
+	recent = append(recent, now)
+	f.starts[key] = recent
+
+	restarts = len(recent) - 1
+	if restarts < flapThreshold {
+		delete(f.flapping, key)
+		return restarts, false
+	}
+	if f.flapping[key] {
+		return restarts, false
+	}
+	f.flapping[key] = true
+	return restarts, true
+}
+
+// runWatch rescans ports every interval and prints a line for each port that
+// opens or closes. Public binds and configured sensitive ports raise alerts, and so
+// does a project/port that keeps restarting (a crashing dev server). With --json it
+// streams events instead, workspaces and Claude sessions included (watchevents.go).
+func runWatch(interval time.Duration) {
+	defer exitOnCrash()
+
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	if !jsonOutput {
+		fmt.Printf("%s%sWatching ports every %v (Ctrl+C to stop)%s\n", ColorBold, ColorCyan, interval, ColorReset)
+		if recordActivity && readOnly {
+			fmt.Printf("%sNot recording editor activity in read-only mode%s\n", ColorYellow, ColorReset)
+		} else if recordActivity {
+			fmt.Printf("%sRecording editor activity to %s%s\n", ColorCyan, getActivityLogPath(), ColorReset)
+		}
+	}
+
+	var previous map[string]PortInfo
+	var workspaces map[string]bool
+	var claudeSessions map[string]ClaudeSession
+	var lastActivitySample time.Time
+	flaps := newFlapTracker()
+	for {
+		if recordActivity && !readOnly && time.Since(lastActivitySample) >= activitySampleInterval {
+			recordActivitySample()
+			lastActivitySample = time.Now()
+		}
+
+		current, err := scanWatchedPorts()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error scanning ports: %v\n", err)
+		} else {
+			if previous == nil {
+				if jsonOutput {
+					for _, key := range sortedKeys(current) {
+						event := portWatchEvent("port_opened", current[key], time.Now())
+						event.Initial = true
+						emitWatchEvent(event)
+					}
+				} else {
+					fmt.Printf("%s%d ports listening%s\n", ColorCyan, len(current), ColorReset)
+				}
+				for _, port := range current {
+					flaps.recordStart(port, time.Now())
+				}
+			} else {
+				reportPortChanges(previous, current, flaps)
+			}
+			previous = current
+		}
+		if jsonOutput {
+			workspaces = watchWorkspaces(workspaces)
+			claudeSessions = watchClaudeSessions(claudeSessions)
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+// scanWatchedPorts runs a full scan with the same filters as the table view, keyed by "port-pid"
+func scanWatchedPorts() (map[string]PortInfo, error) {
+	ports, err := scanPorts()
+	if err != nil {
+		return nil, err
+	}
+
+	recordScan(ports) // every listener, or the filtered-out ones would be recorded as stopped
+
+	config := loadConfig()
+	var list []PortInfo
+	for _, portList := range selectPorts(ports, config) {
+		list = append(list, portList...)
+	}
+
+	current := make(map[string]PortInfo)
+	for _, port := range list {
+		current[fmt.Sprintf("%d-%s", port.Port, port.PID)] = port
+	}
+	return current, nil
+}
+
+// reportPortChanges prints opened/closed ports between two scans and raises alerts.
+// A port coming back for a project it was already seen with is reported as a restart.
+func reportPortChanges(previous, current map[string]PortInfo, flaps *flapTracker) {
+	config := loadConfig()
+	scanTime := time.Now()
+	now := scanTime.Format("15:04:05")
+
+	var opened, closed []PortInfo
+	for key, port := range current {
+		if _, ok := previous[key]; !ok {
+			opened = append(opened, port)
+		}
+	}
+	for key, port := range previous {
+		if _, ok := current[key]; !ok {
+			closed = append(closed, port)
+		}
+	}
+	sort.Slice(opened, func(i, j int) bool { return opened[i].Port < opened[j].Port })
+	sort.Slice(closed, func(i, j int) bool { return closed[i].Port < closed[j].Port })
+
+	for _, port := range opened {
+		restarts, newlyFlapping := flaps.recordStart(port, scanTime)
+		if jsonOutput {
+			event := portWatchEvent("port_opened", port, scanTime)
+			event.Restarts = restarts
+			emitWatchEvent(event)
+		} else if restarts > 0 {
+			fmt.Printf("[%s] %s~ %d%s %s (PID %s) %s restarted (%d in %dm)\n", now, ColorYellow, port.Port, ColorReset,
+				port.Command, port.PID, shortenPath(port.Path), restarts, int(flapWindow.Minutes()))
+		} else {
+			fmt.Printf("[%s] %s+ %d%s %s (PID %s) %s on %s\n", now, ColorGreen, port.Port, ColorReset,
+				port.Command, port.PID, shortenPath(port.Path), port.Address)
+		}
+		if newlyFlapping {
+			raiseAlert(config, fmt.Sprintf("port %d is flapping: %s restarted %d times in %dm (%s)",
+				port.Port, port.Command, restarts, int(flapWindow.Minutes()), shortenPath(port.Path)))
+		}
+
+		// A restarting server was already checked when it first appeared
+		if reason := alertReason(port, config); reason != "" && restarts == 0 {
+			raiseAlert(config, fmt.Sprintf("port %d %s: %s", port.Port, reason, port.Command))
+		}
+	}
+	for _, port := range closed {
+		if jsonOutput {
+			emitWatchEvent(portWatchEvent("port_closed", port, scanTime))
+			continue
+		}
+		fmt.Printf("[%s] %s- %d%s %s (PID %s) %s\n", now, ColorRed, port.Port, ColorReset,
+			port.Command, port.PID, shortenPath(port.Path))
+	}
+}
+
+// alertReason explains why a newly opened port deserves attention, or returns ""
+func alertReason(port PortInfo, config *Config) string {
+	for _, sensitive := range config.SensitivePorts {
+		if port.Port == sensitive {
+			return "is on the sensitive list"
+		}
+	}
+	if isWildcardBind(port.Address) {
+		return "is listening on all interfaces"
+	}
+	return ""
+}
+
+// isWildcardBind reports whether an lsof address like "*:3000" or "0.0.0.0:3000"
+// accepts connections on every interface
+func isWildcardBind(address string) bool {
+	idx := strings.LastIndex(address, ":")
+	if idx < 0 {
+		return false
+	}
+	host := address[:idx]
+	return host == "*" || host == "0.0.0.0" || host == "[::]" || host == "::"
+}
+
+// raiseAlert prints an alert, sends it to the configured notification channels and, if
+// enabled, rings the bell and flags tmux
+func raiseAlert(config *Config, message string) {
+	if jsonOutput {
+		event := newWatchEvent("alert", time.Now())
+		event.Message = message
+		emitWatchEvent(event)
+	} else {
+		fmt.Printf("%s%s! %s%s\n", ColorBold, ColorYellow, message, ColorReset)
+	}
+
+	if watchBell {
+		// The terminal still rings when stdout is a JSON stream
+		fmt.Fprint(os.Stderr, "\a")
+	}
+
+	// Inside tmux: set a user option for the status line (#{@portage_alert}) and flash a message
+	if watchTmux && os.Getenv("TMUX") != "" {
+		exec.Command("tmux", "set-option", "-g", "@portage_alert", "⚠ "+message).Run()
+		exec.Command("tmux", "display-message", "portage: "+message).Run()
+	}
+
+	if channels := config.Notifications.notifiers(); len(channels) > 0 {
+		title := "portage"
+		if host, err := os.Hostname(); err == nil {
+			title += " on " + strings.TrimSuffix(host, ".local")
+		}
+		sendNotifications(channels, title, message)
+	}
+}
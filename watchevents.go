@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// watchEvent is one line of `--watch --json`: newline-delimited JSON, one object per
+// change, so tools can consume the stream as it happens
+type watchEvent struct {
+	SchemaVersion int    `json:"schema_version"`    // jsonSchemaVersion, on every line since there's no envelope
+	Event         string `json:"event"`             // port_opened, port_closed, workspace_opened, workspace_closed, claude_started, claude_stopped, alert
+	Time          string `json:"time"`              // RFC 3339
+	Initial       bool   `json:"initial,omitempty"` // already there when watching started
+	Port          int    `json:"port,omitempty"`
+	PID           string `json:"pid,omitempty"`
+	Command       string `json:"command,omitempty"`
+	Path          string `json:"path,omitempty"`
+	Address       string `json:"address,omitempty"`
+	Restarts      int    `json:"restarts,omitempty"` // port_opened: times it came back within the flap window
+	Message       string `json:"message,omitempty"`  // alert
+}
+
+func newWatchEvent(event string, at time.Time) watchEvent {
+	return watchEvent{SchemaVersion: jsonSchemaVersion, Event: event, Time: at.Format(time.RFC3339)}
+}
+
+func portWatchEvent(event string, port PortInfo, at time.Time) watchEvent {
+	e := newWatchEvent(event, at)
+	e.Port, e.PID, e.Command, e.Path, e.Address = port.Port, port.PID, port.Command, port.Path, port.Address
+	return e
+}
+
+// emitWatchEvent prints an event as a single line of JSON, or through --jq or --format
+func emitWatchEvent(event watchEvent) {
+	if formatTemplate != nil {
+		writeFormatted(event)
+		return
+	}
+	if jqQuery != "" {
+		writeDocument(event)
+		return
+	}
+	if yamlOutput {
+		fmt.Println("---")
+		writeDocument(event)
+		return
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+		return
+	}
+	fmt.Println(string(data))
+}
+
+// watchWorkspaces reports Cursor windows opening and closing since the previous scan
+// (nil on the first one) and returns the current set
+func watchWorkspaces(previous map[string]bool) map[string]bool {
+	now := time.Now()
+	current := make(map[string]bool)
+	for path := range getOpenCursorWindows() {
+		if isUnderPathFilter(path) {
+			current[path] = true
+		}
+	}
+
+	for _, path := range sortedKeys(current) {
+		if !previous[path] {
+			event := newWatchEvent("workspace_opened", now)
+			event.Path, event.Initial = path, previous == nil
+			emitWatchEvent(event)
+		}
+	}
+	for _, path := range sortedKeys(previous) {
+		if !current[path] {
+			event := newWatchEvent("workspace_closed", now)
+			event.Path = path
+			emitWatchEvent(event)
+		}
+	}
+	return current
+}
+
+// watchClaudeSessions reports Claude sessions starting and stopping since the previous
+// scan (nil on the first one), by PID, and returns the current ones
+func watchClaudeSessions(previous map[string]ClaudeSession) map[string]ClaudeSession {
+	now := time.Now()
+	current := make(map[string]ClaudeSession)
+	for _, session := range getClaudeSessions() {
+		if isUnderPathFilter(session.WorkingDir) {
+			current[session.PID] = session
+		}
+	}
+
+	report := func(event string, session ClaudeSession, initial bool) {
+		e := newWatchEvent(event, now)
+		e.PID, e.Path, e.Initial = session.PID, session.WorkingDir, initial
+		emitWatchEvent(e)
+	}
+	for _, pid := range sortedKeys(current) {
+		if _, ok := previous[pid]; !ok {
+			report("claude_started", current[pid], previous == nil)
+		}
+	}
+	for _, pid := range sortedKeys(previous) {
+		if _, ok := current[pid]; !ok {
+			report("claude_stopped", previous[pid], false)
+		}
+	}
+	return current
+}
+
+// sortedKeys keeps the events of one scan in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// openInCursor opens a workspace in Cursor whatever the configured editor is: with the
+// cursor CLI when it's installed, else the app itself
+func openInCursor(path string) string {
+	if path == "" {
+		return "No path available to open"
+	}
+	cmd := exec.Command("cursor", path)
+	if _, err := exec.LookPath("cursor"); err != nil {
+		cmd = exec.Command("open", "-a", "Cursor", path)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Sprintf("Failed to open in Cursor: %v", err)
+	}
+	return fmt.Sprintf("Opened %s in Cursor", shortenPath(path))
+}
+
+// markWorkspaceClosed logs a workspace as closed in the workspace log, like --log-close
+func markWorkspaceClosed(path string) string {
+	if path == "" {
+		return "No path available to mark"
+	}
+	if err := addWorkspaceCloseEvent(path); err != nil {
+		return fmt.Sprintf("Failed to mark %s closed: %v", shortenPath(path), err)
+	}
+	return fmt.Sprintf("Marked %s closed", shortenPath(path))
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// configDir holds what you or portage configure: config.toml and portage.json
+// ($XDG_CONFIG_HOME/portage, ~/.config/portage by default)
+func configDir() string {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// stateDir holds what portage records: the logs and crash reports
+// ($XDG_STATE_HOME/portage, ~/.local/state/portage by default)
+func stateDir() string {
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// xdgDir resolves portage's directory for an XDG base directory variable. Without the
+// variable, macOS uses ~/Library/Application Support/portage, unless the XDG default
+// already exists there (dotfile setups often create ~/.config on macOS too).
+func xdgDir(variable, fallback string) string {
+	if dir := os.Getenv(variable); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, "portage")
+	}
+	home, _ := os.UserHomeDir()
+	dir := filepath.Join(home, fallback, "portage")
+	if runtime.GOOS == "darwin" {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		return filepath.Join(home, "Library", "Application Support", "portage")
+	}
+	return dir
+}
+
+// legacyFile is a file portage kept directly in the home directory before it moved to
+// the XDG directories
+type legacyFile struct {
+	name    string        // under the home directory
+	current func() string // where it lives now
+}
+
+var legacyFiles = []legacyFile{
+	{".portage.json", getConfigPath},
+	{".portage.log", getLogPath},
+	{".portage-workspace.log", func() string { path, _ := getWorkspaceLogPath(); return path }},
+	{".portage-activity.log", getActivityLogPath},
+	{filepath.Join(".portage", "crash"), crashDir},
+}
+
+// migrateLegacyFiles moves files from their old places in the home directory to the
+// XDG directories, once: a file that already exists in its new place is left alone,
+// and so is the old one, so nothing is ever overwritten. Read-only runs leave them all
+// where they are and read them from there.
+func migrateLegacyFiles() {
+	home, err := os.UserHomeDir()
+	if err != nil || readOnly {
+		return
+	}
+	for _, file := range legacyFiles {
+		legacy, current := filepath.Join(home, file.name), file.current()
+		if legacy == current {
+			continue
+		}
+		if _, err := os.Stat(legacy); err != nil {
+			continue
+		}
+		if _, err := os.Stat(current); err == nil {
+			continue
+		}
+		if err := moveFile(legacy, current); err != nil {
+			fmt.Fprintf(os.Stderr, "portage: couldn't move %s to %s: %v\n", shortenPath(legacy), shortenPath(current), err)
+			continue
+		}
+		if !demoMode {
+			fmt.Fprintf(os.Stderr, "portage: moved %s to %s\n", shortenPath(legacy), shortenPath(current))
+		}
+	}
+	os.Remove(filepath.Join(home, ".portage")) // only if the crash reports were all it held
+}
+
+// readPath returns where to read a file portage keeps in the XDG directories: its old
+// place in the home directory while it's still only there, as after read-only runs
+func readPath(current string) string {
+	if _, err := os.Stat(current); err == nil {
+		return current
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return current
+	}
+	for _, file := range legacyFiles {
+		if file.current() != current {
+			continue
+		}
+		legacy := filepath.Join(home, file.name)
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy
+		}
+	}
+	return current
+}
+
+// moveFile renames a file or directory, copying files across file systems
+func moveFile(from, to string) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return err
+	}
+	err := os.Rename(from, to)
+	if err == nil {
+		return nil
+	}
+	info, statErr := os.Stat(from)
+	if statErr != nil || info.IsDir() {
+		return err // a directory on another file system stays where it is
+	}
+
+	source, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	target, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(target, source); err != nil {
+		target.Close()
+		os.Remove(to)
+		return err
+	}
+	if err := target.Close(); err != nil {
+		os.Remove(to)
+		return err
+	}
+	return os.Remove(from)
+}
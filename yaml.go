@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeYAML prints v as YAML for --yaml. It goes through JSON, so the structure and
+// field names are exactly those of --json, in the same order.
+func writeYAML(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // keep numbers as written
+	value, err := decodeOrdered(decoder)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	switch value.(type) {
+	case orderedObject, []interface{}:
+		writeYAMLBlock(&b, value, 0)
+	default:
+		b.WriteString(yamlScalar(value) + "\n")
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// orderedObject is a JSON object with its keys in their original order
+type orderedObject struct {
+	keys   []string
+	values []interface{}
+}
+
+// decodeOrdered reads the next JSON value, keeping the key order of objects
+func decodeOrdered(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		var object orderedObject
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.keys = append(object.keys, key.(string))
+			object.values = append(object.values, value)
+		}
+		_, err := decoder.Token() // }
+		return object, err
+	case json.Delim('['):
+		list := []interface{}{}
+		for decoder.More() {
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := decoder.Token() // ]
+		return list, err
+	}
+	return token, nil
+}
+
+// writeYAMLBlock writes an object or list as block YAML at indent
+func writeYAMLBlock(b *strings.Builder, value interface{}, indent int) {
+	pad := strings.Repeat(" ", indent)
+	switch v := value.(type) {
+	case orderedObject:
+		for i, key := range v.keys {
+			b.WriteString(pad + yamlString(key) + ":")
+			writeYAMLValue(b, v.values[i], indent+2)
+		}
+	case []interface{}:
+		for _, item := range v {
+			// An object item starts on the "- " line: "- port: 3000\n  command: node"
+			var nested strings.Builder
+			writeYAMLBlock(&nested, item, indent+2)
+			if isYAMLBlock(item) {
+				b.WriteString(pad + "- " + strings.TrimPrefix(nested.String(), pad+"  "))
+			} else {
+				b.WriteString(pad + "- " + yamlScalar(item) + "\n")
+			}
+		}
+	}
+}
+
+// writeYAMLValue writes what follows "key:" — a scalar on the same line, or a block below
+func writeYAMLValue(b *strings.Builder, value interface{}, indent int) {
+	if !isYAMLBlock(value) {
+		b.WriteString(" " + yamlScalar(value) + "\n")
+		return
+	}
+	b.WriteString("\n")
+	writeYAMLBlock(b, value, indent)
+}
+
+// isYAMLBlock reports whether value is written as a block: non-empty objects and lists
+func isYAMLBlock(value interface{}) bool {
+	switch v := value.(type) {
+	case orderedObject:
+		return len(v.keys) > 0
+	case []interface{}:
+		return len(v) > 0
+	}
+	return false
+}
+
+// yamlScalar formats a scalar, or an empty object or list in flow style
+func yamlScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return fmt.Sprint(v)
+	case json.Number:
+		return v.String()
+	case string:
+		return yamlString(v)
+	case orderedObject:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	}
+	return fmt.Sprint(value)
+}
+
+// yamlString leaves a string plain unless YAML would read it as something else, in which
+// case it's double-quoted (JSON escapes are valid there)
+func yamlString(s string) string {
+	if !needsYAMLQuotes(s) {
+		return s
+	}
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
+
+func needsYAMLQuotes(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~":
+		return true
+	}
+	if json.Valid([]byte(s)) { // numbers and the like
+		return true
+	}
+	// YAML 1.1 readers also take times ("0:12.34"), dates, hex and 1_000 as numbers
+	if strings.ContainsAny(s[:1], "0123456789.+") && strings.ContainsAny(s, ":-_.xXoO") {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return true
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}